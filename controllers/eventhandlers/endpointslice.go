@@ -0,0 +1,84 @@
+package eventhandlers
+
+import (
+	"context"
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type enqueueRequestsForEndpointSliceEvent struct {
+	client client.Client
+}
+
+// NewEnqueueRequestEndpointSliceEvent enqueues the service owning the EndpointSlice, so that its targets are
+// rebuilt from all the slices of the service
+func NewEnqueueRequestEndpointSliceEvent(client client.Client) handler.EventHandler {
+	return &enqueueRequestsForEndpointSliceEvent{
+		client: client,
+	}
+}
+
+func (h *enqueueRequestsForEndpointSliceEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	glog.V(6).Info("Event: endpointslice create")
+
+	epsNew := e.Object.(*discoveryv1.EndpointSlice)
+	h.enqueueImpactedService(queue, epsNew)
+}
+
+func (h *enqueueRequestsForEndpointSliceEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	glog.V(6).Info("Event: endpointslice update")
+	epsOld := e.ObjectOld.(*discoveryv1.EndpointSlice)
+	epsNew := e.ObjectNew.(*discoveryv1.EndpointSlice)
+
+	if !equality.Semantic.DeepEqual(epsOld.Endpoints, epsNew.Endpoints) ||
+		!equality.Semantic.DeepEqual(epsOld.Ports, epsNew.Ports) {
+		h.enqueueImpactedService(queue, epsNew)
+	}
+}
+
+func (h *enqueueRequestsForEndpointSliceEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	glog.V(6).Infof("Event: endpointslice delete")
+	// the remaining slices of the service still provide its targets, the service deletion is handled
+	// by the service event handler
+	epsOld := e.Object.(*discoveryv1.EndpointSlice)
+	h.enqueueImpactedService(queue, epsOld)
+}
+
+func (h *enqueueRequestsForEndpointSliceEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForEndpointSliceEvent) enqueueImpactedService(queue workqueue.RateLimitingInterface, eps *discoveryv1.EndpointSlice) {
+	svcName, ok := eps.Labels[discoveryv1.LabelServiceName]
+	if !ok || svcName == "" {
+		glog.V(6).Infof("Event: enqueueImpactedService, endpointslice %s-%s has no service\n", eps.Name, eps.Namespace)
+		return
+	}
+
+	svc := &corev1.Service{}
+	namespaceName := types.NamespacedName{
+		Namespace: eps.Namespace,
+		Name:      svcName,
+	}
+
+	if err := h.client.Get(context.TODO(), namespaceName, svc); err != nil {
+		glog.V(6).Infof("Event: enqueueImpactedService, service not found %v\n", err)
+		return
+	}
+
+	queue.Add(reconcile.Request{
+		NamespacedName: namespaceName,
+	})
+
+	glog.V(6).Infof("Finished enqueueImpactedService [%v] for endpointslice %s\n", namespaceName, eps.Name)
+}
@@ -18,16 +18,19 @@ import (
 
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 
-	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 )
 
 type enqueueRequestsForGatewayEvent struct {
-	client client.Client
+	client    client.Client
+	routeKind string
 }
 
-func NewEnqueueRequestGatewayEvent(client client.Client) handler.EventHandler {
+// NewEnqueueRequestGatewayEvent enqueues the routes of routeKind attached to the gateway
+func NewEnqueueRequestGatewayEvent(client client.Client, routeKind string) handler.EventHandler {
 	return &enqueueRequestsForGatewayEvent{
-		client: client,
+		client:    client,
+		routeKind: routeKind,
 	}
 }
 
@@ -39,7 +42,7 @@ func (h *enqueueRequestsForGatewayEvent) Create(e event.CreateEvent, queue workq
 
 	// initialize transition time
 	gwNew.Status.Conditions[0].LastTransitionTime = ZeroTransitionTime
-	h.enqueueImpactedHTTPRoute(queue, gwNew)
+	h.enqueueImpactedRoute(queue, gwNew)
 }
 
 func (h *enqueueRequestsForGatewayEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
@@ -53,7 +56,7 @@ func (h *enqueueRequestsForGatewayEvent) Update(e event.UpdateEvent, queue workq
 			gwOld.Spec, gwNew.Spec)
 		// initialize transition time
 		gwNew.Status.Conditions[0].LastTransitionTime = ZeroTransitionTime
-		h.enqueueImpactedHTTPRoute(queue, gwNew)
+		h.enqueueImpactedRoute(queue, gwNew)
 	}
 }
 
@@ -65,55 +68,28 @@ func (h *enqueueRequestsForGatewayEvent) Generic(e event.GenericEvent, queue wor
 
 }
 
-func (h *enqueueRequestsForGatewayEvent) enqueueImpactedHTTPRoute(queue workqueue.RateLimitingInterface, gw *gateway_api.Gateway) {
-	httpRouteList := &gateway_api.HTTPRouteList{}
+func (h *enqueueRequestsForGatewayEvent) enqueueImpactedRoute(queue workqueue.RateLimitingInterface, gw *gateway_api.Gateway) {
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
 
-	h.client.List(context.TODO(), httpRouteList)
-
-	for _, httpRoute := range httpRouteList.Items {
-
-		if len(httpRoute.Spec.ParentRefs) <= 0 {
-			glog.V(6).Infof("Ignore httpRoute no parentRefs %s", httpRoute.Name)
+		if len(route.ParentRefs) <= 0 {
+			glog.V(6).Infof("Ignore %s no parentRefs %s", route.Kind, route.Name)
 			continue
 		}
 
-		// find the parent gw object
-		var gwNamespace = httpRoute.Namespace
-		if httpRoute.Spec.ParentRefs[0].Namespace != nil {
-			gwNamespace = string(*httpRoute.Spec.ParentRefs[0].Namespace)
-		}
-		gwName := types.NamespacedName{
-			Namespace: gwNamespace,
-			Name:      string(httpRoute.Spec.ParentRefs[0].Name),
-		}
-
-		gw := &gateway_api.Gateway{}
-
-		if err := h.client.Get(context.TODO(), gwName, gw); err != nil {
-			glog.V(6).Infof("Ignore HTTPRoute with unknown parentRef %s\n", httpRoute.Name)
-			continue
-		}
-
-		// find the parent gateway class name
-		gwClass := &gateway_api.GatewayClass{}
-		gwClassName := types.NamespacedName{
-			Namespace: "default",
-			Name:      string(gw.Spec.GatewayClassName),
-		}
-
-		if err := h.client.Get(context.TODO(), gwClassName, gwClass); err != nil {
-			glog.V(6).Infof("Ignore HTTPRoute with unknown Gateway %s \n", httpRoute.Name)
-			continue
-		}
+		// any of the lattice gateway parents of the route can be the one changed
+		for _, parent := range k8s.ValidateRouteParents(context.TODO(), h.client, route) {
+			if parent.Gateway.Name != gw.Name || parent.Gateway.Namespace != gw.Namespace {
+				continue
+			}
 
-		if gwClass.Spec.ControllerName == config.LatticeGatewayControllerName {
-			glog.V(2).Infof("Trigger HTTPRoute from Gateway event , httpRoute %s", httpRoute.Name)
+			glog.V(2).Infof("Trigger %s from Gateway event , route %s", route.Kind, route.Name)
 			queue.Add(reconcile.Request{
 				NamespacedName: types.NamespacedName{
-					Namespace: httpRoute.Namespace,
-					Name:      httpRoute.Name,
+					Namespace: route.Namespace,
+					Name:      route.Name,
 				},
 			})
+			break
 		}
 
 	}
@@ -11,10 +11,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 )
 
 type enqueueRequestsForHTTPRouteEvent struct {
@@ -28,28 +31,39 @@ func NewEnqueueRequestHTTPRouteEvent(client client.Client) handler.EventHandler
 }
 
 func (h *enqueueRequestsForHTTPRouteEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
-	glog.V(6).Info("HTTPRoute create")
+	glog.V(6).Info("Route create")
 
-	newHTTPRoute := e.Object.(*gateway_api.HTTPRoute)
-	h.enqueueImpactedService(queue, newHTTPRoute)
+	h.enqueueImpactedService(queue, e.Object)
 }
 
 func (h *enqueueRequestsForHTTPRouteEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
-	glog.V(6).Info("HTTPRoute update ")
+	glog.V(6).Info("Route update ")
+
+	switch newRoute := e.ObjectNew.(type) {
+	case *gateway_api.HTTPRoute:
+		oldHTTPRoute := e.ObjectOld.(*gateway_api.HTTPRoute)
 
-	oldHTTPRoute := e.ObjectOld.(*gateway_api.HTTPRoute)
-	newHTTPRoute := e.ObjectNew.(*gateway_api.HTTPRoute)
+		if !equality.Semantic.DeepEqual(oldHTTPRoute.Spec, newRoute.Spec) {
+			glog.V(6).Infof("--oldHTTPRoute %v \n", oldHTTPRoute.Spec)
+			glog.V(6).Infof("--newHTTPRoute %v \n", newRoute.Spec)
+			h.enqueueImpactedService(queue, newRoute)
+		}
+	case *gateway_api_v1alpha2.GRPCRoute:
+		oldGRPCRoute := e.ObjectOld.(*gateway_api_v1alpha2.GRPCRoute)
 
-	if !equality.Semantic.DeepEqual(oldHTTPRoute.Spec, newHTTPRoute.Spec) {
-		glog.V(6).Infof("--oldHTTPRoute %v \n", oldHTTPRoute.Spec)
-		glog.V(6).Infof("--newHTTPRoute %v \n", newHTTPRoute.Spec)
-		if newHTTPRoute.Status.RouteStatus.Parents != nil &&
-			newHTTPRoute.Status.RouteStatus.Parents[0].Conditions[0].LastTransitionTime != ZeroTransitionTime {
-			glog.V(6).Infof("Update Gateway Event -- reset LastTransitionTime for gateway %v\n", newHTTPRoute)
-			newHTTPRoute.Status.RouteStatus.Parents[0].Conditions[0].LastTransitionTime = ZeroTransitionTime
+		if !equality.Semantic.DeepEqual(oldGRPCRoute.Spec, newRoute.Spec) {
+			glog.V(6).Infof("--oldGRPCRoute %v \n", oldGRPCRoute.Spec)
+			glog.V(6).Infof("--newGRPCRoute %v \n", newRoute.Spec)
+			h.enqueueImpactedService(queue, newRoute)
+		}
+	case *gateway_api_v1alpha2.TLSRoute:
+		oldTLSRoute := e.ObjectOld.(*gateway_api_v1alpha2.TLSRoute)
 
+		if !equality.Semantic.DeepEqual(oldTLSRoute.Spec, newRoute.Spec) {
+			glog.V(6).Infof("--oldTLSRoute %v \n", oldTLSRoute.Spec)
+			glog.V(6).Infof("--newTLSRoute %v \n", newRoute.Spec)
+			h.enqueueImpactedService(queue, newRoute)
 		}
-		h.enqueueImpactedService(queue, newHTTPRoute)
 	}
 }
 
@@ -61,29 +75,35 @@ func (h *enqueueRequestsForHTTPRouteEvent) Generic(e event.GenericEvent, queue w
 
 }
 
-func (h *enqueueRequestsForHTTPRouteEvent) enqueueImpactedService(queue workqueue.RateLimitingInterface, httpRoute *gateway_api.HTTPRoute) {
-	glog.V(6).Infof("enqueueImpactedService [%v]\n", httpRoute)
-
-	for _, httpRule := range httpRoute.Spec.Rules {
-		for _, httpBackendRef := range httpRule.BackendRefs {
-			// TODOif httpBackendRef.Kind == "service" {
-			namespaceName := types.NamespacedName{
-				Namespace: httpRoute.Namespace,
-				Name:      string(httpBackendRef.Name),
-			}
-
-			svc := &corev1.Service{}
-			if err := h.client.Get(context.TODO(), namespaceName, svc); err != nil {
-				glog.V(6).Infof("enqueueRequestsForHTTPRouteEvent: unknown svc[%v]\n", namespaceName)
-				continue
-			}
-
-			glog.V(6).Infof("enqueueRequestsForHTTPRouteEvent for svc[%v]\n", namespaceName)
-			queue.Add(reconcile.Request{
-				NamespacedName: namespaceName,
-			})
-
-			//}
+func (h *enqueueRequestsForHTTPRouteEvent) enqueueImpactedService(queue workqueue.RateLimitingInterface, obj client.Object) {
+	route, ok := k8s.NewRouteInfo(obj)
+	if !ok {
+		return
+	}
+	glog.V(6).Infof("enqueueImpactedService %s [%v]\n", route.Kind, route)
+
+	for _, backendRef := range route.BackendRefs {
+		// TODOif backendRef.Kind == "service" {
+		namespace := route.Namespace
+		if backendRef.Namespace != nil {
+			namespace = string(*backendRef.Namespace)
 		}
+		namespaceName := types.NamespacedName{
+			Namespace: namespace,
+			Name:      string(backendRef.Name),
+		}
+
+		svc := &corev1.Service{}
+		if err := h.client.Get(context.TODO(), namespaceName, svc); err != nil {
+			glog.V(6).Infof("enqueueRequestsForHTTPRouteEvent: unknown svc[%v]\n", namespaceName)
+			continue
+		}
+
+		glog.V(6).Infof("enqueueRequestsForHTTPRouteEvent for svc[%v]\n", namespaceName)
+		queue.Add(reconcile.Request{
+			NamespacedName: namespaceName,
+		})
+
+		//}
 	}
 }
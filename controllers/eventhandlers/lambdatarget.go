@@ -0,0 +1,75 @@
+package eventhandlers
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForLambdaTargetEvent struct {
+	client    client.Client
+	routeKind string
+}
+
+// NewEnqueueRequestLambdaTargetEvent enqueues the routes of routeKind using the LambdaTarget as backend, so
+// that the function registered to its target group and the route status are updated
+func NewEnqueueRequestLambdaTargetEvent(client client.Client, routeKind string) handler.EventHandler {
+	return &enqueueRequestsForLambdaTargetEvent{
+		client:    client,
+		routeKind: routeKind,
+	}
+}
+
+func (h *enqueueRequestsForLambdaTargetEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	newLambdaTarget := e.Object.(*anv1alpha1.LambdaTarget)
+	h.enqueueImpactedRoutes(queue, newLambdaTarget)
+}
+
+func (h *enqueueRequestsForLambdaTargetEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldLambdaTarget := e.ObjectOld.(*anv1alpha1.LambdaTarget)
+	newLambdaTarget := e.ObjectNew.(*anv1alpha1.LambdaTarget)
+
+	if !equality.Semantic.DeepEqual(oldLambdaTarget.Spec, newLambdaTarget.Spec) {
+		h.enqueueImpactedRoutes(queue, newLambdaTarget)
+	}
+}
+
+func (h *enqueueRequestsForLambdaTargetEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	oldLambdaTarget := e.Object.(*anv1alpha1.LambdaTarget)
+	h.enqueueImpactedRoutes(queue, oldLambdaTarget)
+}
+
+func (h *enqueueRequestsForLambdaTargetEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForLambdaTargetEvent) enqueueImpactedRoutes(queue workqueue.RateLimitingInterface, lambdaTarget *anv1alpha1.LambdaTarget) {
+	glog.V(6).Infof("enqueueImpactedRoutes, lambdaTarget[%s-%s]\n", lambdaTarget.Name, lambdaTarget.Namespace)
+
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if !isBackendUsedByRoute(route, anv1alpha1.LambdaTargetKind, lambdaTarget.Name, lambdaTarget.Namespace) {
+			continue
+		}
+
+		glog.V(6).Infof("enqueueRequestsForLambdaTargetEvent --> %s %s-%s\n", route.Kind, route.Name, route.Namespace)
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: route.Namespace,
+				Name:      route.Name,
+			},
+		})
+	}
+}
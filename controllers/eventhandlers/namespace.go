@@ -0,0 +1,71 @@
+package eventhandlers
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForNamespaceEvent struct {
+	client    client.Client
+	routeKind string
+}
+
+// NewEnqueueRequestNamespaceEvent enqueues the routes of routeKind in a namespace whose labels change,
+// since the labels decide which gateway listeners with a namespace selector the routes may attach to
+func NewEnqueueRequestNamespaceEvent(client client.Client, routeKind string) handler.EventHandler {
+	return &enqueueRequestsForNamespaceEvent{
+		client:    client,
+		routeKind: routeKind,
+	}
+}
+
+func (h *enqueueRequestsForNamespaceEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForNamespaceEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldNamespace := e.ObjectOld.(*corev1.Namespace)
+	newNamespace := e.ObjectNew.(*corev1.Namespace)
+
+	if !equality.Semantic.DeepEqual(oldNamespace.Labels, newNamespace.Labels) {
+		h.enqueueImpactedRoutes(queue, newNamespace)
+	}
+}
+
+func (h *enqueueRequestsForNamespaceEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForNamespaceEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForNamespaceEvent) enqueueImpactedRoutes(queue workqueue.RateLimitingInterface, ns *corev1.Namespace) {
+	glog.V(6).Infof("enqueueImpactedRoutes, namespace[%s]\n", ns.Name)
+
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if route.Namespace != ns.Name {
+			continue
+		}
+
+		glog.V(6).Infof("enqueueRequestsForNamespaceEvent --> %s %s-%s\n", route.Kind, route.Name, route.Namespace)
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: route.Namespace,
+				Name:      route.Name,
+			},
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package eventhandlers
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForPodEvent struct {
+	client client.Client
+}
+
+// NewEnqueueRequestPodEvent enqueues the services of a pod carrying the lattice readiness gate when its containers
+// become ready. The EndpointSlices do not change then, since the pod is not ready until the gate passes, but its
+// target has to be registered for the gate to pass
+func NewEnqueueRequestPodEvent(client client.Client) handler.EventHandler {
+	return &enqueueRequestsForPodEvent{
+		client: client,
+	}
+}
+
+func (h *enqueueRequestsForPodEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForPodEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	podOld := e.ObjectOld.(*corev1.Pod)
+	podNew := e.ObjectNew.(*corev1.Pod)
+
+	if !k8s.HasPodReadinessGate(podNew) {
+		return
+	}
+
+	if podContainersReady(podOld) != podContainersReady(podNew) {
+		glog.V(6).Infof("Event: pod %s-%s containers ready changed\n", podNew.Name, podNew.Namespace)
+		h.enqueueImpactedServices(queue, podNew)
+	}
+}
+
+func (h *enqueueRequestsForPodEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	// the EndpointSlices of the services drop the pod, which is handled by the endpointslice event handler
+}
+
+func (h *enqueueRequestsForPodEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForPodEvent) enqueueImpactedServices(queue workqueue.RateLimitingInterface, pod *corev1.Pod) {
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := h.client.List(context.TODO(), endpointSlices, client.InNamespace(pod.Namespace)); err != nil {
+		glog.V(6).Infof("Event: enqueueImpactedServices, failed to list EndpointSlices %v\n", err)
+		return
+	}
+
+	for _, eps := range endpointSlices.Items {
+		svcName, ok := eps.Labels[discoveryv1.LabelServiceName]
+		if !ok || svcName == "" {
+			continue
+		}
+		for _, endpoint := range eps.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" || endpoint.TargetRef.UID != pod.UID {
+				continue
+			}
+			namespaceName := types.NamespacedName{
+				Namespace: eps.Namespace,
+				Name:      svcName,
+			}
+			queue.Add(reconcile.Request{
+				NamespacedName: namespaceName,
+			})
+			glog.V(6).Infof("Finished enqueueImpactedServices [%v] for pod %s\n", namespaceName, pod.Name)
+			break
+		}
+	}
+}
+
+func podContainersReady(pod *corev1.Pod) bool {
+	condition := k8s.GetPodCondition(pod, corev1.ContainersReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
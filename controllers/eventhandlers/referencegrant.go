@@ -0,0 +1,87 @@
+package eventhandlers
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForReferenceGrantEvent struct {
+	client    client.Client
+	routeKind string
+}
+
+// NewEnqueueRequestReferenceGrantEvent enqueues the routes of routeKind which refer to a backend
+// in the namespace of the referencegrant, so that a granted or revoked reference takes effect
+func NewEnqueueRequestReferenceGrantEvent(client client.Client, routeKind string) handler.EventHandler {
+	return &enqueueRequestsForReferenceGrantEvent{
+		client:    client,
+		routeKind: routeKind,
+	}
+}
+
+func (h *enqueueRequestsForReferenceGrantEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	newGrant := e.Object.(*gateway_api.ReferenceGrant)
+	h.enqueueImpactedRoutes(queue, newGrant)
+}
+
+func (h *enqueueRequestsForReferenceGrantEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldGrant := e.ObjectOld.(*gateway_api.ReferenceGrant)
+	newGrant := e.ObjectNew.(*gateway_api.ReferenceGrant)
+
+	if !equality.Semantic.DeepEqual(oldGrant.Spec, newGrant.Spec) {
+		// routes which are no longer granted need to be reconciled as well
+		h.enqueueImpactedRoutes(queue, oldGrant)
+		h.enqueueImpactedRoutes(queue, newGrant)
+	}
+}
+
+func (h *enqueueRequestsForReferenceGrantEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	oldGrant := e.Object.(*gateway_api.ReferenceGrant)
+	h.enqueueImpactedRoutes(queue, oldGrant)
+}
+
+func (h *enqueueRequestsForReferenceGrantEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForReferenceGrantEvent) enqueueImpactedRoutes(queue workqueue.RateLimitingInterface, grant *gateway_api.ReferenceGrant) {
+	glog.V(6).Infof("enqueueImpactedRoutes, referenceGrant[%s-%s]\n", grant.Name, grant.Namespace)
+
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if !k8s.IsReferenceGrantFrom(*grant, route.Kind, route.Namespace) ||
+			!isBackendNamespaceUsedByRoute(route, grant.Namespace) {
+			continue
+		}
+
+		glog.V(6).Infof("enqueueRequestsForReferenceGrantEvent --> %s %s-%s\n", route.Kind, route.Name, route.Namespace)
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: route.Namespace,
+				Name:      route.Name,
+			},
+		})
+	}
+}
+
+func isBackendNamespaceUsedByRoute(route k8s.RouteInfo, namespace string) bool {
+	for _, backendRef := range route.BackendRefs {
+		if backendRef.Namespace != nil && string(*backendRef.Namespace) == namespace {
+			return true
+		}
+	}
+	return false
+}
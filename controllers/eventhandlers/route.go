@@ -0,0 +1,44 @@
+package eventhandlers
+
+import (
+	"github.com/golang/glog"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForRouteEvent struct {
+}
+
+// NewEnqueueRequestRouteEvent enqueues the route of the same name and namespace as a route of another kind which
+// is created or deleted, so that a route whose name conflicts with it is rejected or served
+func NewEnqueueRequestRouteEvent() handler.EventHandler {
+	return &enqueueRequestsForRouteEvent{}
+}
+
+func (h *enqueueRequestsForRouteEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueSameNameRoute(queue, e.Object)
+}
+
+func (h *enqueueRequestsForRouteEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForRouteEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueSameNameRoute(queue, e.Object)
+}
+
+func (h *enqueueRequestsForRouteEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForRouteEvent) enqueueSameNameRoute(queue workqueue.RateLimitingInterface, route client.Object) {
+	glog.V(6).Infof("enqueueRequestsForRouteEvent --> %s-%s\n", route.GetName(), route.GetNamespace())
+	queue.Add(reconcile.Request{
+		NamespacedName: k8s.NamespacedName(route),
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/golang/glog"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -14,8 +15,9 @@ import (
 
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
-	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 )
 
 type enqueueRequetsForServiceEvent struct {
@@ -36,6 +38,13 @@ func (h *enqueueRequetsForServiceEvent) Create(e event.CreateEvent, queue workqu
 }
 
 func (h *enqueueRequetsForServiceEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldService := e.ObjectOld.(*corev1.Service)
+	newService := e.ObjectNew.(*corev1.Service)
+
+	if isServiceAppProtocolChanged(oldService, newService) {
+		glog.V(6).Info("Event: service appProtocol update")
+		h.enqueueImpactedServiceExport(queue, newService)
+	}
 }
 
 func (h *enqueueRequetsForServiceEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
@@ -89,47 +98,52 @@ func (h *enqueueRequetsForServiceEvent) enqueueImpactedServiceExport(queue workq
 }
 
 type enqueueHTTPRequetsForServiceEvent struct {
-	client client.Client
+	client    client.Client
+	routeKind string
 }
 
-func NewEqueueHTTPRequestServiceEvent(client client.Client) handler.EventHandler {
+// NewEqueueHTTPRequestServiceEvent enqueues the routes of routeKind using the service as backend
+func NewEqueueHTTPRequestServiceEvent(client client.Client, routeKind string) handler.EventHandler {
 	return &enqueueHTTPRequetsForServiceEvent{
-		client: client,
+		client:    client,
+		routeKind: routeKind,
 	}
 }
 
 func (h *enqueueHTTPRequetsForServiceEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
 	service := e.Object.(*corev1.Service)
-	h.enqueueImpactedHTTPRoute(queue, service)
+	h.enqueueImpactedRoute(queue, service)
 }
 
 func (h *enqueueHTTPRequetsForServiceEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldService := e.ObjectOld.(*corev1.Service)
+	newService := e.ObjectNew.(*corev1.Service)
+
+	if isServiceAppProtocolChanged(oldService, newService) {
+		h.enqueueImpactedRoute(queue, newService)
+	}
 }
 
 func (h *enqueueHTTPRequetsForServiceEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
 	service := e.Object.(*corev1.Service)
-	h.enqueueImpactedHTTPRoute(queue, service)
+	h.enqueueImpactedRoute(queue, service)
 }
 
 func (h *enqueueHTTPRequetsForServiceEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
 
 }
 
-func (h *enqueueHTTPRequetsForServiceEvent) enqueueImpactedHTTPRoute(queue workqueue.RateLimitingInterface, ep *corev1.Service) {
-	glog.V(6).Infof("Event: enqueueImpactedHTTPRoute: %v\n", ep)
-
-	httpRouteList := &gateway_api.HTTPRouteList{}
-
-	h.client.List(context.TODO(), httpRouteList)
+func (h *enqueueHTTPRequetsForServiceEvent) enqueueImpactedRoute(queue workqueue.RateLimitingInterface, ep *corev1.Service) {
+	glog.V(6).Infof("Event: enqueueImpactedRoute: %v\n", ep)
 
-	for _, httpRoute := range httpRouteList.Items {
-		if !isServiceUsedByHTTPRoute(httpRoute, ep) {
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if !isBackendUsedByRoute(route, "Service", ep.Name, ep.Namespace) {
 			continue
 		}
-		glog.V(6).Infof("Event: enqueueImpactedHTTPRoute --> httproute %v \n", httpRoute)
+		glog.V(6).Infof("Event: enqueueImpactedRoute --> %s %s-%s \n", route.Kind, route.Name, route.Namespace)
 		namespacedName := types.NamespacedName{
-			Namespace: httpRoute.Namespace,
-			Name:      httpRoute.Name,
+			Namespace: route.Namespace,
+			Name:      route.Name,
 		}
 		queue.Add(reconcile.Request{
 			NamespacedName: namespacedName,
@@ -139,33 +153,45 @@ func (h *enqueueHTTPRequetsForServiceEvent) enqueueImpactedHTTPRoute(queue workq
 
 }
 
-func isServiceUsedByHTTPRoute(httpRoute gateway_api.HTTPRoute, ep *corev1.Service) bool {
-	for _, httpRule := range httpRoute.Spec.Rules {
-		for _, httpBackendRef := range httpRule.BackendRefs {
-			//glog.V(6).Infof("isServiceUsedByHTTPRoute httpBackendRef %v, %v\n", httpBackendRef.BackendObjectReference, ep.Name)
-			if string(*httpBackendRef.BackendObjectReference.Kind) != "service" {
-				glog.V(6).Infof("isServiceUsedByHTTPRoute: kind %v\n", string(*httpBackendRef.BackendObjectReference.Kind))
-				continue
-			}
-
-			if string(httpBackendRef.BackendObjectReference.Name) != ep.Name {
-				//glog.V(6).Infof("isServiceUsedByHTTPRoute, name %v\n", string(httpBackendRef.BackendObjectReference.Name))
-				continue
+// isServiceAppProtocolChanged returns whether the appProtocol of a port changed, which changes the protocol
+// version of the target groups of the service
+func isServiceAppProtocolChanged(oldService *corev1.Service, newService *corev1.Service) bool {
+	appProtocols := func(svc *corev1.Service) map[int32]string {
+		ret := make(map[int32]string)
+		for _, port := range svc.Spec.Ports {
+			if port.AppProtocol != nil {
+				ret[port.Port] = *port.AppProtocol
 			}
+		}
+		return ret
+	}
+	return !equality.Semantic.DeepEqual(appProtocols(oldService), appProtocols(newService))
+}
 
-			namespace := httpRoute.Namespace
-			if httpBackendRef.BackendObjectReference.Namespace != nil {
-				namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-			}
+func isBackendUsedByRoute(route k8s.RouteInfo, kind string, name string, namespace string) bool {
+	for _, backendRef := range route.BackendRefs {
+		backendKind := "Service"
+		if backendRef.Kind != nil {
+			backendKind = string(*backendRef.Kind)
+		}
+		if backendKind != kind {
+			continue
+		}
 
-			if namespace != ep.Namespace {
-				//glog.V(6).Infof("isServiceUsedByHTTPRoute, namespace %v\n", namespace)
-				continue
-			}
+		if string(backendRef.Name) != name {
+			continue
+		}
 
-			return true
+		backendNamespace := route.Namespace
+		if backendRef.Namespace != nil {
+			backendNamespace = string(*backendRef.Namespace)
+		}
 
+		if backendNamespace != namespace {
+			continue
 		}
+
+		return true
 	}
 	return false
 
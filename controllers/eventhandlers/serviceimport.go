@@ -14,17 +14,21 @@ import (
 
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
-	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 )
 
 type enqueueRequestsForServiceImportEvent struct {
-	client client.Client
+	client    client.Client
+	routeKind string
 }
 
-func NewEqueueRequestServiceImportEvent(client client.Client) handler.EventHandler {
+// NewEqueueRequestServiceImportEvent enqueues the routes of routeKind using the serviceimport as backend
+func NewEqueueRequestServiceImportEvent(client client.Client, routeKind string) handler.EventHandler {
 	return &enqueueRequestsForServiceImportEvent{
-		client: client,
+		client:    client,
+		routeKind: routeKind,
 	}
 }
 
@@ -54,21 +58,17 @@ func (h *enqueueRequestsForServiceImportEvent) Generic(e event.GenericEvent, que
 }
 
 func (h *enqueueRequestsForServiceImportEvent) enqueueImpactedService(queue workqueue.RateLimitingInterface, serviceImport *mcs_api.ServiceImport) {
-	glog.V(6).Infof("enqueueImpactedHTTPRoute, serviceImport[%v]\n", serviceImport)
-
-	httpRouteList := &gateway_api.HTTPRouteList{}
+	glog.V(6).Infof("enqueueImpactedRoute, serviceImport[%v]\n", serviceImport)
 
-	h.client.List(context.TODO(), httpRouteList)
-
-	for _, httpRoute := range httpRouteList.Items {
-		if !isServiceImportUsedByHTTPRoute(httpRoute, serviceImport) {
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if !isBackendUsedByRoute(route, "ServiceImport", serviceImport.Name, serviceImport.Namespace) {
 			continue
 		}
 
-		glog.V(6).Infof("enqueueRequestsForServiceImportEvent --> httproute %v\n", httpRoute)
+		glog.V(6).Infof("enqueueRequestsForServiceImportEvent --> %s %s-%s\n", route.Kind, route.Name, route.Namespace)
 		namespacedName := types.NamespacedName{
-			Namespace: httpRoute.Namespace,
-			Name:      httpRoute.Name,
+			Namespace: route.Namespace,
+			Name:      route.Name,
 		}
 
 		queue.Add(reconcile.Request{
@@ -78,29 +78,3 @@ func (h *enqueueRequestsForServiceImportEvent) enqueueImpactedService(queue work
 	}
 
 }
-
-func isServiceImportUsedByHTTPRoute(httpRoute gateway_api.HTTPRoute, serviceImport *mcs_api.ServiceImport) bool {
-	for _, httpRule := range httpRoute.Spec.Rules {
-		for _, httpBackendRef := range httpRule.BackendRefs {
-			if string(*httpBackendRef.BackendObjectReference.Kind) != "serviceimport" {
-				continue
-			}
-
-			if string(httpBackendRef.BackendObjectReference.Name) != serviceImport.Name {
-				continue
-			}
-
-			namespace := httpRoute.Namespace
-			if httpBackendRef.BackendObjectReference.Namespace != nil {
-				namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-			}
-
-			if namespace != serviceImport.Namespace {
-				continue
-			}
-			return true
-		}
-	}
-	return false
-
-}
@@ -0,0 +1,203 @@
+package eventhandlers
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+type enqueueRequestsForTargetGroupPolicyEvent struct {
+	client    client.Client
+	routeKind string
+}
+
+// NewEnqueueRequestTargetGroupPolicyEvent enqueues the routes of routeKind using the service the policy targets
+// as backend, so that the target groups are updated with the policy
+func NewEnqueueRequestTargetGroupPolicyEvent(client client.Client, routeKind string) handler.EventHandler {
+	return &enqueueRequestsForTargetGroupPolicyEvent{
+		client:    client,
+		routeKind: routeKind,
+	}
+}
+
+func (h *enqueueRequestsForTargetGroupPolicyEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	newPolicy := e.Object.(*anv1alpha1.TargetGroupPolicy)
+	h.enqueueImpactedRoutes(queue, newPolicy)
+}
+
+func (h *enqueueRequestsForTargetGroupPolicyEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldPolicy := e.ObjectOld.(*anv1alpha1.TargetGroupPolicy)
+	newPolicy := e.ObjectNew.(*anv1alpha1.TargetGroupPolicy)
+
+	if !equality.Semantic.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		h.enqueueImpactedRoutes(queue, oldPolicy)
+		h.enqueueImpactedRoutes(queue, newPolicy)
+	}
+}
+
+func (h *enqueueRequestsForTargetGroupPolicyEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	oldPolicy := e.Object.(*anv1alpha1.TargetGroupPolicy)
+	h.enqueueImpactedRoutes(queue, oldPolicy)
+}
+
+func (h *enqueueRequestsForTargetGroupPolicyEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueRequestsForTargetGroupPolicyEvent) enqueueImpactedRoutes(queue workqueue.RateLimitingInterface, policy *anv1alpha1.TargetGroupPolicy) {
+	glog.V(6).Infof("enqueueImpactedRoutes, targetGroupPolicy[%s-%s]\n", policy.Name, policy.Namespace)
+
+	if !k8s.IsTargetGroupPolicyTargetService(policy) {
+		return
+	}
+
+	for _, route := range k8s.ListRoutes(context.TODO(), h.client, h.routeKind) {
+		if !isBackendUsedByRoute(route, "Service", string(policy.Spec.TargetRef.Name), policy.Namespace) {
+			continue
+		}
+
+		glog.V(6).Infof("enqueueRequestsForTargetGroupPolicyEvent --> %s %s-%s\n", route.Kind, route.Name, route.Namespace)
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: route.Namespace,
+				Name:      route.Name,
+			},
+		})
+	}
+}
+
+type enqueueServiceExportRequestsForTargetGroupPolicyEvent struct {
+	client client.Client
+}
+
+// NewEqueueServiceExportRequestTargetGroupPolicyEvent enqueues the serviceexport of the service the policy targets
+func NewEqueueServiceExportRequestTargetGroupPolicyEvent(client client.Client) handler.EventHandler {
+	return &enqueueServiceExportRequestsForTargetGroupPolicyEvent{
+		client: client,
+	}
+}
+
+func (h *enqueueServiceExportRequestsForTargetGroupPolicyEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	newPolicy := e.Object.(*anv1alpha1.TargetGroupPolicy)
+	h.enqueueImpactedServiceExport(queue, newPolicy)
+}
+
+func (h *enqueueServiceExportRequestsForTargetGroupPolicyEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldPolicy := e.ObjectOld.(*anv1alpha1.TargetGroupPolicy)
+	newPolicy := e.ObjectNew.(*anv1alpha1.TargetGroupPolicy)
+
+	if !equality.Semantic.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		h.enqueueImpactedServiceExport(queue, oldPolicy)
+		h.enqueueImpactedServiceExport(queue, newPolicy)
+	}
+}
+
+func (h *enqueueServiceExportRequestsForTargetGroupPolicyEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	oldPolicy := e.Object.(*anv1alpha1.TargetGroupPolicy)
+	h.enqueueImpactedServiceExport(queue, oldPolicy)
+}
+
+func (h *enqueueServiceExportRequestsForTargetGroupPolicyEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueueServiceExportRequestsForTargetGroupPolicyEvent) enqueueImpactedServiceExport(queue workqueue.RateLimitingInterface, policy *anv1alpha1.TargetGroupPolicy) {
+	if !k8s.IsTargetGroupPolicyTargetService(policy) {
+		return
+	}
+
+	namespacedName := types.NamespacedName{
+		Namespace: policy.Namespace,
+		Name:      string(policy.Spec.TargetRef.Name),
+	}
+
+	srvExport := &mcs_api.ServiceExport{}
+	if err := h.client.Get(context.TODO(), namespacedName, srvExport); err != nil {
+		glog.V(6).Infof("enqueueImpactedServiceExport, serviceexport not found %v\n", err)
+		return
+	}
+
+	glog.V(6).Infof("enqueueServiceExportRequestsForTargetGroupPolicyEvent --> %v\n", namespacedName)
+	queue.Add(reconcile.Request{
+		NamespacedName: namespacedName,
+	})
+}
+
+type enqueuePolicyRequestsForTargetGroupPolicyTargetEvent struct {
+	client client.Client
+}
+
+// NewEnqueueRequestTargetGroupPolicyTargetEvent enqueues every TargetGroupPolicy targeting the same service as
+// the policy or service of the event, since a policy is only applied when the service exists and it is the
+// oldest policy of the service
+func NewEnqueueRequestTargetGroupPolicyTargetEvent(client client.Client) handler.EventHandler {
+	return &enqueuePolicyRequestsForTargetGroupPolicyTargetEvent{
+		client: client,
+	}
+}
+
+func (h *enqueuePolicyRequestsForTargetGroupPolicyTargetEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueImpactedPolicies(queue, e.Object)
+}
+
+func (h *enqueuePolicyRequestsForTargetGroupPolicyTargetEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	if oldPolicy, ok := e.ObjectOld.(*anv1alpha1.TargetGroupPolicy); ok {
+		newPolicy := e.ObjectNew.(*anv1alpha1.TargetGroupPolicy)
+		if !equality.Semantic.DeepEqual(oldPolicy.Spec.TargetRef, newPolicy.Spec.TargetRef) {
+			h.enqueueImpactedPolicies(queue, oldPolicy)
+			h.enqueueImpactedPolicies(queue, newPolicy)
+		}
+	}
+}
+
+func (h *enqueuePolicyRequestsForTargetGroupPolicyTargetEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueImpactedPolicies(queue, e.Object)
+}
+
+func (h *enqueuePolicyRequestsForTargetGroupPolicyTargetEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+
+}
+
+func (h *enqueuePolicyRequestsForTargetGroupPolicyTargetEvent) enqueueImpactedPolicies(queue workqueue.RateLimitingInterface, obj client.Object) {
+	var svcName types.NamespacedName
+
+	switch o := obj.(type) {
+	case *corev1.Service:
+		svcName = k8s.NamespacedName(o)
+	case *anv1alpha1.TargetGroupPolicy:
+		if !k8s.IsTargetGroupPolicyTargetService(o) {
+			return
+		}
+		svcName = types.NamespacedName{
+			Namespace: o.Namespace,
+			Name:      string(o.Spec.TargetRef.Name),
+		}
+	default:
+		return
+	}
+
+	for _, policy := range k8s.ListTargetGroupPolicies(context.TODO(), h.client, svcName) {
+		glog.V(6).Infof("enqueuePolicyRequestsForTargetGroupPolicyTargetEvent --> %s-%s\n", policy.Name, policy.Namespace)
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: policy.Namespace,
+				Name:      policy.Name,
+			},
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+const (
+	// the exported target groups live in other clusters, there is no event when they are created or deleted
+	exportedTargetGroupScanInterval = 30 * time.Second
+)
+
+// ExportedTargetGroupCache periodically lists the target groups exported by the clusters of all vpcs, once for
+// all the ServiceImports, and indexes them by the name and namespace of the exported service. A ServiceImport
+// whose exported target groups changed is sent on the Events channel
+type ExportedTargetGroupCache struct {
+	targetGroupManager lattice.TargetGroupManager
+	events             chan event.GenericEvent
+
+	lock    sync.RWMutex
+	synced  bool
+	exports map[types.NamespacedName][]lattice.ExportedTargetGroup
+}
+
+func NewExportedTargetGroupCache(cloud lattice_aws.Cloud) *ExportedTargetGroupCache {
+	return &ExportedTargetGroupCache{
+		targetGroupManager: lattice.NewTargetGroupManager(cloud),
+		events:             make(chan event.GenericEvent, 100),
+		exports:            make(map[types.NamespacedName][]lattice.ExportedTargetGroup),
+	}
+}
+
+// Start scans the exported target groups until the context is done, it implements manager.Runnable
+func (c *ExportedTargetGroupCache) Start(ctx context.Context) error {
+	ticker := time.NewTicker(exportedTargetGroupScanInterval)
+	defer ticker.Stop()
+
+	for {
+		c.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events returns the channel of the ServiceImports whose exported target groups changed
+func (c *ExportedTargetGroupCache) Events() <-chan event.GenericEvent {
+	return c.events
+}
+
+// ListExported returns the exported target groups of the service name/namespace, as of the last scan
+func (c *ExportedTargetGroupCache) ListExported(name string, namespace string) ([]lattice.ExportedTargetGroup, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if !c.synced {
+		return nil, errors.New("exported target groups are not listed yet")
+	}
+	return c.exports[types.NamespacedName{Namespace: namespace, Name: name}], nil
+}
+
+// ListAllExported returns the exported target groups of all services, as of the last scan
+func (c *ExportedTargetGroupCache) ListAllExported() ([]lattice.ExportedTargetGroup, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if !c.synced {
+		return nil, errors.New("exported target groups are not listed yet")
+	}
+	var exportedTGs []lattice.ExportedTargetGroup
+	for _, tgs := range c.exports {
+		exportedTGs = append(exportedTGs, tgs...)
+	}
+	return exportedTGs, nil
+}
+
+func (c *ExportedTargetGroupCache) scan(ctx context.Context) {
+	exportedTGs, err := c.targetGroupManager.ListAllExported(ctx)
+	if err != nil {
+		// the last scan is kept, the ServiceImports are not updated from an incomplete list
+		glog.V(2).Infof("ExportedTargetGroupCache: failed to list exported target groups, err %v\n", err)
+		return
+	}
+
+	exports := make(map[types.NamespacedName][]lattice.ExportedTargetGroup)
+	for _, tg := range exportedTGs {
+		name := types.NamespacedName{
+			Namespace: stringPtrValue(tg.Tags[latticemodel.K8SServiceNamespaceKey]),
+			Name:      stringPtrValue(tg.Tags[latticemodel.K8SServiceNameKey]),
+		}
+		if name.Name == "" || name.Namespace == "" {
+			continue
+		}
+		exports[name] = append(exports[name], tg)
+	}
+	for _, tgs := range exports {
+		// lattice does not guarantee the order of the list
+		sort.Slice(tgs, func(i, j int) bool { return tgs[i].ARN < tgs[j].ARN })
+	}
+
+	c.lock.Lock()
+	changed := changedExports(c.exports, exports)
+	c.exports = exports
+	c.synced = true
+	c.lock.Unlock()
+
+	for _, name := range changed {
+		glog.V(6).Infof("ExportedTargetGroupCache: exported target groups of %s changed\n", name)
+		serviceImport := &mcs_api.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name.Name,
+				Namespace: name.Namespace,
+			},
+		}
+		select {
+		case c.events <- event.GenericEvent{Object: serviceImport}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// changedExports returns the services whose exported target groups are not the same in both scans
+func changedExports(previous map[types.NamespacedName][]lattice.ExportedTargetGroup,
+	current map[types.NamespacedName][]lattice.ExportedTargetGroup) []types.NamespacedName {
+	var changed []types.NamespacedName
+	for name, exportedTGs := range current {
+		if !equality.Semantic.DeepEqual(previous[name], exportedTGs) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+func Test_ExportedTargetGroupCache_scan(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	exportedTG := func(arn string, name string, weight int64) lattice.ExportedTargetGroup {
+		return lattice.ExportedTargetGroup{
+			ARN:    arn,
+			VpcID:  "vpc-1",
+			Weight: weight,
+			Tags: map[string]*string{
+				latticemodel.K8SParentRefTypeKey:    aws.String(latticemodel.K8SServiceExportType),
+				latticemodel.K8SServiceNameKey:      aws.String(name),
+				latticemodel.K8SServiceNamespaceKey: aws.String("ns1"),
+			},
+		}
+	}
+
+	mockTGManager := lattice.NewMockTargetGroupManager(c)
+	cache := &ExportedTargetGroupCache{
+		targetGroupManager: mockTGManager,
+		events:             make(chan event.GenericEvent, 10),
+		exports:            make(map[types.NamespacedName][]lattice.ExportedTargetGroup),
+	}
+
+	_, err := cache.ListExported("svc1", "ns1")
+	assert.NotNil(t, err)
+
+	// the first scan enqueues all exported services
+	mockTGManager.EXPECT().ListAllExported(ctx).Return([]lattice.ExportedTargetGroup{
+		exportedTG("arn-1", "svc1", 10),
+		exportedTG("arn-2", "svc2", 10),
+		exportedTG("arn-3", "svc3", 10),
+	}, nil)
+	cache.scan(ctx)
+	assert.Equal(t, []string{"svc1", "svc2", "svc3"}, receivedServiceImports(cache))
+
+	exportedTGs, err := cache.ListExported("svc1", "ns1")
+	assert.Nil(t, err)
+	assert.Equal(t, []lattice.ExportedTargetGroup{exportedTG("arn-1", "svc1", 10)}, exportedTGs)
+
+	// only the services whose exported target groups changed or are gone are enqueued, regardless of the order
+	mockTGManager.EXPECT().ListAllExported(ctx).Return([]lattice.ExportedTargetGroup{
+		exportedTG("arn-4", "svc1", 10),
+		exportedTG("arn-1", "svc1", 10),
+		exportedTG("arn-2", "svc2", 20),
+	}, nil)
+	mockTGManager.EXPECT().ListAllExported(ctx).Return([]lattice.ExportedTargetGroup{
+		exportedTG("arn-1", "svc1", 10),
+		exportedTG("arn-4", "svc1", 10),
+		exportedTG("arn-2", "svc2", 20),
+	}, nil)
+	cache.scan(ctx)
+	assert.Equal(t, []string{"svc1", "svc2", "svc3"}, receivedServiceImports(cache))
+	cache.scan(ctx)
+	assert.Empty(t, receivedServiceImports(cache))
+
+	// a failed scan keeps the last one
+	mockTGManager.EXPECT().ListAllExported(ctx).Return(nil, errors.New("list failed"))
+	cache.scan(ctx)
+	assert.Empty(t, receivedServiceImports(cache))
+	exportedTGs, err = cache.ListAllExported()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(exportedTGs))
+}
+
+// receivedServiceImports returns the sorted names of the ServiceImports sent on the events channel
+func receivedServiceImports(cache *ExportedTargetGroupCache) []string {
+	var names []string
+	for {
+		select {
+		case e := <-cache.events:
+			names = append(names, e.Object.GetName())
+		default:
+			sort.Strings(names)
+			return names
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
@@ -141,33 +142,16 @@ func (r *GatewayReconciler) reconcile(ctx context.Context, req ctrl.Request) err
 
 			glog.V(6).Info(fmt.Sprintf("Checking if gateway can be deleted %v\n", gw.Name))
 
-			httpRouteList := &gateway_api.HTTPRouteList{}
+			for _, route := range k8s.ListRoutes(context.TODO(), r.Client) {
 
-			r.Client.List(context.TODO(), httpRouteList)
-			for _, httpRoute := range httpRouteList.Items {
+				for _, parentRef := range route.ParentRefs {
+					gwName := k8s.ParentGatewayName(route.Namespace, parentRef)
 
-				if len(httpRoute.Spec.ParentRefs) <= 0 {
-					continue
-				}
-				gwNamespace := httpRoute.Namespace
-				if httpRoute.Spec.ParentRefs[0].Namespace != nil {
-					gwNamespace = string(*httpRoute.Spec.ParentRefs[0].Namespace)
-				}
-				gwName := types.NamespacedName{
-					Namespace: gwNamespace,
-					Name:      string(httpRoute.Spec.ParentRefs[0].Name),
-				}
-
-				httpGW := &gateway_api.Gateway{}
-
-				if err := r.Client.Get(context.TODO(), gwName, httpGW); err != nil {
-					continue
-				}
-
-				if httpGW.Name == gw.Name && httpGW.Namespace == gw.Namespace {
+					if gwName.Name == gw.Name && gwName.Namespace == gw.Namespace {
 
-					gwLog.Info("Can not delete because it is referenced by some HTTPRoutes")
-					return errors.New("retry later, since it is referenced by some HTTPRoutes")
+						gwLog.Info(fmt.Sprintf("Can not delete because it is referenced by %v %v", route.Kind, route.Name))
+						return errors.New("retry later, since it is referenced by some routes")
+					}
 				}
 
 			}
@@ -334,56 +318,85 @@ func (r *GatewayReconciler) updateBadStatus(ctx context.Context, message string,
 }
 
 func UpdateHTTPRouteListenerStatus(ctx context.Context, k8sclient client.Client, httproute *gateway_api.HTTPRoute) error {
-	gw := &gateway_api.Gateway{}
+	route, _ := k8s.NewRouteInfo(httproute)
+	return updateRouteListenerStatus(ctx, k8sclient, route)
+}
 
-	gwNamespace := httproute.Namespace
-	if httproute.Spec.ParentRefs[0].Namespace != nil {
-		gwNamespace = string(*httproute.Spec.ParentRefs[0].Namespace)
-	}
-	gwName := types.NamespacedName{
-		Namespace: gwNamespace,
-		// TODO assume one parent for now and point to service network
-		Name: string(httproute.Spec.ParentRefs[0].Name),
-	}
+func UpdateGRPCRouteListenerStatus(ctx context.Context, k8sclient client.Client, grpcroute *gateway_api_v1alpha2.GRPCRoute) error {
+	route, _ := k8s.NewRouteInfo(grpcroute)
+	return updateRouteListenerStatus(ctx, k8sclient, route)
+}
+
+func UpdateTLSRouteListenerStatus(ctx context.Context, k8sclient client.Client, tlsroute *gateway_api_v1alpha2.TLSRoute) error {
+	route, _ := k8s.NewRouteInfo(tlsroute)
+	return updateRouteListenerStatus(ctx, k8sclient, route)
+}
+
+// updateRouteListenerStatus updates the listener status of every lattice gateway the route refers to
+func updateRouteListenerStatus(ctx context.Context, k8sclient client.Client, route k8s.RouteInfo) error {
+	parents := k8s.ValidateRouteParents(ctx, k8sclient, route)
 
-	if err := k8sclient.Get(ctx, gwName, gw); err != nil {
-		glog.V(2).Infof("Failed to update gateway listener status due to gatewag not found for %v\n", httproute.Spec)
+	if len(parents) == 0 {
+		glog.V(2).Infof("Failed to update gateway listener status due to gatewag not found for %v\n", route.ParentRefs)
 		return errors.New("gateway not found")
 	}
 
-	return UpdateGWListenerStatus(ctx, k8sclient, gw)
+	updated := make(map[types.NamespacedName]bool)
+	var lastErr error
+	for _, parent := range parents {
+		gwName := types.NamespacedName{Namespace: parent.Gateway.Namespace, Name: parent.Gateway.Name}
+		if updated[gwName] {
+			continue
+		}
+		updated[gwName] = true
+
+		if err := UpdateGWListenerStatus(ctx, k8sclient, parent.Gateway); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
 }
 
 func listenerRouteGroupKindSupported(listener gateway_api.Listener) (bool, []gateway_api.RouteGroupKind) {
-	defaultSupportedKind := []gateway_api.RouteGroupKind{
-		gateway_api.RouteGroupKind{
-			Kind: "HTTPRoute",
-		},
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		defaultSupportedKind := make([]gateway_api.RouteGroupKind, 0)
+		for _, kind := range k8s.ListenerDefaultRouteKinds(listener) {
+			defaultSupportedKind = append(defaultSupportedKind, gateway_api.RouteGroupKind{
+				Kind: gateway_api.Kind(kind),
+			})
+		}
+		return true, defaultSupportedKind
 	}
 
 	validRoute := true
 	supportedKind := make([]gateway_api.RouteGroupKind, 0)
 
 	for _, routeGroupKind := range listener.AllowedRoutes.Kinds {
-		// today, controller only support HTTPRoute
-		if routeGroupKind.Kind != "HTTPRoute" {
+		// today, controller only support HTTPRoute and GRPCRoute on HTTP(S) listeners and TLSRoute on TLS listeners
+		if !listenerRouteKindSupported(listener, string(routeGroupKind.Kind)) {
 			validRoute = false
 		} else {
 			supportedKind = append(supportedKind, gateway_api.RouteGroupKind{
-				Kind: "HTTPRoute",
+				Kind: routeGroupKind.Kind,
 			})
 		}
 
 	}
 
-	if validRoute {
-		return true, defaultSupportedKind
-	} else {
-		return false, supportedKind
-	}
+	return validRoute, supportedKind
 
 }
 
+func listenerRouteKindSupported(listener gateway_api.Listener, kind string) bool {
+	for _, supportedKind := range k8s.ListenerDefaultRouteKinds(listener) {
+		if kind == supportedKind {
+			return true
+		}
+	}
+	return false
+}
+
 func UpdateGWListenerStatus(ctx context.Context, k8sclient client.Client, gw *gateway_api.Gateway) error {
 	hasValidListener := false
 
@@ -393,18 +406,16 @@ func UpdateGWListenerStatus(ctx context.Context, k8sclient client.Client, gw *ga
 
 	gw.Status.Listeners = make([]gateway_api.ListenerStatus, 0)
 
-	httpRouteList := &gateway_api.HTTPRouteList{}
-
-	k8sclient.List(context.TODO(), httpRouteList)
+	routes := k8s.ListRoutes(context.TODO(), k8sclient)
 
 	// Add one of lattice domains as GW address. This can represent incorrect value in some cases (e.g. cross-account)
 	// TODO: support multiple endpoint addresses across services.
-	if len(httpRouteList.Items) > 0 {
+	if len(routes) > 0 {
 
 		gw.Status.Addresses = []gateway_api.GatewayAddress{}
 
 		addressType := gateway_api.HostnameAddressType
-		for _, route := range httpRouteList.Items {
+		for _, route := range routes {
 			if route.DeletionTimestamp.IsZero() && len(route.Annotations) > 0 {
 				if domain, exists := route.Annotations[LatticeAssignedDomainName]; exists {
 					gw.Status.Addresses = append(gw.Status.Addresses, gateway_api.GatewayAddress{
@@ -421,8 +432,6 @@ func UpdateGWListenerStatus(ctx context.Context, k8sclient client.Client, gw *ga
 		return errors.New("no gateway listner found")
 	}
 
-	defaultListener := gw.Spec.Listeners[0]
-
 	// go through each section of gw
 	for _, listener := range gw.Spec.Listeners {
 
@@ -460,42 +469,26 @@ func UpdateGWListenerStatus(ctx context.Context, k8sclient client.Client, gw *ga
 				LastTransitionTime: metav1.NewTime(time.Now()),
 			}
 
-			for _, httpRoute := range httpRouteList.Items {
-				if !httpRoute.DeletionTimestamp.IsZero() {
-					// Ignore the delete httproute
+			for _, route := range routes {
+				if !route.DeletionTimestamp.IsZero() {
+					// Ignore the delete route
 					continue
 				}
-				for _, parentRef := range httpRoute.Spec.ParentRefs {
-					if parentRef.Name != gateway_api.ObjectName(gw.Name) {
-						continue
-					}
-
-					if parentRef.Namespace != nil &&
-						*parentRef.Namespace != gateway_api.Namespace(gw.Namespace) {
+				for _, parentRef := range route.ParentRefs {
+					if k8s.ParentGatewayName(route.Namespace, parentRef) != (types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}) {
 						continue
 					}
-					attachedRoutes++
-
-					var httpSectionName string
-					if parentRef.SectionName == nil {
-						httpSectionName = string(defaultListener.Name)
 
-					} else {
-						httpSectionName = string(*parentRef.SectionName)
+					parent := k8s.NewRouteParent(ctx, k8sclient, gw, parentRef, route.Kind, route.Namespace)
+					for _, attachedListener := range parent.Listeners {
+						if attachedListener.Name == listener.Name {
+							listenerStatus.AttachedRoutes++
+							attachedRoutes++
+						}
 					}
-
-					if httpSectionName != string(listener.Name) {
-						continue
-					}
-					listenerStatus.AttachedRoutes++
-					attachedRoutes++
-
 				}
 			}
-			httpKind := gateway_api.RouteGroupKind{
-				Kind: "HTTPRoute",
-			}
-			listenerStatus.SupportedKinds = append(listenerStatus.SupportedKinds, httpKind)
+			listenerStatus.SupportedKinds = supportedkind
 			listenerStatus.Conditions = append(listenerStatus.Conditions, condition)
 
 		}
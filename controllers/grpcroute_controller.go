@@ -0,0 +1,289 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	lattice_runtime "github.com/aws/aws-application-networking-k8s/pkg/runtime"
+)
+
+// GRPCRouteReconciler reconciles a GRPCRoute object
+type GRPCRouteReconciler struct {
+	client.Client
+	Scheme            *runtime.Scheme
+	gwReconciler      *GatewayReconciler
+	gwClassReconciler *GatewayClassReconciler
+	finalizerManager  k8s.FinalizerManager
+	eventRecorder     record.EventRecorder
+	modelBuilder      gateway.GRPCLatticeServiceBuilder
+	stackDeployer     deploy.StackDeployer
+	latticeDataStore  *latticestore.LatticeDataStore
+	stackMashaller    deploy.StackMarshaller
+}
+
+const (
+	grpcRouteFinalizer = "grpcroute.k8s.aws/resources"
+)
+
+func NewGRPCRouteReconciler(cloud aws.Cloud, client client.Client, scheme *runtime.Scheme, eventRecorder record.EventRecorder,
+	gwReconciler *GatewayReconciler, gwClassReconciler *GatewayClassReconciler, finalizerManager k8s.FinalizerManager,
+	latticeDataStore *latticestore.LatticeDataStore) *GRPCRouteReconciler {
+	modelBuilder := gateway.NewGRPCLatticeServiceBuilder(client, latticeDataStore, cloud)
+	stackDeployer := deploy.NewLatticeServiceStackDeploy(cloud, client, latticeDataStore)
+	stackMarshaller := deploy.NewDefaultStackMarshaller()
+
+	return &GRPCRouteReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		gwReconciler:      gwReconciler,
+		gwClassReconciler: gwClassReconciler,
+		finalizerManager:  finalizerManager,
+		modelBuilder:      modelBuilder,
+		stackDeployer:     stackDeployer,
+		eventRecorder:     eventRecorder,
+		latticeDataStore:  latticeDataStore,
+		stackMashaller:    stackMarshaller,
+	}
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=grpcroutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=grpcroutes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=grpcroutes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return lattice_runtime.HandleReconcileError(r.reconcile(ctx, req))
+}
+
+func (r *GRPCRouteReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	grpcLog := log.FromContext(ctx)
+
+	grpcLog.Info("GRPCRouteReconciler")
+
+	grpcRoute := &gateway_api_v1alpha2.GRPCRoute{}
+
+	if err := r.Client.Get(ctx, req.NamespacedName, grpcRoute); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !r.isGRPCRouteRelevant(ctx, grpcRoute) {
+		// not relevant
+		return nil
+	}
+
+	if conflict := k8s.FindConflictingRoute(ctx, r.Client, grpcRoute); conflict != nil {
+		glog.V(2).Infof("GRPCRoute %s-%s conflicts, %v\n", grpcRoute.Name, grpcRoute.Namespace, conflict)
+		if !grpcRoute.DeletionTimestamp.IsZero() {
+			// the lattice service of the name belongs to the other route, there is nothing to clean up
+			r.finalizerManager.RemoveFinalizers(ctx, grpcRoute, grpcRouteFinalizer)
+			return nil
+		}
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeWarning, k8s.GRPCRouteEventReasonConflicted, conflict.Error())
+		return updateRouteParentStatus(ctx, r.Client, r.eventRecorder, grpcRoute, "", conflict)
+	}
+
+	if !grpcRoute.DeletionTimestamp.IsZero() {
+		grpcLog.Info("Deleting")
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeNormal,
+			k8s.GRPCRouteEventReasonReconcile, "Deleting Reconcile")
+		if err := r.cleanupGRPCRouteResources(ctx, grpcRoute); err != nil {
+			glog.V(6).Infof("Failed to cleanup GRPCRoute %v err %v\n", grpcRoute, err)
+			return err
+		}
+		UpdateGRPCRouteListenerStatus(ctx, r.Client, grpcRoute)
+		r.finalizerManager.RemoveFinalizers(ctx, grpcRoute, grpcRouteFinalizer)
+
+		return nil
+	} else {
+		grpcLog.Info("Adding/Updating")
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeNormal,
+			k8s.GRPCRouteEventReasonReconcile, "Adding/Updating Reconcile")
+		return r.reconcileGRPCRouteResource(ctx, grpcRoute)
+	}
+
+}
+
+func (r *GRPCRouteReconciler) cleanupGRPCRouteResources(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) error {
+
+	_, _, err := r.buildAndDeployModel(ctx, grpcRoute)
+
+	return err
+}
+
+func (r *GRPCRouteReconciler) isGRPCRouteRelevant(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) bool {
+
+	if len(grpcRoute.Spec.ParentRefs) == 0 {
+		glog.V(6).Infof("Ignore GRPCRoute which has no ParentRefs gateway %v \n ", grpcRoute.Spec)
+		return false
+	}
+
+	route, _ := k8s.NewRouteInfo(grpcRoute)
+
+	// relevant as long as one of the parents is a aws-vpc-lattice gateway
+	if len(k8s.ValidateRouteParents(ctx, r.Client, route)) == 0 {
+		glog.V(6).Infof("Ignore non aws-vpc-lattice GRPCRoute !!! %v\n", grpcRoute.Spec)
+		return false
+	}
+
+	glog.V(6).Infof("Found aws-vpc-lattice for GRPCRoute for %v\n", grpcRoute.Spec)
+	return true
+}
+
+func (r *GRPCRouteReconciler) buildAndDeployModel(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) (core.Stack, *latticemodel.Service, error) {
+	grpcLog := log.FromContext(ctx)
+
+	stack, latticeService, err := r.modelBuilder.Build(ctx, grpcRoute)
+
+	if err != nil {
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeWarning,
+			k8s.GRPCRouteEventReasonFailedBuildModel, fmt.Sprintf("Failed build model due to %v", err))
+		glog.V(6).Infof("buildAndDeployModel, Failed build model for %v due to %v\n", grpcRoute.Name, err)
+
+		if grpcRoute.DeletionTimestamp.IsZero() {
+			updateRouteParentStatus(ctx, r.Client, r.eventRecorder, grpcRoute, "", err)
+		}
+
+		return nil, nil, err
+	}
+
+	stackJSON, err := r.stackMashaller.Marshal(stack)
+	if err != nil {
+		glog.V(6).Infof("error on r.stackMashaller.Marshal error %v \n", err)
+	}
+
+	grpcLog.Info("Successfully built model:", stackJSON, "")
+
+	if err := r.stackDeployer.Deploy(ctx, stack); err != nil {
+		glog.V(6).Infof("GRPCRouteReconciler: Failed deploy %s due to err %v \n", grpcRoute.Name, err)
+
+		var retryErr = errors.New(lattice.LATTICE_RETRY)
+
+		if errors.As(err, &retryErr) {
+			r.eventRecorder.Event(grpcRoute, corev1.EventTypeNormal,
+				k8s.GRPCRouteEventReasonRetryReconcile, "retry reconcile...")
+
+		} else {
+			r.eventRecorder.Event(grpcRoute, corev1.EventTypeWarning,
+				k8s.GRPCRouteEventReasonFailedDeployModel, fmt.Sprintf("Failed deploy model due to %v", err))
+		}
+		return nil, nil, err
+	}
+
+	grpcLog.Info("Successfully deployed model")
+
+	return stack, latticeService, err
+}
+
+func (r *GRPCRouteReconciler) reconcileGRPCRouteResource(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) error {
+	glog.V(6).Infof("Beginning -- reconcileGRPCRouteResource, [%v]\n", grpcRoute)
+
+	if err := r.finalizerManager.AddFinalizers(ctx, grpcRoute, grpcRouteFinalizer); err != nil {
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeWarning, k8s.GRPCRouteEventReasonFailedAddFinalizer, fmt.Sprintf("Failed add finalizer due to %v", err))
+	}
+
+	_, _, err := r.buildAndDeployModel(ctx, grpcRoute)
+
+	if err == nil {
+		r.eventRecorder.Event(grpcRoute, corev1.EventTypeNormal,
+			k8s.GRPCRouteEventReasonDeploySucceed, "Adding/Updating reconcile Done!")
+
+		serviceStatus, err1 := r.latticeDataStore.GetLatticeService(grpcRoute.Name, grpcRoute.Namespace)
+
+		if err1 == nil {
+			r.updateGRPCRouteStatus(ctx, serviceStatus.DNS, grpcRoute)
+		}
+	}
+
+	return err
+
+}
+
+func (r *GRPCRouteReconciler) updateGRPCRouteStatus(ctx context.Context, dns string, grpcRoute *gateway_api_v1alpha2.GRPCRoute) error {
+	glog.V(6).Infof("updateGRPCRouteStatus: grpcroute %v, dns %v\n", grpcRoute, dns)
+	grpcRouteOld := grpcRoute.DeepCopy()
+
+	if len(grpcRoute.ObjectMeta.Annotations) == 0 {
+		grpcRoute.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	grpcRoute.ObjectMeta.Annotations[LatticeAssignedDomainName] = dns
+
+	if err := r.Client.Patch(ctx, grpcRoute, client.MergeFrom(grpcRouteOld)); err != nil {
+		glog.V(2).Infof("updateGRPCRouteStatus: Patch() received err %v \n", err)
+		return errors.Wrapf(err, "failed to update grpcroute status")
+	}
+
+	// Update listener Status
+	UpdateGRPCRouteListenerStatus(ctx, r.Client, grpcRoute)
+
+	if err := updateRouteParentStatus(ctx, r.Client, r.eventRecorder, grpcRoute, dns, nil); err != nil {
+		return err
+	}
+
+	glog.V(6).Infof("updateGRPCRouteStatus patched dns %v \n", dns)
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GRPCRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	routeEventHandler := eventhandlers.NewEnqueueRequestRouteEvent()
+	gwEventHandler := eventhandlers.NewEnqueueRequestGatewayEvent(r.Client, k8s.GRPCRouteKind)
+	svcEventHandler := eventhandlers.NewEqueueHTTPRequestServiceEvent(r.Client, k8s.GRPCRouteKind)
+	svcImportEventHandler := eventhandlers.NewEqueueRequestServiceImportEvent(r.Client, k8s.GRPCRouteKind)
+	refGrantEventHandler := eventhandlers.NewEnqueueRequestReferenceGrantEvent(r.Client, k8s.GRPCRouteKind)
+	namespaceEventHandler := eventhandlers.NewEnqueueRequestNamespaceEvent(r.Client, k8s.GRPCRouteKind)
+	tgPolicyEventHandler := eventhandlers.NewEnqueueRequestTargetGroupPolicyEvent(r.Client, k8s.GRPCRouteKind)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gateway_api_v1alpha2.GRPCRoute{}).
+		Watches(&source.Kind{Type: &gateway_api.HTTPRoute{}}, routeEventHandler).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.TLSRoute{}}, routeEventHandler).
+		Watches(&source.Kind{Type: &gateway_api.Gateway{}}, gwEventHandler).
+		Watches(&source.Kind{Type: &corev1.Service{}}, svcEventHandler).
+		Watches(&source.Kind{Type: &mcs_api.ServiceImport{}}, svcImportEventHandler).
+		Watches(&source.Kind{Type: &gateway_api.ReferenceGrant{}}, refGrantEventHandler).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, namespaceEventHandler).
+		Watches(&source.Kind{Type: &anv1alpha1.TargetGroupPolicy{}}, tgPolicyEventHandler).
+		Complete(r)
+}
@@ -19,26 +19,24 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
 	"github.com/aws/aws-application-networking-k8s/pkg/aws"
-	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/deploy"
 	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
 	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
@@ -92,6 +90,9 @@ func NewHttpRouteReconciler(cloud aws.Cloud, client client.Client, scheme *runti
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=application-networking.k8s.aws,resources=lambdatargets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -123,6 +124,17 @@ func (r *HTTPRouteReconciler) reconcile(ctx context.Context, req ctrl.Request) e
 		return nil
 	}
 
+	if conflict := k8s.FindConflictingRoute(ctx, r.Client, httpRoute); conflict != nil {
+		glog.V(2).Infof("HTTPRoute %s-%s conflicts, %v\n", httpRoute.Name, httpRoute.Namespace, conflict)
+		if !httpRoute.DeletionTimestamp.IsZero() {
+			// the lattice service of the name belongs to the other route, there is nothing to clean up
+			r.finalizerManager.RemoveFinalizers(ctx, httpRoute, httpRouteFinalizer)
+			return nil
+		}
+		r.eventRecorder.Event(httpRoute, corev1.EventTypeWarning, k8s.HTTPRouteEventReasonConflicted, conflict.Error())
+		return updateRouteParentStatus(ctx, r.Client, r.eventRecorder, httpRoute, "", conflict)
+	}
+
 	if !httpRoute.DeletionTimestamp.IsZero() {
 		httpLog.Info("Deleting")
 		r.eventRecorder.Event(httpRoute, corev1.EventTypeNormal,
@@ -161,45 +173,16 @@ func (r *HTTPRouteReconciler) isHTTPRouteRelevant(ctx context.Context, httpRoute
 		return false
 	}
 
-	gw := &gateway_api.Gateway{}
-
-	// TODO handle multiple parentRefs
-	gwNamespace := httpRoute.Namespace
-	if httpRoute.Spec.ParentRefs[0].Namespace != nil {
-		gwNamespace = string(*httpRoute.Spec.ParentRefs[0].Namespace)
-	}
-	gwName := types.NamespacedName{
-		Namespace: gwNamespace,
-		// TODO assume one parent for now and point to service network
-		Name: string(httpRoute.Spec.ParentRefs[0].Name),
-	}
-
-	if err := r.gwReconciler.Client.Get(ctx, gwName, gw); err != nil {
-		glog.V(6).Infof("Could not find gateway %s: %s\n", gwName.String(), err.Error())
-		glog.V(6).Infof("Ignore HTTPRoute whose ParentRef gatway object has NOT defined yet for %v\n", httpRoute.Spec)
-		return false
-	}
-
-	// make sure gateway is a aws-vpc-lattice
-	gwClass := &gateway_api.GatewayClass{}
-	gwClassName := types.NamespacedName{
-		Namespace: "default",
-		Name:      string(gw.Spec.GatewayClassName),
-	}
-
-	if err := r.gwClassReconciler.Client.Get(ctx, gwClassName, gwClass); err != nil {
-		glog.V(6).Infof("Ignore HTTPRoute that NOT controlled by any GatewayClass for %v\n", httpRoute.Spec)
-		return false
-	}
-
-	if gwClass.Spec.ControllerName == config.LatticeGatewayControllerName {
-		glog.V(6).Infof("Found aws-vpc-lattice for HTTPRoute for %v\n", httpRoute.Spec)
+	route, _ := k8s.NewRouteInfo(httpRoute)
 
-		return true
-	} else {
+	// relevant as long as one of the parents is a aws-vpc-lattice gateway
+	if len(k8s.ValidateRouteParents(ctx, r.Client, route)) == 0 {
 		glog.V(6).Infof("Ignore non aws-vpc-lattice HTTPRoute !!! %v\n", httpRoute.Spec)
 		return false
 	}
+
+	glog.V(6).Infof("Found aws-vpc-lattice for HTTPRoute for %v\n", httpRoute.Spec)
+	return true
 }
 
 func (r *HTTPRouteReconciler) buildAndDeployModel(ctx context.Context, httproute *gateway_api.HTTPRoute) (core.Stack, *latticemodel.Service, error) {
@@ -213,6 +196,10 @@ func (r *HTTPRouteReconciler) buildAndDeployModel(ctx context.Context, httproute
 			k8s.HTTPRouteEventReasonFailedBuildModel, fmt.Sprintf("Failed build model due to %v", err))
 		glog.V(6).Infof("buildAndDeployModel, Failed build model for %v due to %v\n", httproute.Name, err)
 
+		if httproute.DeletionTimestamp.IsZero() {
+			updateRouteParentStatus(ctx, r.Client, r.eventRecorder, httproute, "", err)
+		}
+
 		// Build failed
 		// TODO continue deploy to trigger reconsile of stale HTTProute and policy
 		return nil, nil, err
@@ -288,35 +275,11 @@ func (r *HTTPRouteReconciler) updateHTTPRouteStatus(ctx context.Context, dns str
 		return errors.Wrapf(err, "failed to update httproute status")
 	}
 
-	httprouteOld = httproute.DeepCopy()
-	if len(httproute.Status.RouteStatus.Parents) == 0 {
-		httproute.Status.RouteStatus.Parents = make([]gateway_api.RouteParentStatus, 1)
-		httproute.Status.RouteStatus.Parents[0].Conditions = make([]metav1.Condition, 1)
-		httproute.Status.RouteStatus.Parents[0].Conditions[0].LastTransitionTime = eventhandlers.ZeroTransitionTime
-	}
-
-	httproute.Status.RouteStatus.Parents[0].ControllerName = config.LatticeGatewayControllerName
-
-	httproute.Status.RouteStatus.Parents[0].Conditions[0].Type = string(gateway_api.RouteConditionAccepted)
-	httproute.Status.RouteStatus.Parents[0].Conditions[0].Status = metav1.ConditionTrue
-	httproute.Status.RouteStatus.Parents[0].Conditions[0].Message = fmt.Sprintf("DNS Name: %s", dns)
-	httproute.Status.RouteStatus.Parents[0].Conditions[0].Reason = string(gateway_api.RouteReasonAccepted)
-	httproute.Status.RouteStatus.Parents[0].Conditions[0].ObservedGeneration = httproute.Generation
-
-	if httproute.Status.RouteStatus.Parents[0].Conditions[0].LastTransitionTime == eventhandlers.ZeroTransitionTime {
-		httproute.Status.RouteStatus.Parents[0].Conditions[0].LastTransitionTime = metav1.NewTime(time.Now())
-	}
-
-	httproute.Status.RouteStatus.Parents[0].ParentRef.Group = httproute.Spec.ParentRefs[0].Group
-	httproute.Status.RouteStatus.Parents[0].ParentRef.Kind = httproute.Spec.ParentRefs[0].Kind
-	httproute.Status.RouteStatus.Parents[0].ParentRef.Name = httproute.Spec.ParentRefs[0].Name
-
 	// Update listener Status
 	UpdateHTTPRouteListenerStatus(ctx, r.Client, httproute)
 
-	if err := r.Client.Status().Patch(ctx, httproute, client.MergeFrom(httprouteOld)); err != nil {
-		glog.V(2).Infof("updateHTTPRouteStatus: Patch() received err %v \n", err)
-		return errors.Wrapf(err, "failed to update httproute status")
+	if err := updateRouteParentStatus(ctx, r.Client, r.eventRecorder, httproute, dns, nil); err != nil {
+		return err
 	}
 
 	glog.V(6).Infof("updateHTTPRouteStatus patched dns %v \n", dns)
@@ -326,14 +289,25 @@ func (r *HTTPRouteReconciler) updateHTTPRouteStatus(ctx context.Context, dns str
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *HTTPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	gwEventHandler := eventhandlers.NewEnqueueRequestGatewayEvent(r.Client)
-	svcEventHandler := eventhandlers.NewEqueueHTTPRequestServiceEvent(r.Client)
-	svcImportEventHandler := eventhandlers.NewEqueueRequestServiceImportEvent(r.Client)
+	routeEventHandler := eventhandlers.NewEnqueueRequestRouteEvent()
+	gwEventHandler := eventhandlers.NewEnqueueRequestGatewayEvent(r.Client, k8s.HTTPRouteKind)
+	svcEventHandler := eventhandlers.NewEqueueHTTPRequestServiceEvent(r.Client, k8s.HTTPRouteKind)
+	svcImportEventHandler := eventhandlers.NewEqueueRequestServiceImportEvent(r.Client, k8s.HTTPRouteKind)
+	refGrantEventHandler := eventhandlers.NewEnqueueRequestReferenceGrantEvent(r.Client, k8s.HTTPRouteKind)
+	namespaceEventHandler := eventhandlers.NewEnqueueRequestNamespaceEvent(r.Client, k8s.HTTPRouteKind)
+	tgPolicyEventHandler := eventhandlers.NewEnqueueRequestTargetGroupPolicyEvent(r.Client, k8s.HTTPRouteKind)
+	lambdaTargetEventHandler := eventhandlers.NewEnqueueRequestLambdaTargetEvent(r.Client, k8s.HTTPRouteKind)
 	return ctrl.NewControllerManagedBy(mgr).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		For(&gateway_api.HTTPRoute{}).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.GRPCRoute{}}, routeEventHandler).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.TLSRoute{}}, routeEventHandler).
 		Watches(&source.Kind{Type: &gateway_api.Gateway{}}, gwEventHandler).
 		Watches(&source.Kind{Type: &corev1.Service{}}, svcEventHandler).
 		Watches(&source.Kind{Type: &mcs_api.ServiceImport{}}, svcImportEventHandler).
+		Watches(&source.Kind{Type: &gateway_api.ReferenceGrant{}}, refGrantEventHandler).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, namespaceEventHandler).
+		Watches(&source.Kind{Type: &anv1alpha1.TargetGroupPolicy{}}, tgPolicyEventHandler).
+		Watches(&source.Kind{Type: &anv1alpha1.LambdaTarget{}}, lambdaTargetEventHandler).
 		Complete(r)
 }
@@ -18,38 +18,60 @@ package controllers
 
 import (
 	"context"
-	//"fmt"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/golang/glog"
+
+	"github.com/aws/aws-sdk-go/aws"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 )
 
-// PodReconciler reconciles a Pod object
+const (
+	// how often the health of the lattice targets of a pod is checked until they are healthy
+	podReadinessGatePollInterval = 10 * time.Second
+)
+
+// PodReconciler sets the lattice readiness gate condition of pods, which becomes True once all the
+// lattice targets of the pod are healthy
 type PodReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme           *runtime.Scheme
+	latticeDataStore *latticestore.LatticeDataStore
+	// targetHealth lists the targets, the pods of a target group share its cached targets
+	targetHealth *TargetHealthCollector
+}
+
+func NewPodReconciler(client client.Client, scheme *runtime.Scheme, ds *latticestore.LatticeDataStore,
+	targetHealth *TargetHealthCollector) *PodReconciler {
+	return &PodReconciler{
+		Client:           client,
+		Scheme:           scheme,
+		latticeDataStore: ds,
+		targetHealth:     targetHealth,
+	}
 }
 
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=pods/finalizers,verbs=update
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Pod object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+// Reconcile polls the health of the lattice targets of pods carrying the lattice readiness gate, and sets
+// the readiness gate condition accordingly, so that rollouts wait for the new pods to receive lattice traffic
 func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	podLog := log.FromContext(ctx)
 
-	// TODO(user): your logic here
 	podLog.Info("PodReconcile")
 
 	pod := &corev1.Pod{}
@@ -58,9 +80,133 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !k8s.HasPodReadinessGate(pod) || !pod.DeletionTimestamp.IsZero() || pod.Status.PodIP == "" {
+		return ctrl.Result{}, nil
+	}
+
+	if condition := k8s.GetPodCondition(pod, k8s.PodReadinessGateConditionType); condition != nil &&
+		condition.Status == corev1.ConditionTrue {
+		return ctrl.Result{}, nil
+	}
+
+	condition, err := r.buildReadinessGateCondition(ctx, pod)
+	if err != nil {
+		glog.V(6).Infof("Failed to check lattice targets of pod %v, err %v\n", req.NamespacedName, err)
+		return ctrl.Result{RequeueAfter: podReadinessGatePollInterval}, nil
+	}
+
+	podOld := pod.DeepCopy()
+	k8s.SetPodCondition(pod, condition)
+	if err := r.Client.Status().Patch(ctx, pod, client.MergeFrom(podOld)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if condition.Status != corev1.ConditionTrue {
+		return ctrl.Result{RequeueAfter: podReadinessGatePollInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// buildReadinessGateCondition finds the target groups of the services backed by the pod, and returns a True
+// condition when the pod is registered and healthy in all of them
+func (r *PodReconciler) buildReadinessGateCondition(ctx context.Context, pod *corev1.Pod) (corev1.PodCondition, error) {
+	condition := corev1.PodCondition{
+		Type: k8s.PodReadinessGateConditionType,
+	}
+
+	var targetGroupIDs []string
+	for _, tgName := range r.podTargetGroupNames(ctx, pod) {
+		for _, tg := range r.latticeDataStore.GetTargetGroupsByTG(tgName) {
+			if tg.ID != "" {
+				targetGroupIDs = append(targetGroupIDs, tg.ID)
+			}
+		}
+	}
+
+	if len(targetGroupIDs) == 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = k8s.PodReadinessGateReasonTargetNotFound
+		condition.Message = "Pod is not backing any lattice target group"
+		return condition, nil
+	}
+
+	var notHealthy []string
+	for _, tgID := range targetGroupIDs {
+		targets, err := r.targetHealth.Targets(ctx, tgID)
+		if err != nil {
+			return condition, err
+		}
+
+		registered := false
+		for _, target := range targets {
+			if aws.StringValue(target.Id) != pod.Status.PodIP {
+				continue
+			}
+			registered = true
+			if !lattice.IsTargetHealthy(target) {
+				notHealthy = append(notHealthy, fmt.Sprintf("%s:%s", tgID, aws.StringValue(target.Status)))
+			}
+		}
+		if !registered {
+			notHealthy = append(notHealthy, fmt.Sprintf("%s:NOT_REGISTERED", tgID))
+		}
+	}
+
+	if len(notHealthy) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = k8s.PodReadinessGateReasonTargetNotHealthy
+		condition.Message = fmt.Sprintf("Lattice targets not healthy: %s", strings.Join(notHealthy, ", "))
+		return condition, nil
+	}
+
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = k8s.PodReadinessGateReasonTargetHealthy
+	return condition, nil
+}
+
+// podTargetGroupNames returns the names of the target groups the pod is registered to, from the EndpointSlices
+// listing the pod
+func (r *PodReconciler) podTargetGroupNames(ctx context.Context, pod *corev1.Pod) []string {
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, endpointSlices, client.InNamespace(pod.Namespace)); err != nil {
+		glog.V(6).Infof("Failed to list EndpointSlices of pod %s-%s, err %v\n", pod.Name, pod.Namespace, err)
+		return nil
+	}
+
+	var tgNames []string
+	for _, endpointSlice := range endpointSlices.Items {
+		svcName, ok := endpointSlice.Labels[discoveryv1.LabelServiceName]
+		if !ok || !isPodInEndpointSlice(pod, endpointSlice) {
+			continue
+		}
+
+		svc := &corev1.Service{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: svcName}, svc); err != nil {
+			continue
+		}
+
+		// target groups of all ports, and of the service ports served by the slice
+		tgNames = append(tgNames, latticestore.TargetGroupName(svc.Name, svc.Namespace))
+		for _, port := range endpointSlice.Ports {
+			for _, svcPort := range svc.Spec.Ports {
+				if svcPort.Name == aws.StringValue(port.Name) {
+					tgNames = append(tgNames, latticestore.TargetGroupPortName(svc.Name, svc.Namespace, svcPort.Port))
+				}
+			}
+		}
+	}
+	return tgNames
+}
+
+func isPodInEndpointSlice(pod *corev1.Pod, endpointSlice discoveryv1.EndpointSlice) bool {
+	for _, endpoint := range endpointSlice.Endpoints {
+		if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" && endpoint.TargetRef.UID == pod.UID {
+			return true
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
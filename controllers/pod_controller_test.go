@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+)
+
+func Test_PodReconcile(t *testing.T) {
+	podIP := "10.0.0.1"
+	tgID := "tg-id"
+	targetPort := int32(8080)
+
+	tests := []struct {
+		name          string
+		podExists     bool
+		tgInDatastore bool
+		targets       []*vpclattice.TargetSummary
+		wantResult    ctrl.Result
+		wantStatus    corev1.ConditionStatus
+		wantReason    string
+	}{
+		{
+			name:       "pod not found",
+			wantResult: ctrl.Result{},
+		},
+		{
+			name:       "pod not backing a target group",
+			podExists:  true,
+			wantResult: ctrl.Result{RequeueAfter: podReadinessGatePollInterval},
+			wantStatus: corev1.ConditionFalse,
+			wantReason: k8s.PodReadinessGateReasonTargetNotFound,
+		},
+		{
+			name:          "pod not registered",
+			podExists:     true,
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.2"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+			},
+			wantResult: ctrl.Result{RequeueAfter: podReadinessGatePollInterval},
+			wantStatus: corev1.ConditionFalse,
+			wantReason: k8s.PodReadinessGateReasonTargetNotHealthy,
+		},
+		{
+			name:          "pod target unhealthy",
+			podExists:     true,
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String(podIP), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusInitial)},
+			},
+			wantResult: ctrl.Result{RequeueAfter: podReadinessGatePollInterval},
+			wantStatus: corev1.ConditionFalse,
+			wantReason: k8s.PodReadinessGateReasonTargetNotHealthy,
+		},
+		{
+			name:          "pod target without health checks",
+			podExists:     true,
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String(podIP), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusUnavailable)},
+			},
+			wantResult: ctrl.Result{},
+			wantStatus: corev1.ConditionTrue,
+			wantReason: k8s.PodReadinessGateReasonTargetHealthy,
+		},
+		{
+			name:          "pod target healthy",
+			podExists:     true,
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String(podIP), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+			},
+			wantResult: ctrl.Result{},
+			wantStatus: corev1.ConditionTrue,
+			wantReason: k8s.PodReadinessGateReasonTargetHealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns1",
+					Name:      "pod1",
+					UID:       "pod1-uid",
+				},
+				Spec: corev1.PodSpec{
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: k8s.PodReadinessGateConditionType}},
+				},
+				Status: corev1.PodStatus{
+					PodIP:      podIP,
+					Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+				},
+			}
+			if tt.podExists {
+				assert.NoError(t, k8sClient.Create(ctx, pod.DeepCopy()))
+			}
+			assert.NoError(t, k8sClient.Create(ctx, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc1"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}))
+			assert.NoError(t, k8sClient.Create(ctx, &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns1",
+					Name:      "svc1-1",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "svc1"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses: []string{podIP},
+						TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod.Name, UID: pod.UID},
+					},
+				},
+				Ports: []discoveryv1.EndpointPort{{Name: aws.String("http"), Port: &targetPort}},
+			}))
+
+			ds := latticestore.NewLatticeDataStore()
+			mockTargetsManager := lattice.NewMockTargetsManager(c)
+			if tt.tgInDatastore {
+				tgName := latticestore.TargetGroupPortName("svc1", "ns1", 80)
+				ds.AddTargetGroup(tgName, "vpc-id", "tg-arn", tgID, false, "route1")
+				mockTargetsManager.EXPECT().List(ctx, tgID).Return(tt.targets, nil)
+			}
+
+			r := &PodReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sSchema,
+				latticeDataStore: ds,
+				targetHealth: &TargetHealthCollector{
+					latticeDataStore: ds,
+					targetsManager:   mockTargetsManager,
+				},
+			}
+
+			podName := types.NamespacedName{Namespace: "ns1", Name: "pod1"}
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: podName})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantResult, result)
+
+			if !tt.podExists {
+				return
+			}
+			got := &corev1.Pod{}
+			assert.NoError(t, k8sClient.Get(ctx, podName, got))
+			condition := k8s.GetPodCondition(got, k8s.PodReadinessGateConditionType)
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, tt.wantStatus, condition.Status)
+				assert.Equal(t, tt.wantReason, condition.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+// updateRouteParentStatus writes the Accepted and ResolvedRefs conditions of every lattice gateway parent of
+// a route, it is shared by all route reconcilers. buildErr is the model build error of the route, if any
+func updateRouteParentStatus(ctx context.Context, k8sClient client.Client, eventRecorder record.EventRecorder,
+	obj client.Object, dns string, buildErr error) error {
+	route, ok := k8s.NewRouteInfo(obj)
+	routeStatus := k8s.GetRouteStatus(obj)
+	if !ok || routeStatus == nil {
+		return fmt.Errorf("unsupported route %v", obj)
+	}
+
+	objOld := obj.DeepCopyObject().(client.Object)
+
+	routeStatus.Parents = buildRouteParentStatuses(ctx, k8sClient, route, obj.GetGeneration(), dns, buildErr,
+		routeStatus.Parents)
+	recordNotAllowedByListeners(eventRecorder, obj, routeStatus.Parents)
+
+	if err := k8sClient.Status().Patch(ctx, obj, client.MergeFrom(objOld)); err != nil {
+		glog.V(2).Infof("updateRouteParentStatus: Patch() received err %v \n", err)
+		return errors.Wrapf(err, "failed to update %s status", strings.ToLower(route.Kind))
+	}
+
+	return nil
+}
+
+// buildRouteParentStatuses returns one RouteParentStatus per lattice gateway parent of the route, each parent
+// is accepted or rejected independently. Parent statuses written by other controllers are kept as they are
+func buildRouteParentStatuses(ctx context.Context, k8sClient client.Client, route k8s.RouteInfo, generation int64,
+	dns string, buildErr error, current []gateway_api.RouteParentStatus) []gateway_api.RouteParentStatus {
+
+	var statuses []gateway_api.RouteParentStatus
+	for _, status := range current {
+		if status.ControllerName != config.LatticeGatewayControllerName {
+			statuses = append(statuses, status)
+		}
+	}
+
+	resolvedRefs := resolveBackendRefs(ctx, k8sClient, route)
+	resolvedRefs.ObservedGeneration = generation
+
+	for _, parent := range k8s.ValidateRouteParents(ctx, k8sClient, route) {
+		status := gateway_api.RouteParentStatus{
+			ParentRef:      parent.ParentRef,
+			ControllerName: config.LatticeGatewayControllerName,
+		}
+		if previous := findRouteParentStatus(current, parent.ParentRef); previous != nil {
+			status.Conditions = previous.Conditions
+		}
+
+		accepted := metav1.Condition{
+			Type:               string(gateway_api.RouteConditionAccepted),
+			ObservedGeneration: generation,
+		}
+		switch {
+		case !parent.Accepted:
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = string(parent.Reason)
+			accepted.Message = parent.Message
+		case buildErr != nil:
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = string(routeAcceptedReason(buildErr))
+			accepted.Message = fmt.Sprintf("Failed build model due to %v", buildErr)
+		default:
+			accepted.Status = metav1.ConditionTrue
+			accepted.Reason = string(gateway_api.RouteReasonAccepted)
+			accepted.Message = fmt.Sprintf("DNS Name: %s", dns)
+		}
+
+		// LastTransitionTime is only bumped when the status of a condition flips
+		meta.SetStatusCondition(&status.Conditions, accepted)
+		meta.SetStatusCondition(&status.Conditions, resolvedRefs)
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// recordNotAllowedByListeners emits an event for every lattice gateway parent whose listeners do not allow the
+// route, e.g. a route in another namespace than the gateway, since such a route is silently not attached otherwise
+func recordNotAllowedByListeners(eventRecorder record.EventRecorder, obj client.Object,
+	statuses []gateway_api.RouteParentStatus) {
+	for _, status := range statuses {
+		if status.ControllerName != config.LatticeGatewayControllerName {
+			continue
+		}
+		accepted := meta.FindStatusCondition(status.Conditions, string(gateway_api.RouteConditionAccepted))
+		if accepted == nil || accepted.Reason != string(gateway_api.RouteReasonNotAllowedByListeners) {
+			continue
+		}
+		eventRecorder.Event(obj, corev1.EventTypeWarning, k8s.RouteEventReasonNotAllowedByListeners,
+			fmt.Sprintf("Parent %s: %s", status.ParentRef.Name, accepted.Message))
+	}
+}
+
+// routeAcceptedReason returns the reason of the Accepted condition of a route which failed to build. Errors
+// which are not caused by the route spec are pending, the build is retried
+func routeAcceptedReason(buildErr error) gateway_api.RouteConditionReason {
+	var conflictErr *k8s.RouteConflictError
+	if errors.As(buildErr, &conflictErr) {
+		return k8s.RouteReasonConflicted
+	}
+	if apierrors.IsNotFound(buildErr) {
+		return gateway_api.RouteReasonBackendNotFound
+	}
+
+	cause := buildErr
+	for errors.Unwrap(cause) != nil {
+		cause = errors.Unwrap(cause)
+	}
+
+	switch cause.Error() {
+	case gateway.LATTICE_NO_PARENT_LISTENER:
+		return gateway_api.RouteReasonNotAllowedByListeners
+	case gateway.LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES:
+		return k8s.RouteReasonUnsupportedHostnames
+	case gateway.LATTICE_UNSUPPORTED_MATCH_TYPE,
+		gateway.LATTICE_UNSUPPORTED_HEADER_MATCH_TYPE,
+		gateway.LATTICE_UNSUPPORTED_PATH_MATCH_TYPE,
+		gateway.LATTICE_UNSUPPORTED_FILTER,
+		gateway.LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH,
+		gateway.LATTICE_UNSUPPORTED_MULTIPLE_TLS_RULES,
+		gateway.LATTICE_UNSUPPORTED_LAMBDA_BACKEND,
+		gateway.LATTICE_INVALID_FIXED_RESPONSE,
+		gateway.LATTICE_CONFLICTING_LISTENER_PROTOCOLS,
+		gateway.LATTICE_CONFLICTING_CERTIFICATES,
+		gateway.LATTICE_EXCEED_MAX_HEADER_MATCHES,
+		gateway.LATTICE_EXCEED_MAX_RULES:
+		return gateway_api.RouteReasonUnsupportedValue
+	}
+	return k8s.RouteReasonPending
+}
+
+// resolveBackendRefs returns the ResolvedRefs condition of the route, which is false when any backendRef
+// is of a kind other than Service, ServiceImport and LambdaTarget, refers to another namespace without a
+// ReferenceGrant, or refers to an object which does not exist. LambdaTargets are only supported by HTTPRoutes
+// and must have a valid function ARN, ServiceImports must not be resolved without exported target group
+func resolveBackendRefs(ctx context.Context, k8sClient client.Client, route k8s.RouteInfo) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   string(gateway_api.RouteConditionResolvedRefs),
+		Status: metav1.ConditionTrue,
+		Reason: string(gateway_api.RouteReasonResolvedRefs),
+	}
+
+	for _, backendRef := range route.BackendRefs {
+		kind := "Service"
+		if backendRef.Kind != nil {
+			kind = string(*backendRef.Kind)
+		}
+		name := types.NamespacedName{
+			Namespace: route.Namespace,
+			Name:      string(backendRef.Name),
+		}
+		if backendRef.Namespace != nil {
+			name.Namespace = string(*backendRef.Namespace)
+		}
+
+		var obj client.Object
+		switch {
+		case k8s.IsLambdaTargetBackendRef(backendRef.BackendObjectReference):
+			if route.Kind != k8s.HTTPRouteKind {
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = string(gateway_api.RouteReasonInvalidKind)
+				condition.Message = fmt.Sprintf("%s backendRef %s is only supported by %s", kind, name, k8s.HTTPRouteKind)
+				return condition
+			}
+			obj = &anv1alpha1.LambdaTarget{}
+		case kind == "Service":
+			obj = &corev1.Service{}
+		case kind == "ServiceImport":
+			obj = &mcs_api.ServiceImport{}
+		default:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(gateway_api.RouteReasonInvalidKind)
+			condition.Message = fmt.Sprintf("Unsupported backendRef kind %s of %s", kind, name)
+			return condition
+		}
+
+		if !k8s.IsBackendRefPermitted(ctx, k8sClient, route.Kind, route.Namespace, backendRef.BackendObjectReference) {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(gateway_api.RouteReasonRefNotPermitted)
+			condition.Message = fmt.Sprintf("%s %s is not permitted by any ReferenceGrant", kind, name)
+			return condition
+		}
+
+		if err := k8sClient.Get(ctx, name, obj); err != nil {
+			glog.V(6).Infof("%s %s-%s backendRef %s %s not found, err %v\n",
+				route.Kind, route.Name, route.Namespace, kind, name, err)
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(gateway_api.RouteReasonBackendNotFound)
+			condition.Message = fmt.Sprintf("%s %s not found", kind, name)
+			return condition
+		}
+
+		if serviceImport, ok := obj.(*mcs_api.ServiceImport); ok && k8s.IsServiceImportNotReady(serviceImport) {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(gateway_api.RouteReasonBackendNotFound)
+			condition.Message = fmt.Sprintf("%s %s has no exported target group", kind, name)
+			return condition
+		}
+
+		if lambdaTarget, ok := obj.(*anv1alpha1.LambdaTarget); ok && !k8s.IsValidLambdaFunctionARN(lambdaTarget.Spec.FunctionARN) {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(gateway_api.RouteReasonBackendNotFound)
+			condition.Message = fmt.Sprintf("%s %s has invalid function ARN %s", kind, name, lambdaTarget.Spec.FunctionARN)
+			return condition
+		}
+	}
+
+	return condition
+}
+
+func findRouteParentStatus(statuses []gateway_api.RouteParentStatus, parentRef gateway_api.ParentReference) *gateway_api.RouteParentStatus {
+	for i := range statuses {
+		if statuses[i].ControllerName == config.LatticeGatewayControllerName &&
+			isSameParentRef(statuses[i].ParentRef, parentRef) {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func isSameParentRef(a, b gateway_api.ParentReference) bool {
+	return a.Name == b.Name &&
+		stringPtrValue((*string)(a.Namespace)) == stringPtrValue((*string)(b.Namespace)) &&
+		stringPtrValue((*string)(a.SectionName)) == stringPtrValue((*string)(b.SectionName)) &&
+		stringPtrValue((*string)(a.Kind)) == stringPtrValue((*string)(b.Kind)) &&
+		stringPtrValue((*string)(a.Group)) == stringPtrValue((*string)(b.Group))
+}
+
+func stringPtrValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
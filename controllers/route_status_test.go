@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+func Test_routeAcceptedReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		buildErr   error
+		wantReason gateway_api.RouteConditionReason
+	}{
+		{
+			name:       "conflicting route",
+			buildErr:   &k8s.RouteConflictError{Kind: k8s.GRPCRouteKind, Name: "route1", Namespace: "default"},
+			wantReason: k8s.RouteReasonConflicted,
+		},
+		{
+			name: "backend not found",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, "service1")),
+			wantReason: gateway_api.RouteReasonBackendNotFound,
+		},
+		{
+			name: "no listener allows the route",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				errors.New(gateway.LATTICE_NO_PARENT_LISTENER)),
+			wantReason: gateway_api.RouteReasonNotAllowedByListeners,
+		},
+		{
+			name: "unsupported match",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				errors.New(gateway.LATTICE_UNSUPPORTED_HEADER_MATCH_TYPE)),
+			wantReason: gateway_api.RouteReasonUnsupportedValue,
+		},
+		{
+			name: "parent listeners with different protocols on one port",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				errors.New(gateway.LATTICE_CONFLICTING_LISTENER_PROTOCOLS)),
+			wantReason: gateway_api.RouteReasonUnsupportedValue,
+		},
+		{
+			name: "parent listeners with different certificates",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				errors.New(gateway.LATTICE_CONFLICTING_CERTIFICATES)),
+			wantReason: gateway_api.RouteReasonUnsupportedValue,
+		},
+		{
+			name:       "tlsroute with several hostnames",
+			buildErr:   errors.New(gateway.LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES),
+			wantReason: k8s.RouteReasonUnsupportedHostnames,
+		},
+		{
+			name: "too many rules",
+			buildErr: fmt.Errorf("failed to build grpcroute route1-default: %w",
+				errors.New(gateway.LATTICE_EXCEED_MAX_RULES)),
+			wantReason: gateway_api.RouteReasonUnsupportedValue,
+		},
+		{
+			name: "transient error is retried",
+			buildErr: fmt.Errorf("failed to build httproute route1-default: %w",
+				errors.New("target group not found in datastore")),
+			wantReason: k8s.RouteReasonPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantReason, routeAcceptedReason(tt.buildErr))
+		})
+	}
+}
+
+// a route in another namespace than its gateway is not attached by default, which is reported as an event too
+func Test_updateRouteParentStatus_NotAllowedByListeners(t *testing.T) {
+	var fromAll = gateway_api.NamespacesFromAll
+	var infraNamespace = gateway_api.Namespace("infra")
+
+	tests := []struct {
+		name          string
+		allowedRoutes *gateway_api.AllowedRoutes
+		wantEvent     bool
+	}{
+		{
+			name:      "listener allows routes from the same namespace by default",
+			wantEvent: true,
+		},
+		{
+			name: "listener allows routes from all namespaces",
+			allowedRoutes: &gateway_api.AllowedRoutes{
+				Namespaces: &gateway_api.RouteNamespaces{From: &fromAll},
+			},
+			wantEvent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			gateway_api.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			assert.NoError(t, k8sClient.Create(ctx, &gateway_api.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "amazon-vpc-lattice"},
+				Spec:       gateway_api.GatewayClassSpec{ControllerName: config.LatticeGatewayControllerName},
+			}))
+			assert.NoError(t, k8sClient.Create(ctx, &gateway_api.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw1", Namespace: string(infraNamespace)},
+				Spec: gateway_api.GatewaySpec{
+					GatewayClassName: "amazon-vpc-lattice",
+					Listeners: []gateway_api.Listener{
+						{
+							Name:          "http",
+							Port:          80,
+							Protocol:      gateway_api.HTTPProtocolType,
+							AllowedRoutes: tt.allowedRoutes,
+						},
+					},
+				},
+			}))
+			route := &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "default"},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{{Name: "gw1", Namespace: &infraNamespace}},
+					},
+				},
+			}
+			assert.NoError(t, k8sClient.Create(ctx, route))
+
+			eventRecorder := record.NewFakeRecorder(10)
+			assert.NoError(t, updateRouteParentStatus(ctx, k8sClient, eventRecorder, route, "", nil))
+
+			if tt.wantEvent {
+				if assert.Len(t, eventRecorder.Events, 1) {
+					assert.Contains(t, <-eventRecorder.Events, k8s.RouteEventReasonNotAllowedByListeners)
+				}
+			} else {
+				assert.Len(t, eventRecorder.Events, 0)
+			}
+		})
+	}
+}
@@ -18,32 +18,43 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/golang/glog"
 
+	aws_sdk "github.com/aws/aws-sdk-go/aws"
+
 	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	"github.com/aws/aws-application-networking-k8s/pkg/aws"
 	"github.com/aws/aws-application-networking-k8s/pkg/deploy"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
 	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	lattice_runtime "github.com/aws/aws-application-networking-k8s/pkg/runtime"
 )
 
 const (
 	// Typo
 	serviceFinalizer = "service.ki8s.aws/resources"
+	// targetsDrainingRequeueInterval is how often the targets are checked again while stale targets are draining
+	targetsDrainingRequeueInterval = 30 * time.Second
 )
 
 // ServiceReconciler reconciles a Service object
@@ -82,8 +93,7 @@ func NewServiceReconciler(client client.Client, scheme *runtime.Scheme, eventRec
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=services/finalizers,verbs=update
-//+kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=core,resources=endpoints/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=configmaps, verbs=create;delete;patch;update;get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -109,13 +119,15 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if !svc.DeletionTimestamp.IsZero() {
-		tgNameD := latticestore.TargetGroupName(svc.Name, svc.Namespace)
-		TGDeleted := ds.GetTargetGroupsByTG(tgNameD)
-		for _, tg := range TGDeleted {
-			glog.V(6).Infof("service deletion trigger target IP list registration %v and tg %v\n",
-				tgNameD, tg)
-			r.reconcileTargetsResource(ctx, svc, tg.TargetGroupKey.RouteName)
-
+		for _, port := range servicePorts(svc) {
+			tgNameD := latticestore.TargetGroupPortName(svc.Name, svc.Namespace, port)
+			TGDeleted := ds.GetTargetGroupsByTG(tgNameD)
+			for _, tg := range TGDeleted {
+				glog.V(6).Infof("service deletion trigger target IP list registration %v and tg %v\n",
+					tgNameD, tg)
+				r.reconcileTargetsResource(ctx, svc, tg.TargetGroupKey.RouteName, port)
+
+			}
 		}
 		r.finalizerManager.RemoveFinalizers(ctx, svc, serviceFinalizer)
 
@@ -123,31 +135,57 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	// TODO also need to check serviceexport object to trigger building TargetGroup
-	tgName := latticestore.TargetGroupName(svc.Name, svc.Namespace)
-	TGs := ds.GetTargetGroupsByTG(tgName) // isServiceImport = false
-	for _, tg := range TGs {
-
-		glog.V(6).Infof("endpoints change trigger target IP list registration %v and tg %v\n",
-			tgName, tg)
+	var reconcileErr error
+	pending := false
+	for _, port := range servicePorts(svc) {
+		tgName := latticestore.TargetGroupPortName(svc.Name, svc.Namespace, port)
+		TGs := ds.GetTargetGroupsByTG(tgName) // isServiceImport = false
+		for _, tg := range TGs {
+
+			glog.V(6).Infof("endpoints change trigger target IP list registration %v and tg %v\n",
+				tgName, tg)
+
+			tgPending, err := r.reconcileTargetsResource(ctx, svc, tg.TargetGroupKey.RouteName, port)
+			if err != nil {
+				reconcileErr = err
+			}
+			pending = pending || tgPending
 
-		r.reconcileTargetsResource(ctx, svc, tg.TargetGroupKey.RouteName)
+		}
+	}
 
+	if reconcileErr == nil && pending {
+		// check again until the deregistered targets finished draining
+		return ctrl.Result{RequeueAfter: targetsDrainingRequeueInterval}, nil
 	}
+	return lattice_runtime.HandleReconcileError(reconcileErr)
+}
 
-	return ctrl.Result{}, nil
+// servicePorts returns the ports of the target groups of the service, 0 is the target group of all ports
+func servicePorts(svc *corev1.Service) []int32 {
+	ports := []int32{0}
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, port.Port)
+	}
+	return ports
 }
 
-func (r *ServiceReconciler) reconcileTargetsResource(ctx context.Context, svc *corev1.Service, routename string) {
+// reconcileTargetsResource returns whether targets of the target group are still pending, e.g. draining
+func (r *ServiceReconciler) reconcileTargetsResource(ctx context.Context, svc *corev1.Service, routename string, port int32) (bool, error) {
 	if err := r.finalizerManager.AddFinalizers(ctx, svc, serviceFinalizer); err != nil {
 		r.eventRecorder.Event(svc, corev1.EventTypeWarning, k8s.ServiceEventReasonFailedAddFinalizer, fmt.Sprintf("Failed and finalizer due %v", err))
 	}
 
-	r.buildAndDeployModel(ctx, svc, routename)
+	_, latticeTargets, err := r.buildAndDeployModel(ctx, svc, routename, port)
+	if err != nil {
+		return false, err
+	}
+	return latticeTargets != nil && latticeTargets.Status != nil && latticeTargets.Status.PendingTargets > 0, nil
 }
 
-func (r *ServiceReconciler) buildAndDeployModel(ctx context.Context, svc *corev1.Service, routename string) (core.Stack, *latticemodel.Targets, error) {
+func (r *ServiceReconciler) buildAndDeployModel(ctx context.Context, svc *corev1.Service, routename string, port int32) (core.Stack, *latticemodel.Targets, error) {
 	svcLog := log.FromContext(ctx)
-	stack, latticeTargets, err := r.modelBuilder.Build(ctx, svc, routename)
+	stack, latticeTargets, err := r.modelBuilder.Build(ctx, svc, routename, port)
 
 	if err != nil {
 		r.eventRecorder.Event(svc, corev1.EventTypeWarning,
@@ -161,6 +199,7 @@ func (r *ServiceReconciler) buildAndDeployModel(ctx context.Context, svc *corev1
 	if err := r.stackDeployer.Deploy(ctx, stack); err != nil {
 		r.eventRecorder.Event(svc, corev1.EventTypeWarning,
 			k8s.ServiceEventReasonFailedDeployModel, fmt.Sprintf("Failed deploy mode due to %v", err))
+		r.recordTargetsFailedEvents(svc, err)
 		return nil, nil, err
 	}
 
@@ -168,17 +207,39 @@ func (r *ServiceReconciler) buildAndDeployModel(ctx context.Context, svc *corev1
 	return stack, latticeTargets, err
 }
 
+// recordTargetsFailedEvents records an event per target lattice failed to register or deregister
+func (r *ServiceReconciler) recordTargetsFailedEvents(svc *corev1.Service, err error) {
+	var targetsErr *lattice.TargetsFailedError
+	if !errors.As(err, &targetsErr) {
+		return
+	}
+
+	for _, failure := range targetsErr.RegisterFailures {
+		r.eventRecorder.Event(svc, corev1.EventTypeWarning, k8s.ServiceEventReasonFailedRegisterTarget,
+			fmt.Sprintf("Failed to register target %s:%d, %s: %s", aws_sdk.StringValue(failure.Id),
+				aws_sdk.Int64Value(failure.Port), aws_sdk.StringValue(failure.FailureCode), aws_sdk.StringValue(failure.FailureMessage)))
+	}
+	for _, failure := range targetsErr.DeregisterFailures {
+		r.eventRecorder.Event(svc, corev1.EventTypeWarning, k8s.ServiceEventReasonFailedDeregisterTarget,
+			fmt.Sprintf("Failed to deregister target %s:%d, %s: %s", aws_sdk.StringValue(failure.Id),
+				aws_sdk.Int64Value(failure.Port), aws_sdk.StringValue(failure.FailureCode), aws_sdk.StringValue(failure.FailureMessage)))
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	//TODO handle endslices
-	epsEventsHandler := eventhandlers.NewEnqueueRequestEndpointEvent(r.Client)
+	epsEventsHandler := eventhandlers.NewEnqueueRequestEndpointSliceEvent(r.Client)
 	httpRouteEventHandler := eventhandlers.NewEnqueueRequestHTTPRouteEvent(r.Client)
 	serviceExportHandler := eventhandlers.NewEqueueRequestServiceExportEvent(r.Client)
+	podEventHandler := eventhandlers.NewEnqueueRequestPodEvent(r.Client)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Service{}).
-		Watches(&source.Kind{Type: &corev1.Endpoints{}}, epsEventsHandler).
+		Watches(&source.Kind{Type: &discoveryv1.EndpointSlice{}}, epsEventsHandler).
 		Watches(&source.Kind{Type: &gateway_api.HTTPRoute{}}, httpRouteEventHandler).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.GRPCRoute{}}, httpRouteEventHandler).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.TLSRoute{}}, httpRouteEventHandler).
 		Watches(&source.Kind{Type: &mcs_api.ServiceExport{}}, serviceExportHandler).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, podEventHandler).
 		Complete(r)
 }
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+const (
+	serviceImportDiscoveryInterval = time.Minute
+)
+
+// ServiceImportDiscovery periodically creates a ServiceImport for each service exported by the clusters of the vpcs
+// associated with the service networks of the gateways of this cluster. By namespace sameness, the ServiceImport
+// has the name and namespace of the exported service. Discovered ServiceImports are updated with the exported
+// ports, and deleted once the service is no longer exported
+type ServiceImportDiscovery struct {
+	client                client.Client
+	serviceNetworkManager lattice.ServiceNetworkManager
+	// exportedTGCache lists the exported target groups, shared with the ServiceImport reconciler
+	exportedTGCache *ExportedTargetGroupCache
+}
+
+func NewServiceImportDiscovery(client client.Client, cloud lattice_aws.Cloud, exportedTGCache *ExportedTargetGroupCache) *ServiceImportDiscovery {
+	return &ServiceImportDiscovery{
+		client:                client,
+		serviceNetworkManager: lattice.NewDefaultServiceNetworkManager(cloud),
+		exportedTGCache:       exportedTGCache,
+	}
+}
+
+// Start discovers the ServiceImports until the context is done, it implements manager.Runnable
+func (d *ServiceImportDiscovery) Start(ctx context.Context) error {
+	ticker := time.NewTicker(serviceImportDiscoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.discover(ctx)
+		}
+	}
+}
+
+func (d *ServiceImportDiscovery) discover(ctx context.Context) {
+	exports, err := d.listExports(ctx)
+	if err != nil {
+		// without the complete list of exports, discovered ServiceImports cannot be garbage collected safely
+		glog.V(2).Infof("ServiceImportDiscovery: failed to list exported target groups, err %v\n", err)
+		return
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := d.client.List(ctx, nsList); err != nil {
+		glog.V(2).Infof("ServiceImportDiscovery: failed to list namespaces, err %v\n", err)
+		return
+	}
+	namespaces := make(map[string]bool)
+	for _, ns := range nsList.Items {
+		if ns.DeletionTimestamp.IsZero() {
+			namespaces[ns.Name] = true
+		}
+	}
+
+	for name, exportedTGs := range exports {
+		if !namespaces[name.Namespace] {
+			// namespaces are not created for imports, the namespace of the same name is the same namespace
+			glog.V(6).Infof("ServiceImportDiscovery: ignore export %s, namespace does not exist\n", name)
+			continue
+		}
+		d.ensureServiceImport(ctx, name, exportedTGs)
+	}
+
+	d.deleteStaleServiceImports(ctx, exports)
+}
+
+// listExports returns the active exported target groups of the vpcs associated with the service networks of the
+// gateways of this cluster, by the name of the exported service. Services of namespaces which are not in
+// SERVICE_IMPORT_DISCOVERY_NAMESPACES are left out
+func (d *ServiceImportDiscovery) listExports(ctx context.Context) (map[types.NamespacedName][]lattice.ExportedTargetGroup, error) {
+	serviceNetworks, err := d.listServiceNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcs := make(map[string]bool)
+	if len(serviceNetworks) > 0 {
+		vpcIDs, err := d.serviceNetworkManager.ListVpcs(ctx, serviceNetworks)
+		if err != nil {
+			return nil, err
+		}
+		for _, vpcID := range vpcIDs {
+			vpcs[vpcID] = true
+		}
+	}
+
+	exports := make(map[types.NamespacedName][]lattice.ExportedTargetGroup)
+	if len(vpcs) == 0 {
+		return exports, nil
+	}
+
+	exportedTGs, err := d.exportedTGCache.ListAllExported()
+	if err != nil {
+		return nil, err
+	}
+	for _, tg := range exportedTGs {
+		if tg.Status != vpclattice.TargetGroupStatusActive || !vpcs[tg.VpcID] {
+			continue
+		}
+		name := types.NamespacedName{
+			Namespace: stringPtrValue(tg.Tags[latticemodel.K8SServiceNamespaceKey]),
+			Name:      stringPtrValue(tg.Tags[latticemodel.K8SServiceNameKey]),
+		}
+		if name.Name == "" || name.Namespace == "" || !config.IsServiceImportDiscoveryNamespace(name.Namespace) {
+			continue
+		}
+		exports[name] = append(exports[name], tg)
+	}
+	glog.V(6).Infof("ServiceImportDiscovery: service networks %v, vpcs %v, exports %v\n", serviceNetworks, vpcs, exports)
+	return exports, nil
+}
+
+// listServiceNetworks returns the service networks of the lattice gateways of this cluster, which have the name
+// of the gateway
+func (d *ServiceImportDiscovery) listServiceNetworks(ctx context.Context) ([]string, error) {
+	gwList := &gateway_api.GatewayList{}
+	if err := d.client.List(ctx, gwList); err != nil {
+		return nil, err
+	}
+
+	var serviceNetworks []string
+	for _, gw := range gwList.Items {
+		if !gw.DeletionTimestamp.IsZero() {
+			continue
+		}
+		gwClass := &gateway_api.GatewayClass{}
+		if err := d.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gwClass); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			// a service network missing from the list would garbage collect its ServiceImports
+			return nil, err
+		}
+		if gwClass.Spec.ControllerName != config.LatticeGatewayControllerName {
+			continue
+		}
+		serviceNetworks = append(serviceNetworks, gw.Name)
+	}
+	return serviceNetworks, nil
+}
+
+// ensureServiceImport creates the ServiceImport of the exported service, or updates the ports of a discovered one.
+// A ServiceImport which was not discovered, e.g. written by hand, is left alone
+func (d *ServiceImportDiscovery) ensureServiceImport(ctx context.Context, name types.NamespacedName, exportedTGs []lattice.ExportedTargetGroup) {
+	serviceImport := &mcs_api.ServiceImport{}
+	err := d.client.Get(ctx, name, serviceImport)
+	if apierrors.IsNotFound(err) {
+		ports, _ := buildServiceImportPorts(nil, exportedTGs)
+		if ports == nil {
+			ports = []mcs_api.ServicePort{}
+		}
+		serviceImport = &mcs_api.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name.Name,
+				Namespace: name.Namespace,
+				Labels: map[string]string{
+					k8s.ServiceImportDiscoveredLabel: "true",
+				},
+			},
+			Spec: mcs_api.ServiceImportSpec{
+				Type:  mcs_api.ClusterSetIP,
+				Ports: ports,
+			},
+		}
+		if err := d.client.Create(ctx, serviceImport); err != nil {
+			glog.V(2).Infof("ServiceImportDiscovery: failed to create serviceimport %s, err %v\n", name, err)
+			return
+		}
+		glog.V(2).Infof("ServiceImportDiscovery: created serviceimport %s\n", name)
+		return
+	}
+	if err != nil {
+		glog.V(2).Infof("ServiceImportDiscovery: failed to get serviceimport %s, err %v\n", name, err)
+		return
+	}
+
+	if !k8s.IsServiceImportDiscovered(serviceImport) || !serviceImport.DeletionTimestamp.IsZero() {
+		return
+	}
+	ports, ok := buildServiceImportPorts(serviceImport.Spec.Ports, exportedTGs)
+	if !ok || equality.Semantic.DeepEqual(ports, serviceImport.Spec.Ports) {
+		return
+	}
+
+	serviceImportOld := serviceImport.DeepCopy()
+	serviceImport.Spec.Ports = ports
+	if err := d.client.Patch(ctx, serviceImport, client.MergeFrom(serviceImportOld)); err != nil {
+		glog.V(2).Infof("ServiceImportDiscovery: failed to update serviceimport %s, err %v\n", name, err)
+	}
+}
+
+// deleteStaleServiceImports deletes the discovered ServiceImports whose service is no longer exported
+func (d *ServiceImportDiscovery) deleteStaleServiceImports(ctx context.Context, exports map[types.NamespacedName][]lattice.ExportedTargetGroup) {
+	serviceImportList := &mcs_api.ServiceImportList{}
+	if err := d.client.List(ctx, serviceImportList, client.MatchingLabels{k8s.ServiceImportDiscoveredLabel: "true"}); err != nil {
+		glog.V(2).Infof("ServiceImportDiscovery: failed to list serviceimports, err %v\n", err)
+		return
+	}
+
+	for i := range serviceImportList.Items {
+		serviceImport := &serviceImportList.Items[i]
+		if _, ok := exports[k8s.NamespacedName(serviceImport)]; ok || !serviceImport.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := d.client.Delete(ctx, serviceImport); err != nil && !apierrors.IsNotFound(err) {
+			glog.V(2).Infof("ServiceImportDiscovery: failed to delete serviceimport %s-%s, err %v\n",
+				serviceImport.Name, serviceImport.Namespace, err)
+			continue
+		}
+		glog.V(2).Infof("ServiceImportDiscovery: deleted serviceimport %s-%s, no longer exported\n",
+			serviceImport.Name, serviceImport.Namespace)
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	mock_client "github.com/aws/aws-application-networking-k8s/mocks/controller-runtime/client"
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+)
+
+func Test_listServiceNetworks(t *testing.T) {
+	tests := []struct {
+		name       string
+		getErr     error
+		wantSNs    []string
+		wantErrNil bool
+	}{
+		{
+			name:       "lattice gateway",
+			wantSNs:    []string{"gw1"},
+			wantErrNil: true,
+		},
+		{
+			name:       "gatewayclass not found",
+			getErr:     apierrors.NewNotFound(schema.GroupResource{Resource: "gatewayclasses"}, "class1"),
+			wantErrNil: true,
+		},
+		{
+			name:   "gatewayclass get failed",
+			getErr: errors.New("api server unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sClient := mock_client.NewMockClient(c)
+			k8sClient.EXPECT().List(ctx, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					list.(*gateway_api.GatewayList).Items = []gateway_api.Gateway{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "gw1", Namespace: "default"},
+							Spec:       gateway_api.GatewaySpec{GatewayClassName: "class1"},
+						},
+					}
+					return nil
+				})
+			k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if tt.getErr != nil {
+						return tt.getErr
+					}
+					obj.(*gateway_api.GatewayClass).Spec.ControllerName = config.LatticeGatewayControllerName
+					return nil
+				})
+
+			d := &ServiceImportDiscovery{client: k8sClient}
+			serviceNetworks, err := d.listServiceNetworks(ctx)
+
+			if tt.wantErrNil {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantSNs, serviceNetworks)
+			} else {
+				// the discovery cycle is skipped, so no ServiceImport is garbage collected
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
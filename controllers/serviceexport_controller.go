@@ -20,10 +20,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/golang/glog"
 
 	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
+	aws_sdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -32,8 +38,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
 	"github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/deploy"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
 	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
@@ -52,10 +61,15 @@ type ServiceExportReconciler struct {
 	stackDeployer    deploy.StackDeployer
 	latticeDataStore *latticestore.LatticeDataStore
 	stackMashaller   deploy.StackMarshaller
+
+	targetGroupManager lattice.TargetGroupManager
+	targetsManager     lattice.TargetsManager
 }
 
 const (
 	serviceExportFinalizer = "serviceexport.k8s.aws/resources"
+
+	serviceExportNotReadyRequeueInterval = 30 * time.Second
 )
 
 func NewServiceExportReconciler(cloud aws.Cloud, client client.Client, scheme *runtime.Scheme, eventRecorder record.EventRecorder,
@@ -73,6 +87,9 @@ func NewServiceExportReconciler(cloud aws.Cloud, client client.Client, scheme *r
 		eventRecorder:    eventRecorder,
 		latticeDataStore: latticeDataStore,
 		stackMashaller:   stackMarshaller,
+
+		targetGroupManager: lattice.NewTargetGroupManager(cloud),
+		targetsManager:     lattice.NewTargetsManager(cloud, latticeDataStore),
 	}
 }
 
@@ -120,12 +137,181 @@ func (r *ServiceExportReconciler) reconcile(ctx context.Context, req ctrl.Reques
 			return nil
 		}
 
-		return r.reconcileServiceExportResources(ctx, srvExport)
+		if _, err := k8s.ServiceExportWeight(srvExport); err != nil {
+			r.eventRecorder.Event(srvExport, corev1.EventTypeWarning, k8s.ServiceExportEventReasonInvalidWeight,
+				fmt.Sprintf("Using the default weight %d, %v", k8s.DefaultServiceExportWeight, err))
+		}
+
+		_, validStatus, _, _, err := r.serviceExportValidity(ctx, srvExport)
+		if err != nil {
+			return err
+		}
+		var deployErr error
+		if validStatus == corev1.ConditionTrue {
+			deployErr = r.reconcileServiceExportResources(ctx, srvExport)
+		} else {
+			// nothing can be exported, the target group exported until now would keep stale targets
+			deployErr = r.cleanupInvalidServiceExport(ctx, srvExport)
+		}
+		ready, err := r.updateServiceExportStatus(ctx, srvExport)
+		if deployErr != nil {
+			return deployErr
+		}
+		if err != nil {
+			return err
+		}
+		if !ready && validStatus == corev1.ConditionTrue {
+			// an invalid export is reconciled again by the events of its service
+			return lattice_runtime.NewRequeueNeededAfter("ServiceExport not ready", serviceExportNotReadyRequeueInterval)
+		}
+	}
+
+	return nil
+}
+
+// updateServiceExportStatus sets the Valid, Conflict and Ready conditions of the ServiceExport, returns whether it
+// is ready. The export is invalid when the service is missing or headless, conflicts when the clusters of other
+// vpcs export the service with other ports or protocol, and is ready once its target group is active and has
+// registered targets
+func (r *ServiceExportReconciler) updateServiceExportStatus(ctx context.Context, srvExport *mcs_api.ServiceExport) (bool, error) {
+	srvExportOld := srvExport.DeepCopy()
+
+	svc, validStatus, validReason, validMessage, err := r.serviceExportValidity(ctx, srvExport)
+	if err != nil {
+		return false, err
+	}
+	k8s.SetServiceExportCondition(srvExport, mcs_api.ServiceExportValid, validStatus, validReason, validMessage)
+
+	exportedTGs, err := r.targetGroupManager.ListExported(ctx, srvExport.Name, srvExport.Namespace)
+	if err != nil {
+		return false, err
+	}
+	var ownTG *lattice.ExportedTargetGroup
+	for i := range exportedTGs {
+		if exportedTGs[i].VpcID == config.VpcID {
+			ownTG = &exportedTGs[i]
+		}
+	}
+
+	var ports []int32
+	if svc != nil {
+		for _, port := range svc.Spec.Ports {
+			ports = append(ports, port.Port)
+		}
+	}
+	protocol := ""
+	if ownTG != nil {
+		protocol = ownTG.Protocol
+	}
+	conflictStatus, conflictReason, conflictMessage := corev1.ConditionFalse, k8s.ServiceExportReasonNoConflict, ""
+	for _, conflict := range lattice.FindExportConflicts(config.VpcID, ports, protocol, exportedTGs) {
+		conflictStatus = corev1.ConditionTrue
+		if conflict.PortsConflict {
+			conflictReason = k8s.ServiceExportReasonPortsConflict
+			conflictMessage = fmt.Sprintf("Exported with ports %v by the cluster of vpc %s, ports %v here",
+				conflict.Ports, conflict.VpcID, ports)
+		} else {
+			conflictReason = k8s.ServiceExportReasonProtocolConflict
+			conflictMessage = fmt.Sprintf("Exported with protocol %s by the cluster of vpc %s, protocol %s here",
+				conflict.Protocol, conflict.VpcID, protocol)
+		}
+		break
+	}
+	k8s.SetServiceExportCondition(srvExport, mcs_api.ServiceExportConflict, conflictStatus, conflictReason, conflictMessage)
+
+	readyStatus, readyReason, readyMessage := corev1.ConditionFalse, k8s.ServiceExportReasonInvalid, validMessage
+	if validStatus == corev1.ConditionTrue {
+		readyStatus, readyReason, readyMessage, err = r.exportReadiness(ctx, ownTG)
+		if err != nil {
+			return false, err
+		}
+	}
+	k8s.SetServiceExportCondition(srvExport, k8s.ServiceExportConditionReady, readyStatus, readyReason, readyMessage)
+
+	if !equality.Semantic.DeepEqual(srvExportOld.Status, srvExport.Status) {
+		// the conditions are patched as a whole, the optimistic lock keeps the target health collector's condition
+		if err := r.Client.Status().Patch(ctx, srvExport, client.MergeFromWithOptions(srvExportOld, client.MergeFromWithOptimisticLock{})); err != nil {
+			glog.V(2).Infof("Failed to update serviceexport %s-%s status, err %v\n", srvExport.Name, srvExport.Namespace, err)
+			return false, err
+		}
 	}
+	return readyStatus == corev1.ConditionTrue, nil
+}
 
+// serviceExportValidity returns the service of the ServiceExport and its Valid condition, the service is nil
+// when it is not found
+func (r *ServiceExportReconciler) serviceExportValidity(ctx context.Context, srvExport *mcs_api.ServiceExport) (*corev1.Service, corev1.ConditionStatus, string, string, error) {
+	svc := &corev1.Service{}
+	if err := r.Client.Get(ctx, k8s.NamespacedName(srvExport), svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, "", "", "", err
+		}
+		return nil, corev1.ConditionFalse, k8s.ServiceExportReasonServiceNotFound,
+			fmt.Sprintf("Service %s-%s not found", srvExport.Name, srvExport.Namespace), nil
+	}
+	if svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return svc, corev1.ConditionFalse, k8s.ServiceExportReasonServiceHeadless,
+			fmt.Sprintf("Service %s-%s is headless", srvExport.Name, srvExport.Namespace), nil
+	}
+	return svc, corev1.ConditionTrue, k8s.ServiceExportReasonValid, "", nil
+}
+
+// cleanupInvalidServiceExport deletes the exported target group of this cluster, the target groups exported by
+// the clusters of other vpcs are left alone
+func (r *ServiceExportReconciler) cleanupInvalidServiceExport(ctx context.Context, srvExport *mcs_api.ServiceExport) error {
+	tgName := latticestore.TargetGroupName(srvExport.Name, srvExport.Namespace)
+	r.latticeDataStore.SetTargetGroupByServiceExport(tgName, false, false)
+
+	exportedTGs, err := r.targetGroupManager.ListExported(ctx, srvExport.Name, srvExport.Namespace)
+	if err != nil {
+		return err
+	}
+	for _, exportedTG := range exportedTGs {
+		if exportedTG.VpcID != config.VpcID {
+			continue
+		}
+		glog.V(2).Infof("Deleting target group %s of invalid serviceexport %s-%s\n",
+			exportedTG.ID, srvExport.Name, srvExport.Namespace)
+		tg := &latticemodel.TargetGroup{
+			Spec: latticemodel.TargetGroupSpec{
+				Name:      tgName,
+				LatticeID: exportedTG.ID,
+			},
+		}
+		if err := r.targetGroupManager.Delete(ctx, tg); err != nil {
+			return err
+		}
+	}
+
+	// for serviceexport, the routename is ""
+	r.latticeDataStore.DelTargetGroup(tgName, "", false)
 	return nil
 }
 
+// exportReadiness returns the Ready condition of the exported target group of this cluster
+func (r *ServiceExportReconciler) exportReadiness(ctx context.Context, ownTG *lattice.ExportedTargetGroup) (corev1.ConditionStatus, string, string, error) {
+	if ownTG == nil || ownTG.Status != vpclattice.TargetGroupStatusActive {
+		return corev1.ConditionFalse, k8s.ServiceExportReasonTargetGroupNotActive, "Target group is not active yet", nil
+	}
+
+	targets, err := r.targetsManager.List(ctx, ownTG.ID)
+	if err != nil {
+		return "", "", "", err
+	}
+	registered := 0
+	for _, target := range targets {
+		if aws_sdk.StringValue(target.Status) != vpclattice.TargetStatusDraining {
+			registered++
+		}
+	}
+	if registered == 0 {
+		return corev1.ConditionFalse, k8s.ServiceExportReasonNoTargets,
+			fmt.Sprintf("Target group %s has no registered targets", ownTG.Name), nil
+	}
+	return corev1.ConditionTrue, k8s.ServiceExportReasonExported,
+		fmt.Sprintf("Target group %s has %d registered targets", ownTG.Name, registered), nil
+}
+
 func (r *ServiceExportReconciler) cleanupServiceExportResources(ctx context.Context, srvExport *mcs_api.ServiceExport) error {
 	_, _, err := r.buildAndDeployModel(ctx, srvExport)
 	return err
@@ -182,9 +368,11 @@ func (r *ServiceExportReconciler) buildAndDeployModel(ctx context.Context, srvEx
 // TODO need to watch service event too
 func (r *ServiceExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	svcEventsHandler := eventhandlers.NewEqueueRequestServiceEvent(r.Client)
+	tgPolicyEventHandler := eventhandlers.NewEqueueServiceExportRequestTargetGroupPolicyEvent(r.Client)
 	return ctrl.NewControllerManagedBy(mgr).
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		For(&mcs_api.ServiceExport{}).
 		Watches(&source.Kind{Type: &corev1.Service{}}, svcEventsHandler).
+		Watches(&source.Kind{Type: &anv1alpha1.TargetGroupPolicy{}}, tgPolicyEventHandler).
 		Complete(r)
 }
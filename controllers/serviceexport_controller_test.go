@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+// an invalid ServiceExport is not deployed, the target group exported by this cluster is deleted
+func Test_ServiceExportReconcile_Invalid(t *testing.T) {
+	config.VpcID = "vpc-1"
+
+	tests := []struct {
+		name       string
+		svc        *corev1.Service
+		wantReason string
+	}{
+		{
+			name:       "service not found",
+			wantReason: k8s.ServiceExportReasonServiceNotFound,
+		},
+		{
+			name: "headless service",
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "export1"},
+				Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+			},
+			wantReason: k8s.ServiceExportReasonServiceHeadless,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			mcs_api.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			srvExportName := types.NamespacedName{Namespace: "ns1", Name: "export1"}
+			assert.NoError(t, k8sClient.Create(ctx, &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   srvExportName.Namespace,
+					Name:        srvExportName.Name,
+					Annotations: map[string]string{"multicluster.x-k8s.io/federation": "amazon-vpc-lattice"},
+				},
+			}))
+			if tt.svc != nil {
+				assert.NoError(t, k8sClient.Create(ctx, tt.svc))
+			}
+
+			tgName := latticestore.TargetGroupName(srvExportName.Name, srvExportName.Namespace)
+			ds := latticestore.NewLatticeDataStore()
+			ds.AddTargetGroup(tgName, config.VpcID, "tg-arn", "tg-id", false, "")
+
+			mockTGManager := lattice.NewMockTargetGroupManager(c)
+			mockTGManager.EXPECT().ListExported(ctx, srvExportName.Name, srvExportName.Namespace).Return(
+				[]lattice.ExportedTargetGroup{
+					{ID: "tg-id", VpcID: config.VpcID},
+					// exported by the cluster of another vpc
+					{ID: "other-tg-id", VpcID: "vpc-2"},
+				}, nil).AnyTimes()
+			mockTGManager.EXPECT().Delete(ctx, &latticemodel.TargetGroup{
+				Spec: latticemodel.TargetGroupSpec{
+					Name:      tgName,
+					LatticeID: "tg-id",
+				},
+			}).Return(nil)
+
+			// the model is neither built nor deployed
+			r := &ServiceExportReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sSchema,
+				latticeDataStore:   ds,
+				targetGroupManager: mockTGManager,
+			}
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: srvExportName})
+			assert.Nil(t, err)
+			assert.Equal(t, ctrl.Result{}, result)
+
+			_, err = ds.GetTargetGroup(tgName, "", false)
+			assert.NotNil(t, err)
+
+			srvExport := &mcs_api.ServiceExport{}
+			assert.NoError(t, k8sClient.Get(ctx, srvExportName, srvExport))
+			for _, condition := range srvExport.Status.Conditions {
+				if condition.Type == mcs_api.ServiceExportValid {
+					assert.Equal(t, corev1.ConditionFalse, condition.Status)
+					assert.Equal(t, tt.wantReason, *condition.Reason)
+				}
+			}
+		})
+	}
+}
@@ -19,16 +19,26 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
 
-	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
-	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 )
 
 // ServiceImportReconciler reconciles a ServiceImport object
@@ -38,42 +48,41 @@ type ServiceImportReconciler struct {
 	finalizerManager k8s.FinalizerManager
 	eventRecorder    record.EventRecorder
 	latticeDataStore *latticestore.LatticeDataStore
+	exportedTGCache  *ExportedTargetGroupCache
 }
 
 const (
 	serviceImportFinalizer = "serviceimport.k8s.aws/resource"
+
+	// until the exported target groups are listed the first time
+	serviceImportCacheSyncRequeueInterval = 5 * time.Second
 )
 
-func NewServceImportReconciler(client client.Client, scheme *runtime.Scheme, eventRecorder record.EventRecorder,
+func NewServceImportReconciler(exportedTGCache *ExportedTargetGroupCache, client client.Client, scheme *runtime.Scheme, eventRecorder record.EventRecorder,
 	finalizerManager k8s.FinalizerManager, dataStore *latticestore.LatticeDataStore) *ServiceImportReconciler {
-
 	return &ServiceImportReconciler{
 		Client:           client,
 		Scheme:           scheme,
 		finalizerManager: finalizerManager,
 		eventRecorder:    eventRecorder,
 		latticeDataStore: dataStore,
+		exportedTGCache:  exportedTGCache,
 	}
-
 }
 
 //+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports/finalizers,verbs=update
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the ServiceImport object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// Reconcile resolves the ServiceImport to the lattice target groups exported for it, and reflects them in
+// the ports, the clusters status and the ready annotation of the ServiceImport. Routes using the ServiceImport
+// as backend are enqueued by their ServiceImport watch when it changes, e.g. when it becomes resolvable
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	reconcileLog := log.FromContext(ctx)
 
-	// TODO(user): your logic here
 	reconcileLog.Info("ServiceImportReconciler")
 
 	serviceImport := &mcs_api.ServiceImport{}
@@ -97,13 +106,149 @@ func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	reconcileLog.Info("Adding/Updating")
 
+	exportedTGs, err := r.exportedTGCache.ListExported(serviceImport.Name, serviceImport.Namespace)
+	if err != nil {
+		glog.V(6).Infof("ServiceImport %s-%s, %v\n", serviceImport.Name, serviceImport.Namespace, err)
+		return ctrl.Result{RequeueAfter: serviceImportCacheSyncRequeueInterval}, nil
+	}
+
+	// the ServiceImport is enqueued by the cache again once its exported target groups change
+	if _, err := r.updateServiceImport(ctx, serviceImport, activeExportedTGs(serviceImport, exportedTGs)); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
+// activeExportedTGs returns the active exported target groups, only the one of the vpc of the
+// ServiceImportVpcAnnotation when it is set
+func activeExportedTGs(serviceImport *mcs_api.ServiceImport, exportedTGs []lattice.ExportedTargetGroup) []lattice.ExportedTargetGroup {
+	return lattice.ActiveExportedTargetGroups(exportedTGs, serviceImport.Annotations[k8s.ServiceImportVpcAnnotation])
+}
+
+// updateServiceImport sets the ports, clusters, cluster weights and ready annotations of the ServiceImport from
+// the exported target groups, returns whether the ServiceImport is ready. The ips are left alone, lattice services
+// are reached by their DNS name rather than a clusterset IP
+func (r *ServiceImportReconciler) updateServiceImport(ctx context.Context, serviceImport *mcs_api.ServiceImport,
+	exportedTGs []lattice.ExportedTargetGroup) (bool, error) {
+	ready := len(exportedTGs) > 0
+	readyValue := "False"
+	if ready {
+		readyValue = "True"
+	}
+
+	oldServiceImport := serviceImport.DeepCopy()
+	if serviceImport.Annotations == nil {
+		serviceImport.Annotations = make(map[string]string)
+	}
+	wasReady := serviceImport.Annotations[k8s.ServiceImportReadyAnnotation]
+	serviceImport.Annotations[k8s.ServiceImportReadyAnnotation] = readyValue
+	if ports, ok := buildServiceImportPorts(serviceImport.Spec.Ports, exportedTGs); ok {
+		serviceImport.Spec.Ports = ports
+	}
+	// a change of the weights updates the ServiceImport, so that the routes using it are reconciled
+	if weights := buildServiceImportClusterWeights(exportedTGs); weights != "" {
+		serviceImport.Annotations[k8s.ServiceImportClusterWeightsAnnotation] = weights
+	} else {
+		delete(serviceImport.Annotations, k8s.ServiceImportClusterWeightsAnnotation)
+	}
+	if !equality.Semantic.DeepEqual(oldServiceImport.Annotations, serviceImport.Annotations) ||
+		!equality.Semantic.DeepEqual(oldServiceImport.Spec.Ports, serviceImport.Spec.Ports) {
+		if err := r.Client.Patch(ctx, serviceImport, client.MergeFrom(oldServiceImport)); err != nil {
+			glog.V(2).Infof("Failed to update ServiceImport %s-%s, err %v\n", serviceImport.Name, serviceImport.Namespace, err)
+			return ready, err
+		}
+	}
+
+	clusters := buildServiceImportClusters(exportedTGs)
+	if !equality.Semantic.DeepEqual(serviceImport.Status.Clusters, clusters) {
+		oldServiceImport = serviceImport.DeepCopy()
+		serviceImport.Status.Clusters = clusters
+		if err := r.Client.Status().Patch(ctx, serviceImport, client.MergeFrom(oldServiceImport)); err != nil {
+			glog.V(2).Infof("Failed to update status of ServiceImport %s-%s, err %v\n", serviceImport.Name, serviceImport.Namespace, err)
+			return ready, err
+		}
+	}
+
+	if wasReady != readyValue {
+		if ready {
+			r.eventRecorder.Event(serviceImport, corev1.EventTypeNormal, k8s.ServiceImportEventReasonResolved,
+				fmt.Sprintf("Resolved exported target groups of clusters %v", clusters))
+		} else {
+			r.eventRecorder.Event(serviceImport, corev1.EventTypeWarning, k8s.ServiceImportEventReasonNotResolved,
+				"No active exported target group found, the service may not be exported yet")
+		}
+	}
+	return ready, nil
+}
+
+// buildServiceImportPorts returns the union of the exported ports, keeping the name and protocol of existing
+// ports. Returns false when no exported target group has the ports tag, the ports are left as is then
+func buildServiceImportPorts(currentPorts []mcs_api.ServicePort, exportedTGs []lattice.ExportedTargetGroup) ([]mcs_api.ServicePort, bool) {
+	portSet := make(map[int32]bool)
+	for _, tg := range exportedTGs {
+		for _, port := range tg.Ports {
+			portSet[port] = true
+		}
+	}
+	if len(portSet) == 0 {
+		return nil, false
+	}
+
+	var ports []mcs_api.ServicePort
+	for _, currentPort := range currentPorts {
+		if portSet[currentPort.Port] {
+			ports = append(ports, currentPort)
+			delete(portSet, currentPort.Port)
+		}
+	}
+	for port := range portSet {
+		ports = append(ports, mcs_api.ServicePort{
+			Port:     port,
+			Protocol: corev1.ProtocolTCP,
+		})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports, true
+}
+
+// buildServiceImportClusters returns the sorted vpcs of the clusters exporting the service, lattice does not
+// know the names of the clusters
+func buildServiceImportClusters(exportedTGs []lattice.ExportedTargetGroup) []mcs_api.ClusterStatus {
+	vpcSet := make(map[string]bool)
+	for _, tg := range exportedTGs {
+		vpcSet[tg.VpcID] = true
+	}
+
+	var clusters []mcs_api.ClusterStatus
+	for vpcID := range vpcSet {
+		clusters = append(clusters, mcs_api.ClusterStatus{Cluster: vpcID})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Cluster < clusters[j].Cluster })
+	return clusters
+}
+
+// buildServiceImportClusterWeights returns the weights of the clusters exporting the service, as comma separated
+// vpc=weight sorted by vpc
+func buildServiceImportClusterWeights(exportedTGs []lattice.ExportedTargetGroup) string {
+	weights := make(map[string]int64)
+	for _, tg := range exportedTGs {
+		if _, ok := weights[tg.VpcID]; !ok {
+			weights[tg.VpcID] = tg.Weight
+		}
+	}
+
+	var values []string
+	for vpcID, weight := range weights {
+		values = append(values, fmt.Sprintf("%s=%d", vpcID, weight))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		For(&mcs_api.ServiceImport{}).
+		Watches(&source.Channel{Source: r.exportedTGCache.Events()}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
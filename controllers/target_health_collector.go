@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+)
+
+const (
+	targetHealthCollectInterval = time.Minute
+	// targetsCacheMaxAge is how long the listed targets of a target group are reused, the pods polling their
+	// readiness gate share the ListTargets call of their target group
+	targetsCacheMaxAge = podReadinessGatePollInterval
+)
+
+// TargetHealthCollector periodically reads the health of the lattice targets of the services, and surfaces it
+// as annotations on the Service and a LatticeTargetsHealthy condition on its ServiceExport
+type TargetHealthCollector struct {
+	client           client.Client
+	latticeDataStore *latticestore.LatticeDataStore
+	targetsManager   lattice.TargetsManager
+
+	targetsLock  sync.Mutex
+	targetsCache map[string]cachedTargets
+}
+
+type cachedTargets struct {
+	targets  []*vpclattice.TargetSummary
+	listedAt time.Time
+}
+
+func NewTargetHealthCollector(client client.Client, ds *latticestore.LatticeDataStore, cloud lattice_aws.Cloud) *TargetHealthCollector {
+	return &TargetHealthCollector{
+		client:           client,
+		latticeDataStore: ds,
+		targetsManager:   lattice.NewTargetsManager(cloud, ds),
+	}
+}
+
+// Start collects the target health until the context is done, it implements manager.Runnable
+func (c *TargetHealthCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(targetHealthCollectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.collect(ctx)
+		}
+	}
+}
+
+func (c *TargetHealthCollector) collect(ctx context.Context) {
+	svcList := &corev1.ServiceList{}
+	if err := c.client.List(ctx, svcList); err != nil {
+		glog.V(2).Infof("TargetHealthCollector: failed to list services, err %v\n", err)
+		return
+	}
+
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		if !svc.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		health, err := c.collectServiceHealth(ctx, svc)
+		if err != nil {
+			glog.V(6).Infof("TargetHealthCollector: failed to collect health of service %s-%s, err %v\n",
+				svc.Name, svc.Namespace, err)
+			continue
+		}
+		if health == nil {
+			// e.g. the service is no longer a backend, its last known health is stale
+			c.clearServiceHealth(ctx, svc)
+			continue
+		}
+
+		glog.V(6).Infof("TargetHealthCollector: service %s-%s %v\n", svc.Name, svc.Namespace, health)
+		c.updateServiceAnnotations(ctx, svc, health)
+		c.updateServiceExportCondition(ctx, svc, health)
+	}
+}
+
+// collectServiceHealth counts the targets of all the target groups of the service, returns nil when the
+// service has no target group
+func (c *TargetHealthCollector) collectServiceHealth(ctx context.Context, svc *corev1.Service) (*lattice.TargetsHealth, error) {
+	tgIDs := make(map[string]bool)
+	for _, port := range servicePorts(svc) {
+		tgName := latticestore.TargetGroupPortName(svc.Name, svc.Namespace, port)
+		for _, tg := range c.latticeDataStore.GetTargetGroupsByTG(tgName) {
+			if tg.ID != "" {
+				tgIDs[tg.ID] = true
+			}
+		}
+	}
+	if len(tgIDs) == 0 {
+		return nil, nil
+	}
+
+	health := lattice.NewTargetsHealth()
+	for tgID := range tgIDs {
+		targets, err := c.Targets(ctx, tgID)
+		if err != nil {
+			return nil, err
+		}
+		health.Add(targets)
+	}
+	return health, nil
+}
+
+// Targets returns the targets of the target group with their health, listed at most targetsCacheMaxAge ago
+func (c *TargetHealthCollector) Targets(ctx context.Context, tgID string) ([]*vpclattice.TargetSummary, error) {
+	c.targetsLock.Lock()
+	cached, ok := c.targetsCache[tgID]
+	c.targetsLock.Unlock()
+	if ok && time.Since(cached.listedAt) < targetsCacheMaxAge {
+		return cached.targets, nil
+	}
+
+	targets, err := c.targetsManager.List(ctx, tgID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.targetsLock.Lock()
+	defer c.targetsLock.Unlock()
+	if c.targetsCache == nil {
+		c.targetsCache = make(map[string]cachedTargets)
+	}
+	// entries of deleted target groups are dropped once they expired
+	for id, entry := range c.targetsCache {
+		if time.Since(entry.listedAt) >= targetsCacheMaxAge {
+			delete(c.targetsCache, id)
+		}
+	}
+	c.targetsCache[tgID] = cachedTargets{
+		targets:  targets,
+		listedAt: time.Now(),
+	}
+	return targets, nil
+}
+
+// clearServiceHealth removes the target health annotations of the service, and the LatticeTargetsHealthy
+// condition of its ServiceExport
+func (c *TargetHealthCollector) clearServiceHealth(ctx context.Context, svc *corev1.Service) {
+	svcOld := svc.DeepCopy()
+	changed := false
+	for _, key := range []string{k8s.ServiceTargetsHealthAnnotation, k8s.ServiceTargetsTotalAnnotation,
+		k8s.ServiceTargetsHealthyAnnotation} {
+		if _, ok := svc.Annotations[key]; ok {
+			delete(svc.Annotations, key)
+			changed = true
+		}
+	}
+	if changed {
+		if err := c.client.Patch(ctx, svc, client.MergeFrom(svcOld)); err != nil {
+			glog.V(2).Infof("TargetHealthCollector: failed to remove annotations of service %s-%s, err %v\n",
+				svc.Name, svc.Namespace, err)
+		}
+	}
+
+	srvExport := &mcs_api.ServiceExport{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}, srvExport); err != nil {
+		return
+	}
+	srvExportOld := srvExport.DeepCopy()
+	if !k8s.RemoveServiceExportCondition(srvExport, k8s.ServiceExportConditionTargetsHealthy) {
+		return
+	}
+	if err := c.client.Status().Patch(ctx, srvExport, client.MergeFromWithOptions(srvExportOld, client.MergeFromWithOptimisticLock{})); err != nil {
+		glog.V(2).Infof("TargetHealthCollector: failed to update serviceexport %s-%s status, err %v\n",
+			srvExport.Name, srvExport.Namespace, err)
+	}
+}
+
+func (c *TargetHealthCollector) updateServiceAnnotations(ctx context.Context, svc *corev1.Service, health *lattice.TargetsHealth) {
+	annotations := map[string]string{
+		k8s.ServiceTargetsHealthAnnotation:  health.String(),
+		k8s.ServiceTargetsTotalAnnotation:   strconv.Itoa(health.Total),
+		k8s.ServiceTargetsHealthyAnnotation: strconv.Itoa(health.Healthy),
+	}
+
+	changed := false
+	for key, value := range annotations {
+		if svc.Annotations[key] != value {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	svcOld := svc.DeepCopy()
+	if svc.Annotations == nil {
+		svc.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		svc.Annotations[key] = value
+	}
+	if err := c.client.Patch(ctx, svc, client.MergeFrom(svcOld)); err != nil {
+		glog.V(2).Infof("TargetHealthCollector: failed to annotate service %s-%s, err %v\n", svc.Name, svc.Namespace, err)
+	}
+}
+
+func (c *TargetHealthCollector) updateServiceExportCondition(ctx context.Context, svc *corev1.Service, health *lattice.TargetsHealth) {
+	srvExport := &mcs_api.ServiceExport{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}, srvExport); err != nil {
+		return
+	}
+
+	status := corev1.ConditionTrue
+	reason := k8s.ServiceExportReasonTargetsHealthy
+	if health.Total == 0 {
+		status = corev1.ConditionFalse
+		reason = k8s.ServiceExportReasonNoTargets
+	} else if !health.IsHealthy() {
+		status = corev1.ConditionFalse
+		reason = k8s.ServiceExportReasonTargetsUnhealthy
+	}
+
+	srvExportOld := srvExport.DeepCopy()
+	if !k8s.SetServiceExportCondition(srvExport, k8s.ServiceExportConditionTargetsHealthy, status, reason, health.String()) {
+		return
+	}
+	if err := c.client.Status().Patch(ctx, srvExport, client.MergeFromWithOptions(srvExportOld, client.MergeFromWithOptimisticLock{})); err != nil {
+		glog.V(2).Infof("TargetHealthCollector: failed to update serviceexport %s-%s status, err %v\n",
+			srvExport.Name, srvExport.Namespace, err)
+	}
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+)
+
+func Test_TargetHealthCollector_collect(t *testing.T) {
+	tgID := "tg-id"
+	staleAnnotations := map[string]string{
+		k8s.ServiceTargetsHealthAnnotation:  "1/1 targets healthy",
+		k8s.ServiceTargetsTotalAnnotation:   "1",
+		k8s.ServiceTargetsHealthyAnnotation: "1",
+	}
+
+	tests := []struct {
+		name            string
+		tgInDatastore   bool
+		targets         []*vpclattice.TargetSummary
+		annotations     map[string]string
+		wantAnnotations map[string]string
+		wantCondition   *corev1.ConditionStatus
+		wantReason      string
+	}{
+		{
+			name:          "healthy targets",
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+			},
+			wantAnnotations: staleAnnotations,
+			wantCondition:   conditionStatusPtr(corev1.ConditionTrue),
+			wantReason:      k8s.ServiceExportReasonTargetsHealthy,
+		},
+		{
+			name:          "unhealthy targets",
+			tgInDatastore: true,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+				{Id: aws.String("10.0.0.2"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusUnhealthy)},
+			},
+			wantAnnotations: map[string]string{
+				k8s.ServiceTargetsHealthAnnotation:  "1/2 targets unhealthy: " + vpclattice.TargetStatusUnhealthy,
+				k8s.ServiceTargetsTotalAnnotation:   "2",
+				k8s.ServiceTargetsHealthyAnnotation: "1",
+			},
+			wantCondition: conditionStatusPtr(corev1.ConditionFalse),
+			wantReason:    k8s.ServiceExportReasonTargetsUnhealthy,
+		},
+		{
+			name:            "stale health is cleared without target group",
+			annotations:     staleAnnotations,
+			wantAnnotations: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			mcs_api.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			svcName := types.NamespacedName{Namespace: "ns1", Name: "svc1"}
+			assert.NoError(t, k8sClient.Create(ctx, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   svcName.Namespace,
+					Name:        svcName.Name,
+					Annotations: tt.annotations,
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}))
+			srvExport := &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: svcName.Namespace,
+					Name:      svcName.Name,
+				},
+			}
+			// the condition of the last collect
+			k8s.SetServiceExportCondition(srvExport, k8s.ServiceExportConditionTargetsHealthy, corev1.ConditionTrue,
+				k8s.ServiceExportReasonTargetsHealthy, "1/1 targets healthy")
+			assert.NoError(t, k8sClient.Create(ctx, srvExport))
+
+			ds := latticestore.NewLatticeDataStore()
+			mockTargetsManager := lattice.NewMockTargetsManager(c)
+			if tt.tgInDatastore {
+				ds.AddTargetGroup(latticestore.TargetGroupPortName(svcName.Name, svcName.Namespace, 80),
+					"vpc-id", "tg-arn", tgID, false, "route1")
+				mockTargetsManager.EXPECT().List(ctx, tgID).Return(tt.targets, nil)
+			}
+
+			collector := &TargetHealthCollector{
+				client:           k8sClient,
+				latticeDataStore: ds,
+				targetsManager:   mockTargetsManager,
+			}
+			collector.collect(ctx)
+
+			gotSvc := &corev1.Service{}
+			assert.NoError(t, k8sClient.Get(ctx, svcName, gotSvc))
+			for key, value := range tt.wantAnnotations {
+				assert.Equal(t, value, gotSvc.Annotations[key])
+			}
+			for key := range staleAnnotations {
+				if _, ok := tt.wantAnnotations[key]; !ok {
+					assert.NotContains(t, gotSvc.Annotations, key)
+				}
+			}
+
+			gotExport := &mcs_api.ServiceExport{}
+			assert.NoError(t, k8sClient.Get(ctx, svcName, gotExport))
+			var condition *mcs_api.ServiceExportCondition
+			for i := range gotExport.Status.Conditions {
+				if gotExport.Status.Conditions[i].Type == k8s.ServiceExportConditionTargetsHealthy {
+					condition = &gotExport.Status.Conditions[i]
+				}
+			}
+			if tt.wantCondition == nil {
+				assert.Nil(t, condition)
+				return
+			}
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, *tt.wantCondition, condition.Status)
+				assert.Equal(t, tt.wantReason, aws.StringValue(condition.Reason))
+			}
+		})
+	}
+}
+
+func conditionStatusPtr(status corev1.ConditionStatus) *corev1.ConditionStatus {
+	return &status
+}
+
+// the targets of a target group are listed once for all the pods polling their readiness gate
+func Test_TargetHealthCollector_Targets(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	targets := []*vpclattice.TargetSummary{
+		{Id: aws.String("10.0.0.1"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+	}
+	mockTargetsManager := lattice.NewMockTargetsManager(c)
+	mockTargetsManager.EXPECT().List(ctx, "tg-id").Return(targets, nil).Times(2)
+
+	collector := &TargetHealthCollector{
+		latticeDataStore: latticestore.NewLatticeDataStore(),
+		targetsManager:   mockTargetsManager,
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := collector.Targets(ctx, "tg-id")
+		assert.Nil(t, err)
+		assert.Equal(t, targets, got)
+	}
+
+	// listed again once expired
+	collector.targetsCache["tg-id"] = cachedTargets{
+		targets:  targets,
+		listedAt: time.Now().Add(-targetsCacheMaxAge),
+	}
+	got, err := collector.Targets(ctx, "tg-id")
+	assert.Nil(t, err)
+	assert.Equal(t, targets, got)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+)
+
+// TargetGroupPolicyReconciler writes the status of TargetGroupPolicy objects, the policies themselves are
+// applied when the target groups of the routes and serviceexports are built
+type TargetGroupPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func NewTargetGroupPolicyReconciler(client client.Client, scheme *runtime.Scheme) *TargetGroupPolicyReconciler {
+	return &TargetGroupPolicyReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+}
+
+//+kubebuilder:rbac:groups=application-networking.k8s.aws,resources=targetgrouppolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=application-networking.k8s.aws,resources=targetgrouppolicies/status,verbs=get;update;patch
+
+func (r *TargetGroupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policyLog := log.FromContext(ctx)
+
+	policyLog.Info("TargetGroupPolicyReconciler")
+
+	policy := &anv1alpha1.TargetGroupPolicy{}
+	if err := r.Client.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !policy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.updateTargetGroupPolicyStatus(ctx, policy)
+}
+
+func (r *TargetGroupPolicyReconciler) updateTargetGroupPolicyStatus(ctx context.Context, policy *anv1alpha1.TargetGroupPolicy) error {
+	policyOld := policy.DeepCopy()
+
+	accepted := r.validateTargetGroupPolicy(ctx, policy)
+	accepted.Type = anv1alpha1.PolicyConditionAccepted
+	accepted.ObservedGeneration = policy.Generation
+	meta.SetStatusCondition(&policy.Status.Conditions, accepted)
+
+	glog.V(6).Infof("updateTargetGroupPolicyStatus: policy %s-%s, condition %v\n", policy.Name, policy.Namespace, accepted)
+
+	if err := r.Client.Status().Patch(ctx, policy, client.MergeFrom(policyOld)); err != nil {
+		glog.V(2).Infof("updateTargetGroupPolicyStatus: Patch() received err %v \n", err)
+		return errors.Wrapf(err, "failed to update targetgrouppolicy status")
+	}
+	return nil
+}
+
+// validateTargetGroupPolicy returns the Accepted condition of the policy, without type and generation
+func (r *TargetGroupPolicyReconciler) validateTargetGroupPolicy(ctx context.Context, policy *anv1alpha1.TargetGroupPolicy) metav1.Condition {
+	if !k8s.IsTargetGroupPolicyTargetService(policy) {
+		return metav1.Condition{
+			Status:  metav1.ConditionFalse,
+			Reason:  anv1alpha1.PolicyReasonInvalid,
+			Message: "TargetRef must be a Service in the namespace of the policy",
+		}
+	}
+
+	svcName := types.NamespacedName{
+		Namespace: policy.Namespace,
+		Name:      string(policy.Spec.TargetRef.Name),
+	}
+	if err := r.Client.Get(ctx, svcName, &corev1.Service{}); err != nil {
+		return metav1.Condition{
+			Status:  metav1.ConditionFalse,
+			Reason:  anv1alpha1.PolicyReasonTargetNotFound,
+			Message: fmt.Sprintf("Service %s not found, err %v", svcName, err),
+		}
+	}
+
+	if applied := k8s.GetTargetGroupPolicy(ctx, r.Client, svcName); applied != nil && applied.Name != policy.Name {
+		return metav1.Condition{
+			Status:  metav1.ConditionFalse,
+			Reason:  anv1alpha1.PolicyReasonConflicted,
+			Message: fmt.Sprintf("Service %s is already targeted by TargetGroupPolicy %s", svcName, applied.Name),
+		}
+	}
+
+	return metav1.Condition{
+		Status:  metav1.ConditionTrue,
+		Reason:  anv1alpha1.PolicyReasonAccepted,
+		Message: fmt.Sprintf("Applied to Service %s", svcName),
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TargetGroupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	targetEventHandler := eventhandlers.NewEnqueueRequestTargetGroupPolicyTargetEvent(r.Client)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&anv1alpha1.TargetGroupPolicy{}).
+		Watches(&source.Kind{Type: &anv1alpha1.TargetGroupPolicy{}}, targetEventHandler).
+		Watches(&source.Kind{Type: &corev1.Service{}}, targetEventHandler).
+		Complete(r)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/aws/aws-application-networking-k8s/controllers/eventhandlers"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+	"github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy"
+	"github.com/aws/aws-application-networking-k8s/pkg/deploy/lattice"
+	"github.com/aws/aws-application-networking-k8s/pkg/gateway"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	lattice_runtime "github.com/aws/aws-application-networking-k8s/pkg/runtime"
+)
+
+// TLSRouteReconciler reconciles a TLSRoute object
+type TLSRouteReconciler struct {
+	client.Client
+	Scheme            *runtime.Scheme
+	gwReconciler      *GatewayReconciler
+	gwClassReconciler *GatewayClassReconciler
+	finalizerManager  k8s.FinalizerManager
+	eventRecorder     record.EventRecorder
+	modelBuilder      gateway.TLSLatticeServiceBuilder
+	stackDeployer     deploy.StackDeployer
+	latticeDataStore  *latticestore.LatticeDataStore
+	stackMashaller    deploy.StackMarshaller
+}
+
+const (
+	tlsRouteFinalizer = "tlsroute.k8s.aws/resources"
+)
+
+func NewTLSRouteReconciler(cloud aws.Cloud, client client.Client, scheme *runtime.Scheme, eventRecorder record.EventRecorder,
+	gwReconciler *GatewayReconciler, gwClassReconciler *GatewayClassReconciler, finalizerManager k8s.FinalizerManager,
+	latticeDataStore *latticestore.LatticeDataStore) *TLSRouteReconciler {
+	modelBuilder := gateway.NewTLSLatticeServiceBuilder(client, latticeDataStore, cloud)
+	stackDeployer := deploy.NewLatticeServiceStackDeploy(cloud, client, latticeDataStore)
+	stackMarshaller := deploy.NewDefaultStackMarshaller()
+
+	return &TLSRouteReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		gwReconciler:      gwReconciler,
+		gwClassReconciler: gwClassReconciler,
+		finalizerManager:  finalizerManager,
+		modelBuilder:      modelBuilder,
+		stackDeployer:     stackDeployer,
+		eventRecorder:     eventRecorder,
+		latticeDataStore:  latticeDataStore,
+		stackMashaller:    stackMarshaller,
+	}
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+func (r *TLSRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return lattice_runtime.HandleReconcileError(r.reconcile(ctx, req))
+}
+
+func (r *TLSRouteReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	tlsLog := log.FromContext(ctx)
+
+	tlsLog.Info("TLSRouteReconciler")
+
+	tlsRoute := &gateway_api_v1alpha2.TLSRoute{}
+
+	if err := r.Client.Get(ctx, req.NamespacedName, tlsRoute); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !r.isTLSRouteRelevant(ctx, tlsRoute) {
+		// not relevant
+		return nil
+	}
+
+	if conflict := k8s.FindConflictingRoute(ctx, r.Client, tlsRoute); conflict != nil {
+		glog.V(2).Infof("TLSRoute %s-%s conflicts, %v\n", tlsRoute.Name, tlsRoute.Namespace, conflict)
+		if !tlsRoute.DeletionTimestamp.IsZero() {
+			// the lattice service of the name belongs to the other route, there is nothing to clean up
+			r.finalizerManager.RemoveFinalizers(ctx, tlsRoute, tlsRouteFinalizer)
+			return nil
+		}
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeWarning, k8s.TLSRouteEventReasonConflicted, conflict.Error())
+		return updateRouteParentStatus(ctx, r.Client, r.eventRecorder, tlsRoute, "", conflict)
+	}
+
+	if !tlsRoute.DeletionTimestamp.IsZero() {
+		tlsLog.Info("Deleting")
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeNormal,
+			k8s.TLSRouteEventReasonReconcile, "Deleting Reconcile")
+		if err := r.cleanupTLSRouteResources(ctx, tlsRoute); err != nil {
+			glog.V(6).Infof("Failed to cleanup TLSRoute %v err %v\n", tlsRoute, err)
+			return err
+		}
+		UpdateTLSRouteListenerStatus(ctx, r.Client, tlsRoute)
+		r.finalizerManager.RemoveFinalizers(ctx, tlsRoute, tlsRouteFinalizer)
+
+		return nil
+	} else {
+		tlsLog.Info("Adding/Updating")
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeNormal,
+			k8s.TLSRouteEventReasonReconcile, "Adding/Updating Reconcile")
+		return r.reconcileTLSRouteResource(ctx, tlsRoute)
+	}
+
+}
+
+func (r *TLSRouteReconciler) cleanupTLSRouteResources(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) error {
+
+	_, _, err := r.buildAndDeployModel(ctx, tlsRoute)
+
+	return err
+}
+
+func (r *TLSRouteReconciler) isTLSRouteRelevant(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) bool {
+
+	if len(tlsRoute.Spec.ParentRefs) == 0 {
+		glog.V(6).Infof("Ignore TLSRoute which has no ParentRefs gateway %v \n ", tlsRoute.Spec)
+		return false
+	}
+
+	route, _ := k8s.NewRouteInfo(tlsRoute)
+
+	// relevant as long as one of the parents is a aws-vpc-lattice gateway
+	if len(k8s.ValidateRouteParents(ctx, r.Client, route)) == 0 {
+		glog.V(6).Infof("Ignore non aws-vpc-lattice TLSRoute !!! %v\n", tlsRoute.Spec)
+		return false
+	}
+
+	glog.V(6).Infof("Found aws-vpc-lattice for TLSRoute for %v\n", tlsRoute.Spec)
+	return true
+}
+
+func (r *TLSRouteReconciler) buildAndDeployModel(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) (core.Stack, *latticemodel.Service, error) {
+	tlsLog := log.FromContext(ctx)
+
+	stack, latticeService, err := r.modelBuilder.Build(ctx, tlsRoute)
+
+	if err != nil {
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeWarning,
+			k8s.TLSRouteEventReasonFailedBuildModel, fmt.Sprintf("Failed build model due to %v", err))
+		glog.V(6).Infof("buildAndDeployModel, Failed build model for %v due to %v\n", tlsRoute.Name, err)
+
+		if tlsRoute.DeletionTimestamp.IsZero() {
+			updateRouteParentStatus(ctx, r.Client, r.eventRecorder, tlsRoute, "", err)
+		}
+
+		return nil, nil, err
+	}
+
+	stackJSON, err := r.stackMashaller.Marshal(stack)
+	if err != nil {
+		glog.V(6).Infof("error on r.stackMashaller.Marshal error %v \n", err)
+	}
+
+	tlsLog.Info("Successfully built model:", stackJSON, "")
+
+	if err := r.stackDeployer.Deploy(ctx, stack); err != nil {
+		glog.V(6).Infof("TLSRouteReconciler: Failed deploy %s due to err %v \n", tlsRoute.Name, err)
+
+		var retryErr = errors.New(lattice.LATTICE_RETRY)
+
+		if errors.As(err, &retryErr) {
+			r.eventRecorder.Event(tlsRoute, corev1.EventTypeNormal,
+				k8s.TLSRouteEventReasonRetryReconcile, "retry reconcile...")
+
+		} else {
+			r.eventRecorder.Event(tlsRoute, corev1.EventTypeWarning,
+				k8s.TLSRouteEventReasonFailedDeployModel, fmt.Sprintf("Failed deploy model due to %v", err))
+		}
+		return nil, nil, err
+	}
+
+	tlsLog.Info("Successfully deployed model")
+
+	return stack, latticeService, err
+}
+
+func (r *TLSRouteReconciler) reconcileTLSRouteResource(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) error {
+	glog.V(6).Infof("Beginning -- reconcileTLSRouteResource, [%v]\n", tlsRoute)
+
+	if err := r.finalizerManager.AddFinalizers(ctx, tlsRoute, tlsRouteFinalizer); err != nil {
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeWarning, k8s.TLSRouteEventReasonFailedAddFinalizer, fmt.Sprintf("Failed add finalizer due to %v", err))
+	}
+
+	_, _, err := r.buildAndDeployModel(ctx, tlsRoute)
+
+	if err == nil {
+		r.eventRecorder.Event(tlsRoute, corev1.EventTypeNormal,
+			k8s.TLSRouteEventReasonDeploySucceed, "Adding/Updating reconcile Done!")
+
+		serviceStatus, err1 := r.latticeDataStore.GetLatticeService(tlsRoute.Name, tlsRoute.Namespace)
+
+		if err1 == nil {
+			r.updateTLSRouteStatus(ctx, serviceStatus.DNS, tlsRoute)
+		}
+	}
+
+	return err
+
+}
+
+func (r *TLSRouteReconciler) updateTLSRouteStatus(ctx context.Context, dns string, tlsRoute *gateway_api_v1alpha2.TLSRoute) error {
+	glog.V(6).Infof("updateTLSRouteStatus: tlsroute %v, dns %v\n", tlsRoute, dns)
+	tlsRouteOld := tlsRoute.DeepCopy()
+
+	if len(tlsRoute.ObjectMeta.Annotations) == 0 {
+		tlsRoute.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	tlsRoute.ObjectMeta.Annotations[LatticeAssignedDomainName] = dns
+
+	if err := r.Client.Patch(ctx, tlsRoute, client.MergeFrom(tlsRouteOld)); err != nil {
+		glog.V(2).Infof("updateTLSRouteStatus: Patch() received err %v \n", err)
+		return errors.Wrapf(err, "failed to update tlsroute status")
+	}
+
+	// Update listener Status
+	UpdateTLSRouteListenerStatus(ctx, r.Client, tlsRoute)
+
+	if err := updateRouteParentStatus(ctx, r.Client, r.eventRecorder, tlsRoute, dns, nil); err != nil {
+		return err
+	}
+
+	glog.V(6).Infof("updateTLSRouteStatus patched dns %v \n", dns)
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TLSRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	routeEventHandler := eventhandlers.NewEnqueueRequestRouteEvent()
+	gwEventHandler := eventhandlers.NewEnqueueRequestGatewayEvent(r.Client, k8s.TLSRouteKind)
+	svcEventHandler := eventhandlers.NewEqueueHTTPRequestServiceEvent(r.Client, k8s.TLSRouteKind)
+	svcImportEventHandler := eventhandlers.NewEqueueRequestServiceImportEvent(r.Client, k8s.TLSRouteKind)
+	refGrantEventHandler := eventhandlers.NewEnqueueRequestReferenceGrantEvent(r.Client, k8s.TLSRouteKind)
+	namespaceEventHandler := eventhandlers.NewEnqueueRequestNamespaceEvent(r.Client, k8s.TLSRouteKind)
+	tgPolicyEventHandler := eventhandlers.NewEnqueueRequestTargetGroupPolicyEvent(r.Client, k8s.TLSRouteKind)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gateway_api_v1alpha2.TLSRoute{}).
+		Watches(&source.Kind{Type: &gateway_api.HTTPRoute{}}, routeEventHandler).
+		Watches(&source.Kind{Type: &gateway_api_v1alpha2.GRPCRoute{}}, routeEventHandler).
+		Watches(&source.Kind{Type: &gateway_api.Gateway{}}, gwEventHandler).
+		Watches(&source.Kind{Type: &corev1.Service{}}, svcEventHandler).
+		Watches(&source.Kind{Type: &mcs_api.ServiceImport{}}, svcImportEventHandler).
+		Watches(&source.Kind{Type: &gateway_api.ReferenceGrant{}}, refGrantEventHandler).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, namespaceEventHandler).
+		Watches(&source.Kind{Type: &anv1alpha1.TargetGroupPolicy{}}, tgPolicyEventHandler).
+		Complete(r)
+}
@@ -34,10 +34,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/aws/aws-application-networking-k8s/controllers"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
 	//+kubebuilder:scaffold:imports
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 )
@@ -52,7 +54,9 @@ func init() {
 
 	//+kubebuilder:scaffold:scheme
 	utilruntime.Must(gateway_api.AddToScheme(scheme))
+	utilruntime.Must(gateway_api_v1alpha2.AddToScheme(scheme))
 	utilruntime.Must(mcs_api.AddToScheme(scheme))
+	utilruntime.Must(anv1alpha1.AddToScheme(scheme))
 }
 
 func main() {
@@ -101,10 +105,10 @@ func main() {
 	finalizerManager := k8s.NewDefaultFinalizerManager(mgr.GetClient(), ctrl.Log)
 	latticeDataStore := latticestore.NewLatticeDataStore()
 
-	if err = (&controllers.PodReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	targetHealthCollector := controllers.NewTargetHealthCollector(mgr.GetClient(), latticeDataStore, cloud)
+	podReconciler := controllers.NewPodReconciler(mgr.GetClient(), mgr.GetScheme(), latticeDataStore, targetHealthCollector)
+
+	if err = podReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Pod")
 		os.Exit(1)
 	}
@@ -144,7 +148,39 @@ func main() {
 
 	gwReconciler.UpdateGatewayReconciler(httpRouteReconciler)
 
-	serviceImportReconciler := controllers.NewServceImportReconciler(mgr.GetClient(), mgr.GetScheme(),
+	grpcRouteReconciler := controllers.NewGRPCRouteReconciler(cloud, mgr.GetClient(),
+		mgr.GetScheme(), mgr.GetEventRecorderFor("grpcroute"), gwReconciler, gwClassReconciler, finalizerManager,
+		latticeDataStore)
+
+	if err = grpcRouteReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GRPCRoute")
+		os.Exit(1)
+	}
+
+	tlsRouteReconciler := controllers.NewTLSRouteReconciler(cloud, mgr.GetClient(),
+		mgr.GetScheme(), mgr.GetEventRecorderFor("tlsroute"), gwReconciler, gwClassReconciler, finalizerManager,
+		latticeDataStore)
+
+	if err = tlsRouteReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TLSRoute")
+		os.Exit(1)
+	}
+
+	tgPolicyReconciler := controllers.NewTargetGroupPolicyReconciler(mgr.GetClient(), mgr.GetScheme())
+
+	if err = tgPolicyReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TargetGroupPolicy")
+		os.Exit(1)
+	}
+
+	// the target groups exported by all clusters are listed once for the ServiceImport reconciler and discovery
+	exportedTGCache := controllers.NewExportedTargetGroupCache(cloud)
+	if err = mgr.Add(exportedTGCache); err != nil {
+		setupLog.Error(err, "unable to add exported target group cache")
+		os.Exit(1)
+	}
+
+	serviceImportReconciler := controllers.NewServceImportReconciler(exportedTGCache, mgr.GetClient(), mgr.GetScheme(),
 		mgr.GetEventRecorderFor("ServiceImport"), finalizerManager, latticeDataStore)
 
 	if err = serviceImportReconciler.SetupWithManager(mgr); err != nil {
@@ -160,6 +196,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = mgr.Add(targetHealthCollector); err != nil {
+		setupLog.Error(err, "unable to add target health collector")
+		os.Exit(1)
+	}
+
+	if config.ServiceImportDiscovery {
+		if err = mgr.Add(controllers.NewServiceImportDiscovery(mgr.GetClient(), cloud, exportedTGCache)); err != nil {
+			setupLog.Error(err, "unable to add serviceimport discovery")
+			os.Exit(1)
+		}
+	}
+
 	go latticestore.GetDefaultLatticeDataStore().ServeIntrospection()
 
 	//+kubebuilder:scaffold:builder
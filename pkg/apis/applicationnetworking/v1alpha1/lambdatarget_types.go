@@ -0,0 +1,62 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	LambdaTargetKind = "LambdaTarget"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=gateway-api,shortName=lt
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Function",type=string,JSONPath=`.spec.functionArn`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// LambdaTarget is a Lambda function which routes refer to as backendRef, with group
+// application-networking.k8s.aws and kind LambdaTarget
+type LambdaTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LambdaTargetSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// LambdaTargetList contains a list of LambdaTarget
+type LambdaTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LambdaTarget `json:"items"`
+}
+
+// LambdaTargetSpec defines the desired state of LambdaTarget
+type LambdaTargetSpec struct {
+	// FunctionARN is the ARN of the Lambda function registered to the lattice target group, optionally
+	// qualified with a version or alias.
+	//
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:lambda:[a-z0-9-]+:[0-9]{12}:function:[a-zA-Z0-9_-]+(:[a-zA-Z0-9_$-]+)?$`
+	FunctionARN string `json:"functionArn"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LambdaTarget{}, &LambdaTargetList{})
+}
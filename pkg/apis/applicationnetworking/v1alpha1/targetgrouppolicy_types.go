@@ -0,0 +1,178 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const (
+	TargetGroupPolicyKind = "TargetGroupPolicy"
+
+	// PolicyConditionAccepted is the condition type of a policy which is applied to its target
+	PolicyConditionAccepted = "Accepted"
+
+	PolicyReasonAccepted       = "Accepted"
+	PolicyReasonInvalid        = "Invalid"
+	PolicyReasonTargetNotFound = "TargetNotFound"
+	PolicyReasonConflicted     = "Conflicted"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=gateway-api,shortName=tgp
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TargetGroupPolicy configures the lattice target groups of the Service it targets
+type TargetGroupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TargetGroupPolicySpec   `json:"spec"`
+	Status TargetGroupPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TargetGroupPolicyList contains a list of TargetGroupPolicy
+type TargetGroupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TargetGroupPolicy `json:"items"`
+}
+
+// TargetGroupPolicySpec defines the desired state of TargetGroupPolicy
+type TargetGroupPolicySpec struct {
+	// The protocol used to route traffic to the targets, HTTP (default) or HTTPS.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	Protocol *string `json:"protocol,omitempty"`
+
+	// The protocol version used to route traffic to the targets, HTTP1, HTTP2 or GRPC. It overrides the
+	// version derived from the appProtocol of the Service port, HTTP1 by default.
+	// It is ignored for GRPCRoute backends, which always use GRPC.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=HTTP1;HTTP2;GRPC
+	ProtocolVersion *string `json:"protocolVersion,omitempty"`
+
+	// TargetRef is the Service the policy applies to, the policy must be in the namespace of the Service.
+	TargetRef *gateway_api_v1alpha2.PolicyTargetReference `json:"targetRef"`
+
+	// The health check configuration of the target groups.
+	//
+	// +optional
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
+}
+
+// HealthCheckConfig is the health check configuration of a target group. Unset fields keep the lattice defaults
+type HealthCheckConfig struct {
+	// Whether health checking is enabled.
+	//
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// The approximate amount of time, in seconds, between health checks of an individual target.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:validation:Maximum=300
+	IntervalSeconds *int64 `json:"intervalSeconds,omitempty"`
+
+	// The amount of time, in seconds, to wait before reporting a target as unhealthy.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=120
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+
+	// The number of consecutive successful health checks required before an unhealthy target is considered healthy.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	HealthyThresholdCount *int64 `json:"healthyThresholdCount,omitempty"`
+
+	// The number of consecutive failed health checks required before a target is considered unhealthy.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	UnhealthyThresholdCount *int64 `json:"unhealthyThresholdCount,omitempty"`
+
+	// The HTTP codes to use when checking for a successful response from a target, e.g. "200" or "200-299".
+	//
+	// +optional
+	StatusMatch *string `json:"statusMatch,omitempty"`
+
+	// The destination for health checks on the targets.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	Path *string `json:"path,omitempty"`
+
+	// The port used for health checks, the target port by default.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int64 `json:"port,omitempty"`
+
+	// The protocol used for health checks.
+	//
+	// +optional
+	Protocol *HealthCheckProtocol `json:"protocol,omitempty"`
+
+	// The protocol version used for health checks.
+	//
+	// +optional
+	ProtocolVersion *HealthCheckProtocolVersion `json:"protocolVersion,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type HealthCheckProtocol string
+
+const (
+	HealthCheckProtocolHTTP  HealthCheckProtocol = "HTTP"
+	HealthCheckProtocolHTTPS HealthCheckProtocol = "HTTPS"
+)
+
+// +kubebuilder:validation:Enum=HTTP1;HTTP2
+type HealthCheckProtocolVersion string
+
+const (
+	HealthCheckProtocolVersionHTTP1 HealthCheckProtocolVersion = "HTTP1"
+	HealthCheckProtocolVersionHTTP2 HealthCheckProtocolVersion = "HTTP2"
+)
+
+// TargetGroupPolicyStatus defines the observed state of TargetGroupPolicy
+type TargetGroupPolicyStatus struct {
+	// Conditions describe whether the policy is applied to its target.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TargetGroupPolicy{}, &TargetGroupPolicyList{})
+}
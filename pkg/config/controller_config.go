@@ -16,13 +16,26 @@ const (
 )
 
 const (
-	NO_DEFAULT_SERVICE_NETWORK      = "NO_DEFAULT_SERVICE_NETWORK"
-	REGION                          = "REGION"
-	CLUSTER_VPC_ID                  = "CLUSTER_VPC_ID"
-	CLUSTER_LOCAL_GATEWAY           = "CLUSTER_LOCAL_GATEWAY"
-	AWS_ACCOUNT_ID                  = "AWS_ACCOUNT_ID"
-	TARGET_GROUP_NAME_LEN_MODE      = "TARGET_GROUP_NAME_LEN_MODE"
-	GATEWAY_API_CONTROLLER_LOGLEVEL = "GATEWAY_API_CONTROLLER_LOGLEVEL"
+	NO_DEFAULT_SERVICE_NETWORK          = "NO_DEFAULT_SERVICE_NETWORK"
+	REGION                              = "REGION"
+	CLUSTER_VPC_ID                      = "CLUSTER_VPC_ID"
+	CLUSTER_LOCAL_GATEWAY               = "CLUSTER_LOCAL_GATEWAY"
+	AWS_ACCOUNT_ID                      = "AWS_ACCOUNT_ID"
+	TARGET_GROUP_NAME_LEN_MODE          = "TARGET_GROUP_NAME_LEN_MODE"
+	GATEWAY_API_CONTROLLER_LOGLEVEL     = "GATEWAY_API_CONTROLLER_LOGLEVEL"
+	ENDPOINT_READINESS_POLICY           = "ENDPOINT_READINESS_POLICY"
+	SERVICE_IMPORT_DISCOVERY            = "SERVICE_IMPORT_DISCOVERY"
+	SERVICE_IMPORT_DISCOVERY_NAMESPACES = "SERVICE_IMPORT_DISCOVERY_NAMESPACES"
+)
+
+// Values of ENDPOINT_READINESS_POLICY, which decides which EndpointSlice endpoints are registered as targets
+const (
+	// only ready endpoints
+	EndpointReadinessPolicyReady = "ready"
+	// ready endpoints, and terminating endpoints which are still serving
+	EndpointReadinessPolicyServing = "serving"
+	// all endpoints, including not ready ones
+	EndpointReadinessPolicyAll = "all"
 )
 
 var VpcID = UnknownInput
@@ -31,6 +44,9 @@ var Region = UnknownInput
 var logLevel = defaultLogLevel
 var DefaultServiceNetwork = UnknownInput
 var UseLongTGName = false
+var EndpointReadinessPolicy = EndpointReadinessPolicyReady
+var ServiceImportDiscovery = false
+var ServiceImportDiscoveryNamespaces []string
 
 func GetLogLevel() string {
 	logLevel = os.Getenv(GATEWAY_API_CONTROLLER_LOGLEVEL)
@@ -51,6 +67,20 @@ func GetClusterLocalGateway() (string, error) {
 	return DefaultServiceNetwork, nil
 }
 
+// IsServiceImportDiscoveryNamespace returns whether ServiceImports may be discovered in the namespace,
+// all namespaces are allowed when SERVICE_IMPORT_DISCOVERY_NAMESPACES is not set
+func IsServiceImportDiscoveryNamespace(namespace string) bool {
+	if len(ServiceImportDiscoveryNamespaces) == 0 {
+		return true
+	}
+	for _, allowed := range ServiceImportDiscoveryNamespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func ConfigInit() {
 
 	sess, _ := session.NewSession()
@@ -114,4 +144,33 @@ func ConfigInit() {
 	} else {
 		UseLongTGName = false
 	}
+
+	// ENDPOINT_READINESS_POLICY
+	endpointReadinessPolicy := os.Getenv(ENDPOINT_READINESS_POLICY)
+	glog.V(2).Infoln("ENDPOINT_READINESS_POLICY", endpointReadinessPolicy)
+
+	switch strings.ToLower(endpointReadinessPolicy) {
+	case EndpointReadinessPolicyServing:
+		EndpointReadinessPolicy = EndpointReadinessPolicyServing
+	case EndpointReadinessPolicyAll:
+		EndpointReadinessPolicy = EndpointReadinessPolicyAll
+	default:
+		EndpointReadinessPolicy = EndpointReadinessPolicyReady
+	}
+
+	// SERVICE_IMPORT_DISCOVERY
+	serviceImportDiscovery := os.Getenv(SERVICE_IMPORT_DISCOVERY)
+	glog.V(2).Infoln("SERVICE_IMPORT_DISCOVERY", serviceImportDiscovery)
+	ServiceImportDiscovery = strings.ToLower(serviceImportDiscovery) == "true"
+
+	// SERVICE_IMPORT_DISCOVERY_NAMESPACES
+	serviceImportDiscoveryNamespaces := os.Getenv(SERVICE_IMPORT_DISCOVERY_NAMESPACES)
+	glog.V(2).Infoln("SERVICE_IMPORT_DISCOVERY_NAMESPACES", serviceImportDiscoveryNamespaces)
+
+	ServiceImportDiscoveryNamespaces = nil
+	for _, namespace := range strings.Split(serviceImportDiscoveryNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			ServiceImportDiscoveryNamespaces = append(ServiceImportDiscoveryNamespaces, namespace)
+		}
+	}
 }
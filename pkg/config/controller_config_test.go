@@ -17,12 +17,14 @@ func Test_config_init_with_partial_env_var(t *testing.T) {
 	os.Setenv(CLUSTER_LOCAL_GATEWAY, testClusterLocalGateway)
 	os.Unsetenv(AWS_ACCOUNT_ID)
 	os.Unsetenv(TARGET_GROUP_NAME_LEN_MODE)
+	os.Unsetenv(ENDPOINT_READINESS_POLICY)
 	ConfigInit()
 	assert.Equal(t, Region, testRegion)
 	assert.Equal(t, VpcID, testClusterVpcId)
 	assert.Equal(t, AccountID, UnknownInput)
 	assert.Equal(t, DefaultServiceNetwork, testClusterLocalGateway)
 	assert.Equal(t, UseLongTGName, false)
+	assert.Equal(t, EndpointReadinessPolicy, EndpointReadinessPolicyReady)
 }
 
 func Test_config_init_no_env_var(t *testing.T) {
@@ -31,12 +33,14 @@ func Test_config_init_no_env_var(t *testing.T) {
 	os.Unsetenv(CLUSTER_LOCAL_GATEWAY)
 	os.Unsetenv(AWS_ACCOUNT_ID)
 	os.Unsetenv(TARGET_GROUP_NAME_LEN_MODE)
+	os.Unsetenv(ENDPOINT_READINESS_POLICY)
 	ConfigInit()
 	assert.Equal(t, Region, UnknownInput)
 	assert.Equal(t, VpcID, UnknownInput)
 	assert.Equal(t, AccountID, UnknownInput)
 	assert.Equal(t, DefaultServiceNetwork, UnknownInput)
 	assert.Equal(t, UseLongTGName, false)
+	assert.Equal(t, EndpointReadinessPolicy, EndpointReadinessPolicyReady)
 }
 
 func Test_config_init_with_all_env_var(t *testing.T) {
@@ -46,16 +50,73 @@ func Test_config_init_with_all_env_var(t *testing.T) {
 	testClusterLocalGateway := "default"
 	testTargetGroupNameLenMode := "long"
 	testAwsAccountId := "12345678"
+	testEndpointReadinessPolicy := "serving"
 
 	os.Setenv(REGION, testRegion)
 	os.Setenv(CLUSTER_VPC_ID, testClusterVpcId)
 	os.Setenv(CLUSTER_LOCAL_GATEWAY, testClusterLocalGateway)
 	os.Setenv(AWS_ACCOUNT_ID, testAwsAccountId)
 	os.Setenv(TARGET_GROUP_NAME_LEN_MODE, testTargetGroupNameLenMode)
+	os.Setenv(ENDPOINT_READINESS_POLICY, testEndpointReadinessPolicy)
 	ConfigInit()
 	assert.Equal(t, Region, testRegion)
 	assert.Equal(t, VpcID, testClusterVpcId)
 	assert.Equal(t, AccountID, testAwsAccountId)
 	assert.Equal(t, DefaultServiceNetwork, testClusterLocalGateway)
 	assert.Equal(t, UseLongTGName, true)
+	assert.Equal(t, EndpointReadinessPolicy, EndpointReadinessPolicyServing)
+}
+
+func Test_config_init_endpoint_readiness_policy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: "", want: EndpointReadinessPolicyReady},
+		{policy: "ready", want: EndpointReadinessPolicyReady},
+		{policy: "Serving", want: EndpointReadinessPolicyServing},
+		{policy: "all", want: EndpointReadinessPolicyAll},
+		{policy: "unknown", want: EndpointReadinessPolicyReady},
+	}
+
+	for _, tt := range tests {
+		os.Setenv(ENDPOINT_READINESS_POLICY, tt.policy)
+		ConfigInit()
+		assert.Equal(t, tt.want, EndpointReadinessPolicy)
+	}
+	os.Unsetenv(ENDPOINT_READINESS_POLICY)
+}
+
+func Test_config_init_service_import_discovery(t *testing.T) {
+	tests := []struct {
+		discovery      string
+		namespaces     string
+		wantDiscovery  bool
+		wantNamespaces []string
+	}{
+		{discovery: "", namespaces: "", wantDiscovery: false, wantNamespaces: nil},
+		{discovery: "true", namespaces: "", wantDiscovery: true, wantNamespaces: nil},
+		{discovery: "True", namespaces: "ns1", wantDiscovery: true, wantNamespaces: []string{"ns1"}},
+		{discovery: "false", namespaces: "ns1, ns2,,", wantDiscovery: false, wantNamespaces: []string{"ns1", "ns2"}},
+	}
+
+	for _, tt := range tests {
+		os.Setenv(SERVICE_IMPORT_DISCOVERY, tt.discovery)
+		os.Setenv(SERVICE_IMPORT_DISCOVERY_NAMESPACES, tt.namespaces)
+		ConfigInit()
+		assert.Equal(t, tt.wantDiscovery, ServiceImportDiscovery)
+		assert.Equal(t, tt.wantNamespaces, ServiceImportDiscoveryNamespaces)
+	}
+	os.Unsetenv(SERVICE_IMPORT_DISCOVERY)
+	os.Unsetenv(SERVICE_IMPORT_DISCOVERY_NAMESPACES)
+}
+
+func Test_IsServiceImportDiscoveryNamespace(t *testing.T) {
+	ServiceImportDiscoveryNamespaces = nil
+	assert.True(t, IsServiceImportDiscoveryNamespace("ns1"))
+
+	ServiceImportDiscoveryNamespaces = []string{"ns1", "ns2"}
+	assert.True(t, IsServiceImportDiscoveryNamespace("ns2"))
+	assert.False(t, IsServiceImportDiscoveryNamespace("ns3"))
+	ServiceImportDiscoveryNamespaces = nil
 }
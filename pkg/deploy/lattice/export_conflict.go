@@ -0,0 +1,39 @@
+package lattice
+
+// ExportConflict is the target group exported for the same k8s service by the cluster of another vpc, which
+// is incompatible with the export of this cluster
+type ExportConflict struct {
+	VpcID            string
+	Ports            []int32
+	Protocol         string
+	PortsConflict    bool
+	ProtocolConflict bool
+}
+
+// FindExportConflicts compares the ports and protocol exported by this cluster with the target groups exported for
+// the same k8s service by the clusters of other vpcs. Ports and protocols which are not known, e.g. exported by a
+// controller which did not tag the ports, do not conflict
+func FindExportConflicts(vpcID string, ports []int32, protocol string, exportedTGs []ExportedTargetGroup) []ExportConflict {
+	var conflicts []ExportConflict
+	for _, tg := range exportedTGs {
+		if tg.VpcID == vpcID {
+			continue
+		}
+
+		conflict := ExportConflict{
+			VpcID:    tg.VpcID,
+			Ports:    tg.Ports,
+			Protocol: tg.Protocol,
+		}
+		if len(ports) > 0 && len(tg.Ports) > 0 && formatExportPorts(ports) != formatExportPorts(tg.Ports) {
+			conflict.PortsConflict = true
+		}
+		if protocol != "" && tg.Protocol != "" && protocol != tg.Protocol {
+			conflict.ProtocolConflict = true
+		}
+		if conflict.PortsConflict || conflict.ProtocolConflict {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,76 @@
+package lattice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindExportConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		ports       []int32
+		protocol    string
+		exportedTGs []ExportedTargetGroup
+		want        []ExportConflict
+	}{
+		{
+			name:     "only exported by this cluster",
+			ports:    []int32{80},
+			protocol: "HTTP",
+			exportedTGs: []ExportedTargetGroup{
+				{VpcID: "vpc-1", Ports: []int32{80}, Protocol: "HTTP"},
+			},
+			want: nil,
+		},
+		{
+			name:     "compatible exports",
+			ports:    []int32{80, 8080},
+			protocol: "HTTP",
+			exportedTGs: []ExportedTargetGroup{
+				{VpcID: "vpc-1", Ports: []int32{80, 8080}, Protocol: "HTTP"},
+				{VpcID: "vpc-2", Ports: []int32{8080, 80}, Protocol: "HTTP"},
+			},
+			want: nil,
+		},
+		{
+			name:     "ports conflict",
+			ports:    []int32{80},
+			protocol: "HTTP",
+			exportedTGs: []ExportedTargetGroup{
+				{VpcID: "vpc-2", Ports: []int32{8080}, Protocol: "HTTP"},
+			},
+			want: []ExportConflict{
+				{VpcID: "vpc-2", Ports: []int32{8080}, Protocol: "HTTP", PortsConflict: true},
+			},
+		},
+		{
+			name:     "protocol conflict",
+			ports:    []int32{80},
+			protocol: "HTTP",
+			exportedTGs: []ExportedTargetGroup{
+				{VpcID: "vpc-2", Ports: []int32{80}, Protocol: "HTTPS"},
+				{VpcID: "vpc-3", Ports: []int32{80}, Protocol: "HTTP"},
+			},
+			want: []ExportConflict{
+				{VpcID: "vpc-2", Ports: []int32{80}, Protocol: "HTTPS", ProtocolConflict: true},
+			},
+		},
+		{
+			name:     "unknown ports and protocol do not conflict",
+			ports:    nil,
+			protocol: "",
+			exportedTGs: []ExportedTargetGroup{
+				{VpcID: "vpc-2", Ports: []int32{8080}, Protocol: "HTTPS"},
+				{VpcID: "vpc-3"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FindExportConflicts("vpc-1", tt.ports, tt.protocol, tt.exportedTGs))
+		})
+	}
+}
@@ -44,13 +44,36 @@ func (s *defaultListenerManager) Create(ctx context.Context, listener *latticemo
 		return latticemodel.ListenerStatus{}, errors.New(errmsg)
 	}
 
+	latticeTGs, err := buildLatticeTGs(s.latticeDataStore, listener.Spec.DefaultAction.TargetGroups)
+	if err != nil {
+		glog.V(2).Infof("Failed to build default action of listener %v, err %v\n", listener.Spec, err)
+		return latticemodel.ListenerStatus{}, err
+	}
+	defaultAction := buildSDKRuleAction(&listener.Spec.DefaultAction, latticeTGs)
+
+	if err := s.updateCertificate(ctx, serviceStatus.ID, listener.Spec); err != nil {
+		return latticemodel.ListenerStatus{}, err
+	}
+
 	lis, err := s.findListenerByNamePort(ctx, serviceStatus.ID, listener.Spec.Port)
 
 	glog.V(6).Infof("findListenerByNamePort %v , lisenter %v error %v\n", listener, lis, err)
 
-	if err == nil {
-		// update Listener
-		// TODO
+	if err == nil && isListenerReplaceNeeded(listener.Spec, lis) {
+		// lattice can only update the default action of a listener, anything else needs a new listener.
+		// The rules of the old listener go away with it and are rebuilt on the new one by the rule synthesizer
+		glog.V(2).Infof("Replacing listener %v, protocol %v -> %v, tls mode %v\n", aws.StringValue(lis.Name),
+			aws.StringValue(lis.Protocol), listener.Spec.Protocol, listener.Spec.TLSMode)
+		if err := s.Delete(ctx, aws.StringValue(lis.Id), serviceStatus.ID); err != nil {
+			return latticemodel.ListenerStatus{}, err
+		}
+		s.latticeDataStore.DelListener(listener.Spec.Name, listener.Spec.Namespace, aws.Int64Value(lis.Port),
+			aws.StringValue(lis.Protocol))
+	} else if err == nil {
+		if err := s.updateDefaultAction(ctx, serviceStatus.ID, lis, defaultAction); err != nil {
+			return latticemodel.ListenerStatus{}, err
+		}
+
 		k8sname, k8snamespace := latticeName2k8s(aws.StringValue(lis.Name))
 		return latticemodel.ListenerStatus{
 			Name:        k8sname,
@@ -63,17 +86,9 @@ func (s *defaultListenerManager) Create(ctx context.Context, listener *latticemo
 		}, nil
 	}
 
-	defaultStatus := aws.Int64(404)
-
-	defaultResp := vpclattice.FixedResponseAction{
-		StatusCode: defaultStatus,
-	}
 	listenerInput := vpclattice.CreateListenerInput{
-		ClientToken: nil,
-		DefaultAction: &vpclattice.RuleAction{
-			FixedResponse: &defaultResp,
-		},
-
+		ClientToken:       nil,
+		DefaultAction:     defaultAction,
 		Name:              aws.String(k8sLatticeListenerName(listener.Spec.Name, listener.Spec.Namespace, int(listener.Spec.Port), listener.Spec.Protocol)),
 		Port:              aws.Int64(listener.Spec.Port),
 		Protocol:          aws.String(listener.Spec.Protocol),
@@ -90,6 +105,9 @@ func (s *defaultListenerManager) Create(ctx context.Context, listener *latticemo
 	glog.V(2).Infoln("############resp creating listner ###########")
 	glog.V(2).Infof("create listener err :%v\n", err)
 	glog.V(2).Infoln(resp)
+	if err != nil {
+		return latticemodel.ListenerStatus{}, err
+	}
 	return latticemodel.ListenerStatus{
 		Name:        listener.Spec.Name,
 		Namespace:   listener.Spec.Namespace,
@@ -100,6 +118,89 @@ func (s *defaultListenerManager) Create(ctx context.Context, listener *latticemo
 		Protocol:    listener.Spec.Protocol}, nil
 }
 
+// isListenerReplaceNeeded returns whether the lattice listener on the port of the model listener differs
+// in something UpdateListener can not change, its protocol or TLS mode
+func isListenerReplaceNeeded(spec latticemodel.ListenerSpec, lis *vpclattice.ListenerSummary) bool {
+	return !strings.EqualFold(spec.Protocol, aws.StringValue(lis.Protocol)) ||
+		spec.TLSMode != listenerTLSMode(aws.StringValue(lis.Protocol))
+}
+
+// listenerTLSMode returns the TLS mode of a lattice listener of the protocol
+func listenerTLSMode(protocol string) string {
+	switch strings.ToUpper(protocol) {
+	case "HTTPS":
+		return latticemodel.ListenerTLSModeTerminate
+	case latticemodel.ListenerProtocolTLSPassthrough:
+		return latticemodel.ListenerTLSModePassthrough
+	}
+	return ""
+}
+
+// updateCertificate updates the custom certificate of an HTTPS listener in place when it has changed,
+// lattice keeps the certificate on the service rather than on the listener
+func (s *defaultListenerManager) updateCertificate(ctx context.Context, serviceID string,
+	spec latticemodel.ListenerSpec) error {
+	if spec.TLSMode != latticemodel.ListenerTLSModeTerminate || spec.CertificateARN == "" {
+		return nil
+	}
+	latticeSess := s.cloud.Lattice()
+
+	getServiceInput := vpclattice.GetServiceInput{
+		ServiceIdentifier: aws.String(serviceID),
+	}
+	resp, err := latticeSess.GetService(&getServiceInput)
+	if err != nil {
+		glog.V(2).Infof("Failed to get service %v, err %v\n", serviceID, err)
+		return err
+	}
+
+	if aws.StringValue(resp.CertificateArn) == spec.CertificateARN {
+		return nil
+	}
+
+	updateServiceInput := vpclattice.UpdateServiceInput{
+		CertificateArn:    aws.String(spec.CertificateARN),
+		ServiceIdentifier: aws.String(serviceID),
+	}
+	_, err = latticeSess.UpdateService(&updateServiceInput)
+
+	glog.V(2).Infof("Updated certificate of service %v to %v, err %v\n", serviceID, spec.CertificateARN, err)
+	return err
+}
+
+// updateDefaultAction updates the default action of an existing listener in place when it has drifted
+// from the model, e.g. the catch-all rule of the route changed
+func (s *defaultListenerManager) updateDefaultAction(ctx context.Context, serviceID string,
+	lis *vpclattice.ListenerSummary, defaultAction *vpclattice.RuleAction) error {
+	latticeSess := s.cloud.Lattice()
+
+	getListenerInput := vpclattice.GetListenerInput{
+		ListenerIdentifier: lis.Id,
+		ServiceIdentifier:  aws.String(serviceID),
+	}
+	resp, err := latticeSess.GetListener(&getListenerInput)
+	if err != nil {
+		glog.V(2).Infof("Failed to get listener %v, err %v\n", aws.StringValue(lis.Id), err)
+		return err
+	}
+
+	if isSDKRuleActionSame(defaultAction, resp.DefaultAction) {
+		return nil
+	}
+
+	updateListenerInput := vpclattice.UpdateListenerInput{
+		DefaultAction:      defaultAction,
+		ListenerIdentifier: lis.Id,
+		ServiceIdentifier:  aws.String(serviceID),
+	}
+	_, err = latticeSess.UpdateListener(&updateListenerInput)
+
+	glog.V(2).Infoln("############req updating listener default action ###########")
+	glog.V(2).Infoln(updateListenerInput)
+	glog.V(2).Infof("update listener err :%v\n", err)
+	return err
+}
+
 func k8s2LatticeName(name string, namespace string) string {
 	// TODO handle namespace
 	return name
@@ -107,7 +208,9 @@ func k8s2LatticeName(name string, namespace string) string {
 }
 
 func k8sLatticeListenerName(name string, namespace string, port int, protocol string) string {
-	listenerName := fmt.Sprintf("%s-%s-%d-%s", name, namespace, port, strings.ToLower(protocol))
+	// lattice names only allow lower case letters, digits and hyphens, e.g. TLS_PASSTHROUGH is tls-passthrough
+	listenerName := fmt.Sprintf("%s-%s-%d-%s", name, namespace, port,
+		strings.ReplaceAll(strings.ToLower(protocol), "_", "-"))
 
 	return listenerName
 }
@@ -154,6 +257,9 @@ func (s *defaultListenerManager) List(ctx context.Context, serviceID string) ([]
 
 }
 
+// findListenerByNamePort matches on the port only, lattice has one listener per port and the model builder
+// rejects routes whose parent listeners have different protocols on the same port. A listener found with
+// another protocol is therefore one the route changed, which is replaced
 func (s *defaultListenerManager) findListenerByNamePort(ctx context.Context, serviceID string, port int64) (*vpclattice.ListenerSummary, error) {
 	glog.V(6).Infof("calling findListenerByNamePort serviceID %v port %d \n", serviceID, port)
 	latticeSess := s.cloud.Lattice()
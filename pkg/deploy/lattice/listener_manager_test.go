@@ -75,9 +75,12 @@ var listenerList = vpclattice.ListListenersOutput{
 func Test_AddListener(t *testing.T) {
 
 	tests := []struct {
-		name        string
-		isUpdate    bool
-		noServiceID bool
+		name                string
+		isUpdate            bool
+		forward             bool
+		updateDefaultAction bool
+		replaceProtocol     bool
+		noServiceID         bool
 	}{
 		{
 			name:        "add listner",
@@ -85,12 +88,34 @@ func Test_AddListener(t *testing.T) {
 			noServiceID: false,
 		},
 
+		{
+			name:        "add listner, forward to catch-all rule target group",
+			isUpdate:    false,
+			forward:     true,
+			noServiceID: false,
+		},
+
 		{
 			name:        "update listner",
 			isUpdate:    true,
 			noServiceID: false,
 		},
 
+		{
+			name:                "update listner, default action changed",
+			isUpdate:            true,
+			forward:             true,
+			updateDefaultAction: true,
+			noServiceID:         false,
+		},
+
+		{
+			name:            "update listner, protocol changed",
+			isUpdate:        true,
+			replaceProtocol: true,
+			noServiceID:     false,
+		},
+
 		{
 			name:        "add listner, no service ID",
 			isUpdate:    false,
@@ -125,17 +150,39 @@ func Test_AddListener(t *testing.T) {
 
 		stack := core.NewDefaultStack(core.StackID(namespaceName))
 
-		action := latticemodel.DefaultAction{
-			Is_Import:               false,
-			BackendServiceName:      "tg-test",
-			BackendServiceNamespace: "tg-default",
+		action := latticemodel.RuleAction{
+			FixedResponseStatusCode: 404,
+		}
+		if tt.forward {
+			action = latticemodel.RuleAction{
+				TargetGroups: []*latticemodel.RuleTargetGroup{
+					{
+						Name:      "tg-test",
+						Namespace: "tg-default",
+						RouteName: namespaceName.Name,
+						Weight:    10,
+					},
+				},
+			}
+			latticeDataStore.AddTargetGroup(latticestore.TargetGroupName("tg-test", "tg-default"), "vpc", "tg-arn",
+				"tg-id", false, namespaceName.Name)
+		}
+
+		protocol := "HTTP"
+		if tt.replaceProtocol {
+			// the gateway section on port 80 is switched from HTTP to HTTPS
+			protocol = "HTTPS"
 		}
 
 		listenerResourceName := fmt.Sprintf("%s-%s-%d-%s", namespaceName.Name, namespaceName.Namespace,
-			int64(listenersummarys[0].Port), "HTTP")
+			int64(listenersummarys[0].Port), protocol)
 
-		listener := latticemodel.NewListener(stack, listenerResourceName, int64(listenersummarys[0].Port), "HTTP",
+		listener := latticemodel.NewListener(stack, listenerResourceName, int64(listenersummarys[0].Port), protocol,
 			namespaceName.Name, namespaceName.Namespace, action)
+		if tt.replaceProtocol {
+			listener.Spec.TLSMode = latticemodel.ListenerTLSModeTerminate
+			listener.Spec.CertificateARN = "cert-arn"
+		}
 
 		listenerOutput := vpclattice.CreateListenerOutput{}
 		listenerInput := vpclattice.CreateListenerInput{}
@@ -148,27 +195,84 @@ func Test_AddListener(t *testing.T) {
 		defaultAction := vpclattice.RuleAction{
 			FixedResponse: &defaultResp,
 		}
+		modelAction := defaultAction
+		if tt.forward {
+			modelAction = vpclattice.RuleAction{
+				Forward: &vpclattice.ForwardAction{
+					TargetGroups: []*vpclattice.WeightedTargetGroup{
+						{
+							TargetGroupIdentifier: aws.String("tg-id"),
+							Weight:                aws.Int64(10),
+						},
+					},
+				},
+			}
+		}
 		//listenerARN := "listener-ARN"
 		//listenerID := "listener-ID"
-		if !tt.noServiceID && !tt.isUpdate {
+		if !tt.noServiceID && (!tt.isUpdate || tt.replaceProtocol) {
 
 			listername := k8sLatticeListenerName(namespaceName.Name, namespaceName.Namespace,
-				int(listenersummarys[0].Port), listenersummarys[0].Protocol)
+				int(listenersummarys[0].Port), protocol)
 			listenerInput = vpclattice.CreateListenerInput{
-				DefaultAction:     &defaultAction,
+				DefaultAction:     &modelAction,
 				Name:              &listername,
 				ServiceIdentifier: &serviceID,
-				Protocol:          aws.String("HTTP"),
+				Protocol:          aws.String(protocol),
 				Port:              aws.Int64(listenersummarys[0].Port),
 			}
 			listenerOutput = vpclattice.CreateListenerOutput{
 				Arn:           &listenersummarys[0].Arn,
-				DefaultAction: &defaultAction,
+				DefaultAction: &modelAction,
 				Id:            &listenersummarys[0].Id,
 			}
 			mockVpcLatticeSess.EXPECT().CreateListener(&listenerInput).Return(&listenerOutput, nil)
 		}
 
+		if tt.replaceProtocol {
+			// the listener being replaced was deployed before
+			latticeDataStore.AddListener(namespaceName.Name, namespaceName.Namespace, listenersummarys[0].Port,
+				listenersummarys[0].Protocol, listenersummarys[0].Arn, listenersummarys[0].Id)
+
+			// the certificate of the HTTPS listener is set on the service
+			getServiceInput := vpclattice.GetServiceInput{
+				ServiceIdentifier: aws.String(serviceID),
+			}
+			mockVpcLatticeSess.EXPECT().GetService(&getServiceInput).Return(&vpclattice.GetServiceOutput{}, nil)
+			updateServiceInput := vpclattice.UpdateServiceInput{
+				CertificateArn:    aws.String("cert-arn"),
+				ServiceIdentifier: aws.String(serviceID),
+			}
+			mockVpcLatticeSess.EXPECT().UpdateService(&updateServiceInput).Return(&vpclattice.UpdateServiceOutput{}, nil)
+
+			listenerDeleteInput := vpclattice.DeleteListenerInput{
+				ServiceIdentifier:  aws.String(serviceID),
+				ListenerIdentifier: aws.String(listenersummarys[0].Id),
+			}
+			mockVpcLatticeSess.EXPECT().DeleteListener(&listenerDeleteInput).Return(&vpclattice.DeleteListenerOutput{}, nil)
+		}
+
+		if !tt.noServiceID && tt.isUpdate && !tt.replaceProtocol {
+			getListenerInput := vpclattice.GetListenerInput{
+				ListenerIdentifier: &listenersummarys[0].Id,
+				ServiceIdentifier:  &serviceID,
+			}
+			// the listener was created with a fixed 404 response
+			getListenerOutput := vpclattice.GetListenerOutput{
+				DefaultAction: &defaultAction,
+			}
+			mockVpcLatticeSess.EXPECT().GetListener(&getListenerInput).Return(&getListenerOutput, nil)
+
+			if tt.updateDefaultAction {
+				updateListenerInput := vpclattice.UpdateListenerInput{
+					DefaultAction:      &modelAction,
+					ListenerIdentifier: &listenersummarys[0].Id,
+					ServiceIdentifier:  &serviceID,
+				}
+				mockVpcLatticeSess.EXPECT().UpdateListener(&updateListenerInput).Return(&vpclattice.UpdateListenerOutput{}, nil)
+			}
+		}
+
 		if !tt.noServiceID {
 
 			listenerListInput := vpclattice.ListListenersInput{
@@ -195,7 +299,13 @@ func Test_AddListener(t *testing.T) {
 			assert.Equal(t, resp.Name, namespaceName.Name)
 			assert.Equal(t, resp.Namespace, namespaceName.Namespace)
 			assert.Equal(t, resp.Port, listenersummarys[0].Port)
-			assert.Equal(t, resp.Protocol, "HTTP")
+			assert.Equal(t, resp.Protocol, protocol)
+		}
+
+		if tt.replaceProtocol {
+			_, err := latticeDataStore.GetlListener(namespaceName.Name, namespaceName.Namespace,
+				listenersummarys[0].Port, listenersummarys[0].Protocol)
+			assert.Error(t, err, "the replaced listener is removed from the datastore")
 		}
 
 		fmt.Printf("listener create : resp %v, err %v, listernerOutput %v\n", resp, err, listenerOutput)
@@ -207,6 +317,55 @@ func Test_AddListener(t *testing.T) {
 
 }
 
+func Test_isListenerReplaceNeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     latticemodel.ListenerSpec
+		protocol string
+		want     bool
+	}{
+		{
+			name:     "same protocol",
+			spec:     latticemodel.ListenerSpec{Protocol: "HTTP"},
+			protocol: "HTTP",
+			want:     false,
+		},
+		{
+			name:     "protocol changed",
+			spec:     latticemodel.ListenerSpec{Protocol: "HTTPS", TLSMode: latticemodel.ListenerTLSModeTerminate},
+			protocol: "HTTP",
+			want:     true,
+		},
+		{
+			name: "same protocol and tls mode, certificate is updated in place",
+			spec: latticemodel.ListenerSpec{Protocol: "HTTPS", TLSMode: latticemodel.ListenerTLSModeTerminate,
+				CertificateARN: "cert-arn"},
+			protocol: "HTTPS",
+			want:     false,
+		},
+		{
+			name:     "tls mode changed",
+			spec:     latticemodel.ListenerSpec{Protocol: "HTTPS", TLSMode: latticemodel.ListenerTLSModePassthrough},
+			protocol: "HTTPS",
+			want:     true,
+		},
+		{
+			name: "tls passthrough",
+			spec: latticemodel.ListenerSpec{Protocol: latticemodel.ListenerProtocolTLSPassthrough,
+				TLSMode: latticemodel.ListenerTLSModePassthrough},
+			protocol: latticemodel.ListenerProtocolTLSPassthrough,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lis := &vpclattice.ListenerSummary{Protocol: aws.String(tt.protocol)}
+			assert.Equal(t, tt.want, isListenerReplaceNeeded(tt.spec, lis))
+		})
+	}
+}
+
 func Test_ListListener(t *testing.T) {
 
 	tests := []struct {
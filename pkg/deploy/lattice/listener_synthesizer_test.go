@@ -119,10 +119,13 @@ func Test_SynthesizeListener(t *testing.T) {
 			spec.IsDeleted = true
 		}
 
-		action := latticemodel.DefaultAction{
-			Is_Import:               false,
-			BackendServiceName:      "test",
-			BackendServiceNamespace: "default",
+		action := latticemodel.RuleAction{
+			TargetGroups: []*latticemodel.RuleTargetGroup{
+				{
+					Name:      "test",
+					Namespace: "default",
+				},
+			},
 		}
 
 		latticemodel.NewLatticeService(stack, "", spec)
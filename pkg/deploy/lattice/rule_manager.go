@@ -16,6 +16,16 @@ import (
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 )
 
+// lattice rule priority is between 1 and 100
+const maxRulePriority = 100
+
+// lattice target group weight in a forward action is between 0 and 999
+const maxTargetGroupWeight = 999
+
+// maxWeightMultiplier bounds the multiplier of the target group weights when service imports are split across
+// clusters, weights are scaled down to maxTargetGroupWeight afterwards anyway
+const maxWeightMultiplier = 1000000
+
 type RuleManager interface {
 	Create(ctx context.Context, rule *latticemodel.Rule) (latticemodel.RuleStatus, error)
 	Delete(ctx context.Context, ruleID string, listenerID string, serviceID string) error
@@ -85,17 +95,37 @@ func (r *defaultRuleManager) List(ctx context.Context, service string, listener
 	return sdkRules, nil
 }
 
-// today, it only batch update the priority
+// today, it only batch update the priority. Rules of a route can be on several listeners,
+// so they are batch updated per listener
 func (r *defaultRuleManager) Update(ctx context.Context, rules []*latticemodel.Rule) error {
+	glog.V(6).Infof("Rule --- update >>>>>>>>.%v\n", rules)
 
-	var ruleUpdateList []*vpclattice.RuleUpdate
+	var listenerKeys []string
+	rulesByListener := make(map[string][]*latticemodel.Rule)
+	for _, rule := range rules {
+		key := fmt.Sprintf("%d-%s", rule.Spec.ListenerPort, rule.Spec.ListenerProtocol)
+		if _, ok := rulesByListener[key]; !ok {
+			listenerKeys = append(listenerKeys, key)
+		}
+		rulesByListener[key] = append(rulesByListener[key], rule)
+	}
 
-	glog.V(6).Infof("Rule --- update >>>>>>>>.%v\n", rules)
+	for _, key := range listenerKeys {
+		if err := r.updateListenerRules(ctx, rulesByListener[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateListenerRules batch updates the priority of rules which all belong to the same listener
+func (r *defaultRuleManager) updateListenerRules(ctx context.Context, rules []*latticemodel.Rule) error {
+	var ruleUpdateList []*vpclattice.RuleUpdate
 
 	latticeService, err := r.latticeDataStore.GetLatticeService(rules[0].Spec.ServiceName, rules[0].Spec.ServiceNamespace)
 
 	if err != nil {
-		errmsg := fmt.Sprintf("Service %v not found during rule creation", rules[0].Spec)
+		errmsg := fmt.Sprintf("Service %v not found during rule update", rules[0].Spec)
 		glog.V(2).Infof("Error during update rule %s \n", errmsg)
 		return errors.New(errmsg)
 	}
@@ -104,7 +134,7 @@ func (r *defaultRuleManager) Update(ctx context.Context, rules []*latticemodel.R
 		rules[0].Spec.ListenerPort, rules[0].Spec.ListenerProtocol)
 
 	if err != nil {
-		errmsg := fmt.Sprintf("Listener %v not found during rule creation", rules[0].Spec)
+		errmsg := fmt.Sprintf("Listener %v not found during rule update", rules[0].Spec)
 		glog.V(2).Infof("Error during update rule %s \n", errmsg)
 		return errors.New(errmsg)
 	}
@@ -176,27 +206,18 @@ func (r *defaultRuleManager) Create(ctx context.Context, rule *latticemodel.Rule
 		return ruleStatus, nil
 	}
 
-	// if not found, ruleStatus contains the next available priority
-
-	latticeTGs := []*vpclattice.WeightedTargetGroup{}
-
-	for _, tgRule := range rule.Spec.Action.TargetGroups {
-
-		tgName := latticestore.TargetGroupName(tgRule.Name, tgRule.Namespace)
-		tg, err := r.latticeDataStore.GetTargetGroup(tgName, tgRule.RouteName, tgRule.IsServiceImport)
-
-		if err != nil {
-			glog.V(2).Infof("Faild to create rule due to unknown tg %v, err %v\n", tgName, err)
-			return latticemodel.RuleStatus{}, err
-		}
-
-		latticeTG := vpclattice.WeightedTargetGroup{
-			TargetGroupIdentifier: aws.String(tg.ID),
-			Weight:                aws.Int64(tgRule.Weight),
-		}
-
-		latticeTGs = append(latticeTGs, &latticeTG)
+	// if not found, ruleStatus contains the next available priority, the rule is moved to its own priority by
+	// a batch update afterwards so that it does not stay behind less specific rules
+	if err != nil && ruleStatus.Priority != priority {
+		glog.V(6).Infof("Rule-Create: created at priority %d instead of %d, need to BatchUpdate priority\n",
+			ruleStatus.Priority, priority)
+		ruleStatus.UpdatePriorityNeeded = true
+	}
 
+	latticeTGs, err := buildLatticeTGs(r.latticeDataStore, rule.Spec.Action.TargetGroups)
+	if err != nil {
+		glog.V(2).Infof("Faild to create rule %v due to unknown tg, err %v\n", rule.Spec.RuleID, err)
+		return latticemodel.RuleStatus{}, err
 	}
 
 	ruleName := fmt.Sprintf("k8s-%d-%s", rule.Spec.CreateTime.Unix(), rule.Spec.RuleID)
@@ -207,11 +228,7 @@ func (r *defaultRuleManager) Create(ctx context.Context, rule *latticemodel.Rule
 		updateSDKhttpMatch(&httpMatch, rule)
 
 		updateRuleInput := vpclattice.UpdateRuleInput{
-			Action: &vpclattice.RuleAction{
-				Forward: &vpclattice.ForwardAction{
-					TargetGroups: latticeTGs,
-				},
-			},
+			Action:             buildSDKRuleAction(&rule.Spec.Action, latticeTGs),
 			ListenerIdentifier: aws.String(listener.ID),
 			Match: &vpclattice.RuleMatch{
 				HttpMatch: &httpMatch,
@@ -241,11 +258,7 @@ func (r *defaultRuleManager) Create(ctx context.Context, rule *latticemodel.Rule
 		updateSDKhttpMatch(&httpMatch, rule)
 
 		ruleInput := vpclattice.CreateRuleInput{
-			Action: &vpclattice.RuleAction{
-				Forward: &vpclattice.ForwardAction{
-					TargetGroups: latticeTGs,
-				},
-			},
+			Action:             buildSDKRuleAction(&rule.Spec.Action, latticeTGs),
 			ClientToken:        nil,
 			ListenerIdentifier: aws.String(listener.ID),
 			Match: &vpclattice.RuleMatch{
@@ -277,6 +290,160 @@ func (r *defaultRuleManager) Create(ctx context.Context, rule *latticemodel.Rule
 
 }
 
+// buildLatticeTGs resolves the target groups of a model action to their lattice ids. A service import fans out to
+// the target group of each exporting cluster, the weight of the backend is split by the weights of the clusters
+func buildLatticeTGs(store *latticestore.LatticeDataStore, ruleTGs []*latticemodel.RuleTargetGroup) ([]*vpclattice.WeightedTargetGroup, error) {
+	latticeTGs := []*vpclattice.WeightedTargetGroup{}
+
+	tgs := make([]latticestore.TargetGroup, 0, len(ruleTGs))
+	// the weights of all target groups are multiplied so that the cluster weights split the backend weights exactly
+	multiplier := int64(1)
+	capped := false
+	for _, tgRule := range ruleTGs {
+		tgName := ruleTargetGroupName(tgRule)
+		tg, err := store.GetTargetGroup(tgName, tgRule.RouteName, tgRule.IsServiceImport)
+		if err != nil {
+			glog.V(2).Infof("Unknown tg %v, err %v\n", tgName, err)
+			return nil, err
+		}
+		tgs = append(tgs, tg)
+
+		if _, total := reduceClusterWeights(tg.Clusters); total > 0 && !capped {
+			multiplier = lcm(multiplier, total)
+			if multiplier > maxWeightMultiplier {
+				// too many distinct splits to be exact, the weights are rounded instead
+				glog.V(2).Infof("Cluster weights of tg %v cannot be split exactly, the weights are rounded\n", tgName)
+				multiplier = maxWeightMultiplier
+				capped = true
+			}
+		}
+	}
+
+	for i, tgRule := range ruleTGs {
+		tg := tgs[i]
+		if len(tg.Clusters) == 0 {
+			latticeTGs = append(latticeTGs, &vpclattice.WeightedTargetGroup{
+				TargetGroupIdentifier: aws.String(tg.ID),
+				Weight:                aws.Int64(tgRule.Weight * multiplier),
+			})
+			continue
+		}
+
+		weights, total := reduceClusterWeights(tg.Clusters)
+		for j, cluster := range tg.Clusters {
+			if weights[j] == 0 {
+				// the cluster takes no traffic, e.g. while it is drained
+				continue
+			}
+			weight := (tgRule.Weight*multiplier*weights[j] + total/2) / total
+			if weight == 0 && tgRule.Weight > 0 {
+				// rounded, the cluster still takes some traffic
+				weight = 1
+			}
+			latticeTGs = append(latticeTGs, &vpclattice.WeightedTargetGroup{
+				TargetGroupIdentifier: aws.String(cluster.ID),
+				Weight:                aws.Int64(weight),
+			})
+		}
+	}
+
+	if len(latticeTGs) == 0 && len(ruleTGs) > 0 {
+		// every cluster of the service imports has weight 0
+		return nil, errors.New("no target group with a cluster of non zero weight")
+	}
+	if multiplier > 1 {
+		normalizeWeights(latticeTGs)
+	}
+	return latticeTGs, nil
+}
+
+// reduceClusterWeights returns the cluster weights divided by their greatest common divisor, and their sum.
+// The sum is 0 when all clusters have weight 0
+func reduceClusterWeights(clusters []latticestore.ClusterTargetGroup) ([]int64, int64) {
+	divisor := int64(0)
+	for _, cluster := range clusters {
+		divisor = gcd(divisor, cluster.Weight)
+	}
+
+	weights := make([]int64, 0, len(clusters))
+	total := int64(0)
+	for _, cluster := range clusters {
+		weight := int64(0)
+		if divisor > 0 {
+			weight = cluster.Weight / divisor
+		}
+		weights = append(weights, weight)
+		total += weight
+	}
+	return weights, total
+}
+
+// normalizeWeights divides the weights by their greatest common divisor, then scales them down to the lattice
+// maximum if needed. A non zero weight stays non zero
+func normalizeWeights(latticeTGs []*vpclattice.WeightedTargetGroup) {
+	divisor := int64(0)
+	maxWeight := int64(0)
+	for _, tg := range latticeTGs {
+		divisor = gcd(divisor, aws.Int64Value(tg.Weight))
+	}
+	if divisor == 0 {
+		return
+	}
+	for _, tg := range latticeTGs {
+		tg.Weight = aws.Int64(aws.Int64Value(tg.Weight) / divisor)
+		if aws.Int64Value(tg.Weight) > maxWeight {
+			maxWeight = aws.Int64Value(tg.Weight)
+		}
+	}
+	if maxWeight <= maxTargetGroupWeight {
+		return
+	}
+	for _, tg := range latticeTGs {
+		weight := aws.Int64Value(tg.Weight)
+		scaled := weight * maxTargetGroupWeight / maxWeight
+		if scaled == 0 && weight > 0 {
+			scaled = 1
+		}
+		tg.Weight = aws.Int64(scaled)
+	}
+}
+
+func gcd(a int64, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a int64, b int64) int64 {
+	return a / gcd(a, b) * b
+}
+
+// ruleTargetGroupName returns the datastore name of a target group of a rule
+func ruleTargetGroupName(tgRule *latticemodel.RuleTargetGroup) string {
+	if tgRule.IsLambda {
+		return latticestore.LambdaTargetGroupName(tgRule.Name, tgRule.Namespace)
+	}
+	return latticestore.TargetGroupPortName(tgRule.Name, tgRule.Namespace, tgRule.Port)
+}
+
+// buildSDKRuleAction is shared by rules and listener default actions
+func buildSDKRuleAction(action *latticemodel.RuleAction, latticeTGs []*vpclattice.WeightedTargetGroup) *vpclattice.RuleAction {
+	if action.FixedResponseStatusCode != 0 {
+		return &vpclattice.RuleAction{
+			FixedResponse: &vpclattice.FixedResponseAction{
+				StatusCode: aws.Int64(action.FixedResponseStatusCode),
+			},
+		}
+	}
+
+	return &vpclattice.RuleAction{
+		Forward: &vpclattice.ForwardAction{
+			TargetGroups: latticeTGs,
+		},
+	}
+}
+
 func updateSDKhttpMatch(httpMatch *vpclattice.HttpMatch, rule *latticemodel.Rule) {
 	glog.V(6).Infof("Setting sdk HttpMatch using rule.Spec %v", rule.Spec)
 
@@ -295,12 +462,17 @@ func updateSDKhttpMatch(httpMatch *vpclattice.HttpMatch, rule *latticemodel.Rule
 		}
 	}
 
+	if rule.Spec.Method != "" {
+		httpMatch.Method = aws.String(rule.Spec.Method)
+	}
+
 	if rule.Spec.NumOfHeaderMatches > 0 {
 
 		for i := 0; i < rule.Spec.NumOfHeaderMatches; i++ {
 			headerMatch := vpclattice.HeaderMatch{
-				Match: rule.Spec.MatchedHeaders[i].Match,
-				Name:  rule.Spec.MatchedHeaders[i].Name,
+				Match:         rule.Spec.MatchedHeaders[i].Match,
+				Name:          rule.Spec.MatchedHeaders[i].Name,
+				CaseSensitive: rule.Spec.MatchedHeaders[i].CaseSensitive,
 			}
 			httpMatch.HeaderMatches = append(httpMatch.HeaderMatches, &headerMatch)
 
@@ -360,6 +532,13 @@ func isRulesSame(modelRule *latticemodel.Rule, sdkRuleDetail *vpclattice.GetRule
 		}
 	}
 
+	// Method Match
+	if modelRule.Spec.Method != aws.StringValue(sdkRuleDetail.Match.HttpMatch.Method) {
+		glog.V(6).Infof("Method mismatch, k8s %v lattice %v\n",
+			modelRule.Spec.Method, aws.StringValue(sdkRuleDetail.Match.HttpMatch.Method))
+		return false
+	}
+
 	// Header Match
 
 	if modelRule.Spec.NumOfHeaderMatches > 0 {
@@ -378,10 +557,7 @@ func isRulesSame(modelRule *latticemodel.Rule, sdkRuleDetail *vpclattice.GetRule
 			// check if this is in module
 			for i := 0; i < modelRule.Spec.NumOfHeaderMatches; i++ {
 				// compare header
-				if aws.StringValue(modelRule.Spec.MatchedHeaders[i].Name) ==
-					aws.StringValue(sdkHeader.Name) &&
-					aws.StringValue(modelRule.Spec.MatchedHeaders[i].Match.Exact) ==
-						aws.StringValue(sdkHeader.Match.Exact) {
+				if isHeaderMatchSame(&modelRule.Spec.MatchedHeaders[i], sdkHeader) {
 					matchFound = true
 					break
 				}
@@ -409,12 +585,7 @@ func isRulesSame(modelRule *latticemodel.Rule, sdkRuleDetail *vpclattice.GetRule
 func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *latticemodel.Rule,
 	serviceID string, listenerID string) (latticemodel.RuleStatus, error) {
 
-	var priorityMap [100]bool
-
-	for i := 1; i < 100; i++ {
-		priorityMap[i] = false
-
-	}
+	var priorityMap [maxRulePriority + 1]bool
 
 	ruleListInput := vpclattice.ListRulesInput{
 		ListenerIdentifier: aws.String(listenerID),
@@ -460,7 +631,9 @@ func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *lattice
 			continue
 		}
 
-		priorityMap[aws.Int64Value(ruleResp.Priority)] = true
+		if rulePriority := aws.Int64Value(ruleResp.Priority); rulePriority > 0 && rulePriority <= maxRulePriority {
+			priorityMap[rulePriority] = true
+		}
 
 		samerule := isRulesSame(rule, ruleResp)
 
@@ -470,6 +643,27 @@ func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *lattice
 
 		matchRule = ruleResp
 
+		if rule.Spec.Action.FixedResponseStatusCode != 0 || ruleResp.Action.FixedResponse != nil {
+			if ruleResp.Action.FixedResponse == nil ||
+				aws.Int64Value(ruleResp.Action.FixedResponse.StatusCode) != rule.Spec.Action.FixedResponseStatusCode {
+				glog.V(6).Infof("Mismatched fixed response lattice %v, k8s %v\n",
+					ruleResp.Action.FixedResponse, rule.Spec.Action.FixedResponseStatusCode)
+				updateTGsNeeded = true
+			}
+			continue
+		}
+
+		if hasServiceImportTG(rule.Spec.Action.TargetGroups) {
+			// service imports fan out to the target groups of the exporting clusters, compare the resulting action
+			latticeTGs, err := buildLatticeTGs(r.latticeDataStore, rule.Spec.Action.TargetGroups)
+			if err != nil || !isSDKRuleActionSame(buildSDKRuleAction(&rule.Spec.Action, latticeTGs), ruleResp.Action) {
+				glog.V(6).Infof("Mismatched TGs lattice %v, k8s %v, err %v\n",
+					ruleResp.Action.Forward.TargetGroups, latticeTGs, err)
+				updateTGsNeeded = true
+			}
+			continue
+		}
+
 		if len(ruleResp.Action.Forward.TargetGroups) != len(rule.Spec.Action.TargetGroups) {
 			glog.V(6).Infof("Mismatched TGs lattice %v, k8s %v\n",
 				ruleResp.Action.Forward.TargetGroups, rule.Spec.Action.TargetGroups)
@@ -486,7 +680,7 @@ func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *lattice
 
 			for _, k8sTG := range rule.Spec.Action.TargetGroups {
 				// get k8sTG id
-				tgName := latticestore.TargetGroupName(k8sTG.Name, k8sTG.Namespace)
+				tgName := ruleTargetGroupName(k8sTG)
 				k8sTGinStore, err := r.latticeDataStore.GetTargetGroup(tgName, rule.Spec.ServiceName, k8sTG.IsServiceImport)
 
 				if err != nil {
@@ -543,7 +737,7 @@ func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *lattice
 	} else {
 		var nextPriority int64 = 0
 		// find available priority
-		for i := 1; i < 100; i++ {
+		for i := 1; i <= maxRulePriority; i++ {
 			if !priorityMap[i] {
 				nextPriority = int64(i)
 				break
@@ -555,12 +749,18 @@ func (r *defaultRuleManager) findMatchingRule(ctx context.Context, rule *lattice
 
 }
 
+// ruleID2Priority maps the model rule id rule-<n>-<port>-<protocol> to lattice priority n, n is assigned
+// per listener by the model builder following the gateway api match precedence
 func ruleID2Priority(ruleID string) (int64, error) {
 
 	var priority int
 	ruleIDName := strings.NewReader(ruleID)
 	_, err := fmt.Fscanf(ruleIDName, "rule-%d", &priority)
 
+	if err == nil && (priority < 1 || priority > maxRulePriority) {
+		err = fmt.Errorf("rule priority %d of %s is out of range", priority, ruleID)
+	}
+
 	return int64(priority), err
 }
 
@@ -579,3 +779,73 @@ func (r *defaultRuleManager) Delete(ctx context.Context, ruleID string, listener
 
 	return err
 }
+
+func hasServiceImportTG(ruleTGs []*latticemodel.RuleTargetGroup) bool {
+	for _, tgRule := range ruleTGs {
+		if tgRule.IsServiceImport {
+			return true
+		}
+	}
+	return false
+}
+
+// isSDKRuleActionSame compares two lattice actions, the order of forward target groups does not matter
+func isSDKRuleActionSame(a *vpclattice.RuleAction, b *vpclattice.RuleAction) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.FixedResponse != nil || b.FixedResponse != nil {
+		return a.FixedResponse != nil && b.FixedResponse != nil &&
+			aws.Int64Value(a.FixedResponse.StatusCode) == aws.Int64Value(b.FixedResponse.StatusCode)
+	}
+
+	if a.Forward == nil || b.Forward == nil {
+		return a.Forward == b.Forward
+	}
+
+	if len(a.Forward.TargetGroups) != len(b.Forward.TargetGroups) {
+		return false
+	}
+
+	for _, aTG := range a.Forward.TargetGroups {
+		found := false
+		for _, bTG := range b.Forward.TargetGroups {
+			if aws.StringValue(aTG.TargetGroupIdentifier) == aws.StringValue(bTG.TargetGroupIdentifier) &&
+				aws.Int64Value(aTG.Weight) == aws.Int64Value(bTG.Weight) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isHeaderMatchSame(modelHeader *vpclattice.HeaderMatch, sdkHeader *vpclattice.HeaderMatch) bool {
+	if aws.StringValue(modelHeader.Name) != aws.StringValue(sdkHeader.Name) {
+		return false
+	}
+
+	// an unset case sensitivity is not the same as an explicit one
+	if (modelHeader.CaseSensitive == nil) != (sdkHeader.CaseSensitive == nil) ||
+		aws.BoolValue(modelHeader.CaseSensitive) != aws.BoolValue(sdkHeader.CaseSensitive) {
+		return false
+	}
+
+	modelMatch := modelHeader.Match
+	if modelMatch == nil {
+		modelMatch = &vpclattice.HeaderMatchType{}
+	}
+	sdkMatch := sdkHeader.Match
+	if sdkMatch == nil {
+		sdkMatch = &vpclattice.HeaderMatchType{}
+	}
+
+	return aws.StringValue(modelMatch.Exact) == aws.StringValue(sdkMatch.Exact) &&
+		aws.StringValue(modelMatch.Prefix) == aws.StringValue(sdkMatch.Prefix) &&
+		aws.StringValue(modelMatch.Contains) == aws.StringValue(sdkMatch.Contains)
+}
@@ -2,6 +2,7 @@ package lattice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -705,6 +706,7 @@ func Test_CreateRule(t *testing.T) {
 			assert.Equal(t, resp.ListenerID, ListenerID)
 			assert.Equal(t, resp.ServiceID, ServiceID)
 			assert.Equal(t, resp.RuleID, ruleID)
+			assert.Equal(t, tt.updatePriorityNeeded, resp.UpdatePriorityNeeded)
 		}
 
 		fmt.Printf(" rulemanager.Create :%v, err %d\n", resp, err)
@@ -799,6 +801,63 @@ func Test_UpdateRule(t *testing.T) {
 
 }
 
+// rules on different listeners are batch updated per listener
+func Test_UpdateRule_MultipleListeners(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+	latticeDataStore := latticestore.NewLatticeDataStore()
+	ruleManager := NewRuleManager(mockCloud, latticeDataStore)
+
+	newRule := func(port int64, protocol string, ruleID string, latticeRuleID string) *latticemodel.Rule {
+		return &latticemodel.Rule{
+			Spec: latticemodel.RuleSpec{
+				ServiceName:      "svc-1",
+				ServiceNamespace: "default",
+				ListenerPort:     port,
+				ListenerProtocol: protocol,
+				RuleID:           ruleID,
+			},
+			Status: &latticemodel.RuleStatus{
+				RuleID: latticeRuleID,
+			},
+		}
+	}
+	listenerRules := []*latticemodel.Rule{
+		newRule(80, "HTTP", "rule-1-80-http", "http-rule-1"),
+		newRule(443, "HTTPS", "rule-1-443-https", "https-rule-1"),
+		newRule(80, "HTTP", "rule-2-80-http", "http-rule-2"),
+	}
+
+	latticeDataStore.AddLatticeService("svc-1", "default", "serviceARN", "serviceID1", "test-dns")
+	latticeDataStore.AddListener("svc-1", "default", 80, "HTTP", "listenerARN1", "listenerID1")
+	latticeDataStore.AddListener("svc-1", "default", 443, "HTTPS", "listenerARN2", "listenerID2")
+
+	mockVpcLatticeSess.EXPECT().BatchUpdateRule(&vpclattice.BatchUpdateRuleInput{
+		ListenerIdentifier: aws.String("listenerID1"),
+		ServiceIdentifier:  aws.String("serviceID1"),
+		Rules: []*vpclattice.RuleUpdate{
+			{RuleIdentifier: aws.String("http-rule-1"), Priority: aws.Int64(1)},
+			{RuleIdentifier: aws.String("http-rule-2"), Priority: aws.Int64(2)},
+		},
+	}).Return(&vpclattice.BatchUpdateRuleOutput{}, nil)
+	mockVpcLatticeSess.EXPECT().BatchUpdateRule(&vpclattice.BatchUpdateRuleInput{
+		ListenerIdentifier: aws.String("listenerID2"),
+		ServiceIdentifier:  aws.String("serviceID1"),
+		Rules: []*vpclattice.RuleUpdate{
+			{RuleIdentifier: aws.String("https-rule-1"), Priority: aws.Int64(1)},
+		},
+	}).Return(nil, errors.New("ConflictException"))
+
+	err := ruleManager.Update(ctx, listenerRules)
+	assert.NotNil(t, err)
+}
+
 func Test_List(t *testing.T) {
 	c := gomock.NewController(t)
 	defer c.Finish()
@@ -1483,6 +1542,209 @@ func Test_isRulesSame(t *testing.T) {
 			},
 			ruleMatched: false,
 		},
+		{
+			name: "Method + PathPrefix Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					PathMatchPrefix: true,
+					PathMatchValue:  path1,
+					Method:          "GET",
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						Method: aws.String("GET"),
+						PathMatch: &vpclattice.PathMatch{
+							Match: &vpclattice.PathMatchType{
+								Prefix: &path1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: true,
+		},
+		{
+			name: "Method changed -- mis Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					PathMatchPrefix: true,
+					PathMatchValue:  path1,
+					Method:          "POST",
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						Method: aws.String("GET"),
+						PathMatch: &vpclattice.PathMatch{
+							Match: &vpclattice.PathMatchType{
+								Prefix: &path1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: false,
+		},
+		{
+			name: "Method removed -- mis Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					PathMatchPrefix: true,
+					PathMatchValue:  path1,
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						Method: aws.String("GET"),
+						PathMatch: &vpclattice.PathMatch{
+							Match: &vpclattice.PathMatchType{
+								Prefix: &path1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: false,
+		},
+		{
+			name: "Header prefix Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					NumOfHeaderMatches: 1,
+					MatchedHeaders: [5]vpclattice.HeaderMatch{
+						{
+							Match: &vpclattice.HeaderMatchType{
+								Prefix: &hdr1Value},
+							Name: &hdr1,
+						},
+						{},
+						{},
+						{},
+						{},
+					},
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						HeaderMatches: []*vpclattice.HeaderMatch{
+							{
+								Match: &vpclattice.HeaderMatchType{
+									Prefix: &hdr1Value},
+								Name: &hdr1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: true,
+		},
+		{
+			name: "Header prefix changed to contains -- mis Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					NumOfHeaderMatches: 1,
+					MatchedHeaders: [5]vpclattice.HeaderMatch{
+						{
+							Match: &vpclattice.HeaderMatchType{
+								Contains: &hdr1Value},
+							Name: &hdr1,
+						},
+						{},
+						{},
+						{},
+						{},
+					},
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						HeaderMatches: []*vpclattice.HeaderMatch{
+							{
+								Match: &vpclattice.HeaderMatchType{
+									Prefix: &hdr1Value},
+								Name: &hdr1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: false,
+		},
+		{
+			name: "Header case sensitivity changed -- mis Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					NumOfHeaderMatches: 1,
+					MatchedHeaders: [5]vpclattice.HeaderMatch{
+						{
+							Match: &vpclattice.HeaderMatchType{
+								Contains: &hdr1Value},
+							CaseSensitive: aws.Bool(true),
+							Name:          &hdr1,
+						},
+						{},
+						{},
+						{},
+						{},
+					},
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						HeaderMatches: []*vpclattice.HeaderMatch{
+							{
+								Match: &vpclattice.HeaderMatchType{
+									Contains: &hdr1Value},
+								CaseSensitive: aws.Bool(false),
+								Name:          &hdr1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: false,
+		},
+		{
+			name: "Header case sensitivity unset vs false -- mis Match",
+			k8sRule: &latticemodel.Rule{
+				Spec: latticemodel.RuleSpec{
+					NumOfHeaderMatches: 1,
+					MatchedHeaders: [5]vpclattice.HeaderMatch{
+						{
+							Match: &vpclattice.HeaderMatchType{
+								Contains: &hdr1Value},
+							Name: &hdr1,
+						},
+						{},
+						{},
+						{},
+						{},
+					},
+				},
+			},
+			sdkRule: &vpclattice.GetRuleOutput{
+				Match: &vpclattice.RuleMatch{
+					HttpMatch: &vpclattice.HttpMatch{
+						HeaderMatches: []*vpclattice.HeaderMatch{
+							{
+								Match: &vpclattice.HeaderMatchType{
+									Contains: &hdr1Value},
+								CaseSensitive: aws.Bool(false),
+								Name:          &hdr1,
+							},
+						},
+					},
+				},
+			},
+			ruleMatched: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1499,3 +1761,258 @@ func Test_isRulesSame(t *testing.T) {
 	}
 
 }
+
+func Test_buildSDKRuleAction(t *testing.T) {
+	latticeTGs := []*vpclattice.WeightedTargetGroup{
+		{
+			TargetGroupIdentifier: aws.String("tg-id"),
+			Weight:                aws.Int64(10),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		action       latticemodel.RuleAction
+		expectAction *vpclattice.RuleAction
+	}{
+		{
+			name: "forward",
+			action: latticemodel.RuleAction{
+				TargetGroups: []*latticemodel.RuleTargetGroup{{Name: "tg", Weight: 10}},
+			},
+			expectAction: &vpclattice.RuleAction{
+				Forward: &vpclattice.ForwardAction{
+					TargetGroups: latticeTGs,
+				},
+			},
+		},
+		{
+			name: "fixed response",
+			action: latticemodel.RuleAction{
+				FixedResponseStatusCode: 503,
+			},
+			expectAction: &vpclattice.RuleAction{
+				FixedResponse: &vpclattice.FixedResponseAction{
+					StatusCode: aws.Int64(503),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectAction, buildSDKRuleAction(&tt.action, latticeTGs))
+		})
+	}
+}
+
+func Test_isSDKRuleActionSame(t *testing.T) {
+	forward := func(tgs ...*vpclattice.WeightedTargetGroup) *vpclattice.RuleAction {
+		return &vpclattice.RuleAction{
+			Forward: &vpclattice.ForwardAction{
+				TargetGroups: tgs,
+			},
+		}
+	}
+	fixedResponse := func(statusCode int64) *vpclattice.RuleAction {
+		return &vpclattice.RuleAction{
+			FixedResponse: &vpclattice.FixedResponseAction{
+				StatusCode: aws.Int64(statusCode),
+			},
+		}
+	}
+	tg1 := &vpclattice.WeightedTargetGroup{TargetGroupIdentifier: aws.String("tg-1"), Weight: aws.Int64(10)}
+	tg2 := &vpclattice.WeightedTargetGroup{TargetGroupIdentifier: aws.String("tg-2"), Weight: aws.Int64(90)}
+	tg2Weight := &vpclattice.WeightedTargetGroup{TargetGroupIdentifier: aws.String("tg-2"), Weight: aws.Int64(50)}
+
+	tests := []struct {
+		name   string
+		a      *vpclattice.RuleAction
+		b      *vpclattice.RuleAction
+		expect bool
+	}{
+		{
+			name:   "same forward, different order",
+			a:      forward(tg1, tg2),
+			b:      forward(tg2, tg1),
+			expect: true,
+		},
+		{
+			name:   "forward weight changed",
+			a:      forward(tg1, tg2),
+			b:      forward(tg1, tg2Weight),
+			expect: false,
+		},
+		{
+			name:   "forward target group removed",
+			a:      forward(tg1, tg2),
+			b:      forward(tg1),
+			expect: false,
+		},
+		{
+			name:   "same fixed response",
+			a:      fixedResponse(404),
+			b:      fixedResponse(404),
+			expect: true,
+		},
+		{
+			name:   "fixed response status code changed",
+			a:      fixedResponse(404),
+			b:      fixedResponse(503),
+			expect: false,
+		},
+		{
+			name:   "fixed response to forward",
+			a:      forward(tg1),
+			b:      fixedResponse(404),
+			expect: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, isSDKRuleActionSame(tt.a, tt.b))
+		})
+	}
+}
+
+func Test_ruleTargetGroupName(t *testing.T) {
+	ruleTG := &latticemodel.RuleTargetGroup{
+		Name:      "name",
+		Namespace: "ns",
+		Port:      8080,
+	}
+	assert.Equal(t, latticestore.TargetGroupPortName("name", "ns", 8080), ruleTargetGroupName(ruleTG))
+
+	ruleTG.IsLambda = true
+	assert.Equal(t, latticestore.LambdaTargetGroupName("name", "ns"), ruleTargetGroupName(ruleTG))
+}
+
+func Test_buildLatticeTGs_ServiceImportClusters(t *testing.T) {
+	clusters := func(weights ...int64) []latticestore.ClusterTargetGroup {
+		var clusters []latticestore.ClusterTargetGroup
+		for i, weight := range weights {
+			clusters = append(clusters, latticestore.ClusterTargetGroup{
+				VpcID:  fmt.Sprintf("vpc-%d", i+1),
+				ID:     fmt.Sprintf("import-tg-%d", i+1),
+				Weight: weight,
+			})
+		}
+		return clusters
+	}
+
+	tests := []struct {
+		name          string
+		backendWeight int64
+		importWeight  int64
+		clusters      []latticestore.ClusterTargetGroup
+		expectWeights map[string]int64
+	}{
+		{
+			name:          "single cluster keeps the backend weights",
+			backendWeight: 90,
+			importWeight:  10,
+			clusters:      clusters(1),
+			expectWeights: map[string]int64{"backend-tg": 90, "import-tg-1": 10},
+		},
+		{
+			name:          "single cluster with a weight keeps the backend weights",
+			backendWeight: 90,
+			importWeight:  10,
+			clusters:      clusters(80),
+			expectWeights: map[string]int64{"backend-tg": 90, "import-tg-1": 10},
+		},
+		{
+			name:          "import split by cluster weights",
+			backendWeight: 50,
+			importWeight:  50,
+			clusters:      clusters(80, 20),
+			expectWeights: map[string]int64{"backend-tg": 5, "import-tg-1": 4, "import-tg-2": 1},
+		},
+		{
+			name:          "import split evenly by default weights",
+			backendWeight: 1,
+			importWeight:  1,
+			clusters:      clusters(1, 1, 1),
+			expectWeights: map[string]int64{"backend-tg": 3, "import-tg-1": 1, "import-tg-2": 1, "import-tg-3": 1},
+		},
+		{
+			name:          "cluster of weight 0 is left out",
+			backendWeight: 1,
+			importWeight:  1,
+			clusters:      clusters(1, 0),
+			expectWeights: map[string]int64{"backend-tg": 1, "import-tg-1": 1},
+		},
+		{
+			name:          "all clusters of weight 0 are left out",
+			backendWeight: 1,
+			importWeight:  1,
+			clusters:      clusters(0, 0),
+			expectWeights: map[string]int64{"backend-tg": 1},
+		},
+		{
+			name:          "split rounded once the multiplier is capped, a cluster with a weight keeps traffic",
+			backendWeight: 1,
+			importWeight:  1,
+			clusters:      clusters(1000000, 1),
+			expectWeights: map[string]int64{"backend-tg": 999, "import-tg-1": 998, "import-tg-2": 1},
+		},
+		{
+			name:          "weights scaled down to the lattice maximum",
+			backendWeight: 999,
+			importWeight:  1,
+			clusters:      clusters(1, 1),
+			expectWeights: map[string]int64{"backend-tg": 999, "import-tg-1": 1, "import-tg-2": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := latticestore.NewLatticeDataStore()
+			backendTG := &latticemodel.RuleTargetGroup{
+				Name:      "backend",
+				Namespace: "default",
+				RouteName: "route",
+				Weight:    tt.backendWeight,
+			}
+			importTG := &latticemodel.RuleTargetGroup{
+				Name:            "import",
+				Namespace:       "default",
+				IsServiceImport: true,
+				Weight:          tt.importWeight,
+			}
+			ds.AddTargetGroup(ruleTargetGroupName(backendTG), "vpc-1", "backend-arn", "backend-tg", false, "route")
+			ds.AddTargetGroup(ruleTargetGroupName(importTG), "", tt.clusters[0].ARN, tt.clusters[0].ID, true, "")
+			ds.SetTargetGroupClusters(ruleTargetGroupName(importTG), "", true, tt.clusters)
+
+			latticeTGs, err := buildLatticeTGs(ds, []*latticemodel.RuleTargetGroup{backendTG, importTG})
+
+			assert.Nil(t, err)
+			weights := make(map[string]int64)
+			for _, tg := range latticeTGs {
+				weights[aws.StringValue(tg.TargetGroupIdentifier)] = aws.Int64Value(tg.Weight)
+			}
+			assert.Equal(t, tt.expectWeights, weights)
+		})
+	}
+}
+
+func Test_buildLatticeTGs_ServiceImportClustersAllDrained(t *testing.T) {
+	ds := latticestore.NewLatticeDataStore()
+	importTG := &latticemodel.RuleTargetGroup{
+		Name:            "import",
+		Namespace:       "default",
+		IsServiceImport: true,
+		Weight:          1,
+	}
+	ds.AddTargetGroup(ruleTargetGroupName(importTG), "", "import-arn-1", "import-tg-1", true, "")
+	ds.SetTargetGroupClusters(ruleTargetGroupName(importTG), "", true, []latticestore.ClusterTargetGroup{
+		{VpcID: "vpc-1", ARN: "import-arn-1", ID: "import-tg-1", Weight: 0},
+		{VpcID: "vpc-2", ARN: "import-arn-2", ID: "import-tg-2", Weight: 0},
+	})
+
+	latticeTGs, err := buildLatticeTGs(ds, []*latticemodel.RuleTargetGroup{importTG})
+
+	assert.NotNil(t, err)
+	assert.Nil(t, latticeTGs)
+}
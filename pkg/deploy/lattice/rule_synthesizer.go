@@ -64,9 +64,12 @@ func (r *ruleSynthesizer) Synthesize(ctx context.Context) error {
 	}
 
 	if updatePriority {
-		//r.rule.
 		err := r.rule.Update(ctx, resRule)
 		glog.V(6).Infof("rule --synthesie update rule priority err: %v\n", err)
+		if err != nil {
+			// the route is requeued, otherwise the rules stay at the wrong priority
+			return err
+		}
 	}
 
 	return nil
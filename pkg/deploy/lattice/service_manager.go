@@ -92,23 +92,7 @@ func (s *defaultServiceManager) Create(ctx context.Context, service *latticemode
 		if serviceSummary.DnsEntry != nil {
 			serviceDNS = aws.StringValue(serviceSummary.DnsEntry.DomainName)
 		}
-
-		if len(service.Spec.CustomerCertARN) > 0 {
-			serviceUpdateInput := vpclattice.UpdateServiceInput{
-				ServiceIdentifier: serviceSummary.Id,
-				CertificateArn:    aws.String(service.Spec.CustomerCertARN),
-			}
-
-			latticeSess := s.cloud.Lattice()
-			resp, err := latticeSess.UpdateServiceWithContext(ctx, &serviceUpdateInput)
-			glog.V(2).Infof("UpdateServiceWithContext >>>> req %v resp %v err %v\n", serviceUpdateInput, resp, err)
-			if err != nil {
-				glog.V(6).Infoln("fail to update service")
-				return latticemodel.ServiceStatus{ServiceARN: "", ServiceID: ""}, err
-			}
-
-		}
-
+		// the certificate of an existing service is updated along with its HTTPS listener
 	}
 	err = s.serviceNetworkAssociationMgr(ctx, service.Spec.ServiceNetworkNames, serviceID)
 
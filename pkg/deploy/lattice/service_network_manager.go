@@ -17,6 +17,7 @@ type ServiceNetworkManager interface {
 	Create(ctx context.Context, service_network *latticemodel.ServiceNetwork) (latticemodel.ServiceNetworkStatus, error)
 	List(ctx context.Context) ([]string, error)
 	Delete(ctx context.Context, service_network string) error
+	ListVpcs(ctx context.Context, serviceNetworks []string) ([]string, error)
 }
 
 type serviceNetworkOutput struct {
@@ -170,6 +171,47 @@ func (m *defaultServiceNetworkManager) List(ctx context.Context) ([]string, erro
 	return service_networkList, nil
 }
 
+// ListVpcs returns the vpcs actively associated with any of the named service networks, service networks which
+// do not exist are ignored
+func (m *defaultServiceNetworkManager) ListVpcs(ctx context.Context, serviceNetworks []string) ([]string, error) {
+	vpcLatticeSess := m.cloud.Lattice()
+	resp, err := vpcLatticeSess.ListServiceNetworksAsList(ctx, &vpclattice.ListServiceNetworksInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, serviceNetwork := range serviceNetworks {
+		names[serviceNetwork] = true
+	}
+
+	var vpcs []string
+	vpcSet := make(map[string]bool)
+	for _, r := range resp {
+		if !names[aws.StringValue(r.Name)] {
+			continue
+		}
+
+		associations, err := vpcLatticeSess.ListServiceNetworkVpcAssociationsAsList(ctx, &vpclattice.ListServiceNetworkVpcAssociationsInput{
+			ServiceNetworkIdentifier: r.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, association := range associations {
+			vpcID := aws.StringValue(association.VpcId)
+			if aws.StringValue(association.Status) != vpclattice.ServiceNetworkVpcAssociationStatusActive || vpcSet[vpcID] {
+				continue
+			}
+			vpcSet[vpcID] = true
+			vpcs = append(vpcs, vpcID)
+		}
+	}
+
+	glog.V(6).Infof("defaultServiceNetworkManager: ListVpcs of %v return %v \n", serviceNetworks, vpcs)
+	return vpcs, nil
+}
+
 func (m *defaultServiceNetworkManager) Delete(ctx context.Context, service_network string) error {
 	service_networkSummary, err := m.findServiceNetworkByName(ctx, service_network)
 	if err != nil {
@@ -78,3 +78,18 @@ func (mr *MockServiceNetworkManagerMockRecorder) List(ctx interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockServiceNetworkManager)(nil).List), ctx)
 }
+
+// ListVpcs mocks base method.
+func (m *MockServiceNetworkManager) ListVpcs(ctx context.Context, serviceNetworks []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVpcs", ctx, serviceNetworks)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVpcs indicates an expected call of ListVpcs.
+func (mr *MockServiceNetworkManagerMockRecorder) ListVpcs(ctx, serviceNetworks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVpcs", reflect.TypeOf((*MockServiceNetworkManager)(nil).ListVpcs), ctx, serviceNetworks)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 	"testing"
 
@@ -1021,3 +1022,59 @@ func Test_ListMesh_NoMesh(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, meshList, []string{})
 }
+
+func Test_ListVpcs(t *testing.T) {
+	listServiceNetworkOutput := []*vpclattice.ServiceNetworkSummary{
+		{Arn: aws.String("sn-arn-1"), Id: aws.String("sn-1"), Name: aws.String("gw1")},
+		{Arn: aws.String("sn-arn-2"), Id: aws.String("sn-2"), Name: aws.String("gw2")},
+		{Arn: aws.String("sn-arn-3"), Id: aws.String("sn-3"), Name: aws.String("other")},
+	}
+	activeStatus := vpclattice.ServiceNetworkVpcAssociationStatusActive
+	failedStatus := vpclattice.ServiceNetworkVpcAssociationStatusCreateFailed
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListServiceNetworksAsList(ctx, gomock.Any()).Return(listServiceNetworkOutput, nil)
+	mockVpcLatticeSess.EXPECT().ListServiceNetworkVpcAssociationsAsList(ctx, &vpclattice.ListServiceNetworkVpcAssociationsInput{
+		ServiceNetworkIdentifier: aws.String("sn-1"),
+	}).Return([]*vpclattice.ServiceNetworkVpcAssociationSummary{
+		{VpcId: aws.String("vpc-1"), Status: &activeStatus},
+		{VpcId: aws.String("vpc-2"), Status: &failedStatus},
+	}, nil)
+	mockVpcLatticeSess.EXPECT().ListServiceNetworkVpcAssociationsAsList(ctx, &vpclattice.ListServiceNetworkVpcAssociationsInput{
+		ServiceNetworkIdentifier: aws.String("sn-2"),
+	}).Return([]*vpclattice.ServiceNetworkVpcAssociationSummary{
+		{VpcId: aws.String("vpc-1"), Status: &activeStatus},
+		{VpcId: aws.String("vpc-3"), Status: &activeStatus},
+	}, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	snManager := NewDefaultServiceNetworkManager(mockCloud)
+	vpcs, err := snManager.ListVpcs(ctx, []string{"gw1", "gw2", "missing"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"vpc-1", "vpc-3"}, vpcs)
+}
+
+func Test_ListVpcs_AssociationError(t *testing.T) {
+	listServiceNetworkOutput := []*vpclattice.ServiceNetworkSummary{
+		{Arn: aws.String("sn-arn-1"), Id: aws.String("sn-1"), Name: aws.String("gw1")},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListServiceNetworksAsList(ctx, gomock.Any()).Return(listServiceNetworkOutput, nil)
+	mockVpcLatticeSess.EXPECT().ListServiceNetworkVpcAssociationsAsList(ctx, gomock.Any()).Return(nil, errors.New("error"))
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	snManager := NewDefaultServiceNetworkManager(mockCloud)
+	_, err := snManager.ListVpcs(ctx, []string{"gw1"})
+
+	assert.NotNil(t, err)
+}
@@ -3,6 +3,10 @@ package lattice
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/golang/glog"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -10,6 +14,7 @@ import (
 
 	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 )
@@ -19,6 +24,38 @@ type TargetGroupManager interface {
 	Delete(ctx context.Context, targetGroup *latticemodel.TargetGroup) error
 	List(ctx context.Context) ([]targetGroupOutput, error)
 	Get(tx context.Context, targetGroup *latticemodel.TargetGroup) (latticemodel.TargetGroupStatus, error)
+	ListExported(ctx context.Context, name string, namespace string) ([]ExportedTargetGroup, error)
+	ListAllExported(ctx context.Context) ([]ExportedTargetGroup, error)
+}
+
+// ExportedTargetGroup is a target group created for a ServiceExport by the controller of any cluster
+type ExportedTargetGroup struct {
+	ID       string
+	ARN      string
+	Name     string
+	VpcID    string
+	Status   string
+	Protocol string
+	// Ports are the ports of the exported k8s service, empty when the exporting cluster did not tag them
+	Ports []int32
+	// Weight is the weight of the exporting cluster, k8s.DefaultServiceExportWeight when it did not tag one
+	Weight int64
+	Tags   map[string]*string
+}
+
+// ActiveExportedTargetGroups returns the active exported target groups, only the ones of the vpc when it is set
+func ActiveExportedTargetGroups(exportedTGs []ExportedTargetGroup, vpcID string) []ExportedTargetGroup {
+	var activeTGs []ExportedTargetGroup
+	for _, tg := range exportedTGs {
+		if tg.Status != vpclattice.TargetGroupStatusActive {
+			continue
+		}
+		if vpcID != "" && tg.VpcID != vpcID {
+			continue
+		}
+		activeTGs = append(activeTGs, tg)
+	}
+	return activeTGs
 }
 
 type defaultTargetGroupManager struct {
@@ -31,7 +68,23 @@ func NewTargetGroupManager(cloud lattice_aws.Cloud) *defaultTargetGroupManager {
 	}
 }
 
+// latticeTGNameSuffixes are the suffixes of the lattice target group names for the non default protocols
+// and protocol versions, see getLatticeTGNameSuffix
+var latticeTGNameSuffixes = []string{
+	"",
+	"-http2",
+	"-grpc",
+	"-https",
+	"-https-http2",
+	"-https-grpc",
+	"-tcp",
+}
+
 func getLatticeTGName(targetGroup *latticemodel.TargetGroup) string {
+	return getLatticeTGBaseName(targetGroup) + getLatticeTGNameSuffix(targetGroup.Spec.Config)
+}
+
+func getLatticeTGBaseName(targetGroup *latticemodel.TargetGroup) string {
 	var tgName string
 	if config.UseLongTGName {
 		tgName = latticestore.TargetGroupLongName(targetGroup.Spec.Name,
@@ -43,6 +96,37 @@ func getLatticeTGName(targetGroup *latticemodel.TargetGroup) string {
 	return tgName
 }
 
+// getLegacyLatticeTGName returns the name the previous release gave the lattice target group, without the service
+// port and without the protocol suffix. Lambda target groups did not exist then, their name is returned unchanged
+func getLegacyLatticeTGName(targetGroup *latticemodel.TargetGroup) string {
+	if targetGroup.Spec.Type == latticemodel.TargetGroupTypeLambda || targetGroup.Spec.Config.IsServiceImport {
+		return getLatticeTGName(targetGroup)
+	}
+	tgConfig := targetGroup.Spec.Config
+	if tgConfig.K8SServicePort == 0 && getLatticeTGNameSuffix(tgConfig) == "" {
+		return getLatticeTGName(targetGroup)
+	}
+
+	tgName := latticestore.TargetGroupName(tgConfig.K8SServiceName, tgConfig.K8SServiceNamespace)
+	if config.UseLongTGName {
+		tgName = latticestore.TargetGroupLongName(tgName, tgConfig.K8SHTTPRouteName, config.VpcID)
+	}
+	return tgName
+}
+
+// getLatticeTGNameSuffix returns the name suffix for a non default protocol or protocol version. Both are immutable
+// on a lattice target group, so a change needs a new target group which cannot have the name of the old one
+func getLatticeTGNameSuffix(tgConfig latticemodel.TargetGroupConfig) string {
+	suffix := ""
+	if tgConfig.Protocol != "" && tgConfig.Protocol != vpclattice.TargetGroupProtocolHttp {
+		suffix += "-" + strings.ToLower(tgConfig.Protocol)
+	}
+	if tgConfig.ProtocolVersion != "" && tgConfig.ProtocolVersion != vpclattice.TargetGroupProtocolVersionHttp1 {
+		suffix += "-" + strings.ToLower(tgConfig.ProtocolVersion)
+	}
+	return suffix
+}
+
 // Create will try to create a target group
 // return error when:
 //
@@ -65,12 +149,32 @@ func (s *defaultTargetGroupManager) Create(ctx context.Context, targetGroup *lat
 	glog.V(6).Infof("Create Target Group API call for name %s \n", targetGroup.Spec.Name)
 
 	latticeTGName := getLatticeTGName(targetGroup)
+	latticeTGNames := []string{latticeTGName}
+	legacyTGName := getLegacyLatticeTGName(targetGroup)
+	if legacyTGName != latticeTGName {
+		latticeTGNames = append(latticeTGNames, legacyTGName)
+	}
 	// check if exists
-	tgSummary, err := s.findTGByName(ctx, latticeTGName)
+	tgSummary, err := s.findTGByName(ctx, latticeTGNames...)
 	if err != nil {
 		return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, err
 	}
+	if tgSummary != nil && aws.StringValue(tgSummary.Name) == legacyTGName && legacyTGName != latticeTGName {
+		adopted, err := s.adoptLegacyTG(ctx, tgSummary, targetGroup)
+		if err != nil {
+			return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, err
+		}
+		if !adopted {
+			tgSummary = nil
+		}
+	}
 	if tgSummary != nil {
+		if err := s.updateHealthCheck(ctx, aws.StringValue(tgSummary.Id), targetGroup.Spec); err != nil {
+			return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, err
+		}
+		if err := s.updateExportTags(ctx, aws.StringValue(tgSummary.Arn), targetGroup.Spec.Config); err != nil {
+			return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, err
+		}
 		return latticemodel.TargetGroupStatus{TargetGroupARN: aws.StringValue(tgSummary.Arn), TargetGroupID: aws.StringValue(tgSummary.Id)}, err
 	}
 
@@ -81,6 +185,15 @@ func (s *defaultTargetGroupManager) Create(ctx context.Context, targetGroup *lat
 		Protocol:        &targetGroup.Spec.Config.Protocol,
 		ProtocolVersion: &targetGroup.Spec.Config.ProtocolVersion,
 		VpcIdentifier:   &targetGroup.Spec.Config.VpcID,
+		HealthCheck:     targetGroup.Spec.Config.HealthCheckConfig,
+	}
+	if targetGroup.Spec.Config.Protocol == "TCP" {
+		// lattice rejects a protocol version for TCP target groups
+		tgConfig.ProtocolVersion = nil
+	}
+	if targetGroup.Spec.Type == latticemodel.TargetGroupTypeLambda {
+		// lambda target groups take no config, traffic is sent to the function in any vpc
+		tgConfig = nil
 	}
 
 	targetGroupType := string(targetGroup.Spec.Type)
@@ -93,11 +206,26 @@ func (s *defaultTargetGroupManager) Create(ctx context.Context, targetGroup *lat
 	}
 	createTargetGroupInput.Tags[latticemodel.K8SServiceNameKey] = &targetGroup.Spec.Config.K8SServiceName
 	createTargetGroupInput.Tags[latticemodel.K8SServiceNamespaceKey] = &targetGroup.Spec.Config.K8SServiceNamespace
+	if targetGroup.Spec.Config.K8SServicePort != 0 {
+		createTargetGroupInput.Tags[latticemodel.K8SServicePortKey] = aws.String(strconv.Itoa(int(targetGroup.Spec.Config.K8SServicePort)))
+	}
+	if targetGroup.Spec.Type == latticemodel.TargetGroupTypeLambda {
+		createTargetGroupInput.Tags[latticemodel.K8SClusterVpcIDKey] = aws.String(config.VpcID)
+	}
 	if targetGroup.Spec.Config.IsServiceExport {
 		value := latticemodel.K8SServiceExportType
 		createTargetGroupInput.Tags[latticemodel.K8SParentRefTypeKey] = &value
+		if len(targetGroup.Spec.Config.K8SServiceExportPorts) > 0 {
+			createTargetGroupInput.Tags[latticemodel.K8SServiceExportPortsKey] = aws.String(formatExportPorts(targetGroup.Spec.Config.K8SServiceExportPorts))
+		}
+		if targetGroup.Spec.Config.K8SServiceExportWeight != nil {
+			createTargetGroupInput.Tags[latticemodel.K8SServiceExportWeightKey] = aws.String(strconv.FormatInt(*targetGroup.Spec.Config.K8SServiceExportWeight, 10))
+		}
 	} else {
 		value := latticemodel.K8SHTTPRouteType
+		if targetGroup.Spec.Config.K8SRouteType != "" {
+			value = targetGroup.Spec.Config.K8SRouteType
+		}
 		createTargetGroupInput.Tags[latticemodel.K8SParentRefTypeKey] = &value
 		createTargetGroupInput.Tags[latticemodel.K8SHTTPRouteNameKey] = &targetGroup.Spec.Config.K8SHTTPRouteName
 		createTargetGroupInput.Tags[latticemodel.K8SHTTPRouteNamespaceKey] = &targetGroup.Spec.Config.K8SHTTPRouteNamespace
@@ -130,11 +258,276 @@ func (s *defaultTargetGroupManager) Create(ctx context.Context, targetGroup *lat
 	return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, nil
 }
 
+// adoptLegacyTG returns whether the target group created by the previous release under the legacy name is taken
+// over by the target group, instead of creating a new one. That needs the same protocol and protocol version, and
+// the legacy target group must not be taken over yet, for another service port of the route. It is tagged with the
+// service port of the target group then, so it is kept by SynthesizeSDKTargetGroups. A legacy target group which is
+// not taken over is drained and deleted by the target group synthesizer, once the rules use the new one
+func (s *defaultTargetGroupManager) adoptLegacyTG(ctx context.Context, tgSummary *vpclattice.TargetGroupSummary, targetGroup *latticemodel.TargetGroup) (bool, error) {
+	tgConfig := targetGroup.Spec.Config
+	vpcLatticeSess := s.cloud.Lattice()
+
+	resp, err := vpcLatticeSess.GetTargetGroupWithContext(ctx, &vpclattice.GetTargetGroupInput{
+		TargetGroupIdentifier: tgSummary.Id,
+	})
+	if err != nil {
+		glog.V(2).Infof("Failed to get legacy target group %v, err %v\n", aws.StringValue(tgSummary.Id), err)
+		return false, err
+	}
+	protocolVersion := tgConfig.ProtocolVersion
+	if tgConfig.Protocol == vpclattice.TargetGroupProtocolTcp {
+		// TCP target groups have no protocol version
+		protocolVersion = ""
+	}
+	if resp.Config == nil || aws.StringValue(resp.Config.Protocol) != tgConfig.Protocol ||
+		aws.StringValue(resp.Config.ProtocolVersion) != protocolVersion {
+		glog.V(2).Infof("Legacy target group %v has another protocol, it is replaced\n", aws.StringValue(tgSummary.Name))
+		return false, nil
+	}
+
+	tagsOutput, err := vpcLatticeSess.ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+		ResourceArn: tgSummary.Arn,
+	})
+	if err != nil {
+		glog.V(2).Infof("Failed to list tags of legacy target group %v, err %v\n", aws.StringValue(tgSummary.Arn), err)
+		return false, err
+	}
+	tags := tagsOutput.Tags
+	if _, ok := tags[latticemodel.K8SServicePortKey]; ok {
+		glog.V(6).Infof("Legacy target group %v is taken over for another port\n", aws.StringValue(tgSummary.Name))
+		return false, nil
+	}
+	if !tgConfig.IsServiceExport && (aws.StringValue(tags[latticemodel.K8SHTTPRouteNameKey]) != tgConfig.K8SHTTPRouteName ||
+		aws.StringValue(tags[latticemodel.K8SHTTPRouteNamespaceKey]) != tgConfig.K8SHTTPRouteNamespace) {
+		glog.V(6).Infof("Legacy target group %v belongs to another route\n", aws.StringValue(tgSummary.Name))
+		return false, nil
+	}
+
+	if tgConfig.K8SServicePort != 0 {
+		tagInput := vpclattice.TagResourceInput{
+			ResourceArn: tgSummary.Arn,
+			Tags: map[string]*string{
+				latticemodel.K8SServicePortKey: aws.String(strconv.Itoa(int(tgConfig.K8SServicePort))),
+			},
+		}
+		_, err = vpcLatticeSess.TagResourceWithContext(ctx, &tagInput)
+		glog.V(2).Infof("tag legacy target group with service port >>>> req [%v], err[%v]\n", tagInput, err)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// updateHealthCheck updates the health check of an existing target group in place when it has drifted from
+// the TargetGroupPolicy of the k8s service. Without a health check config the lattice defaults are restored
+func (s *defaultTargetGroupManager) updateHealthCheck(ctx context.Context, tgID string, tgSpec latticemodel.TargetGroupSpec) error {
+	if tgSpec.Type == latticemodel.TargetGroupTypeLambda {
+		// lambda target groups have no health check
+		return nil
+	}
+	healthCheck := tgSpec.Config.HealthCheckConfig
+	if healthCheck == nil {
+		healthCheck = defaultHealthCheckConfig(tgSpec.Config)
+	}
+
+	vpcLatticeSess := s.cloud.Lattice()
+	getTGInput := vpclattice.GetTargetGroupInput{
+		TargetGroupIdentifier: aws.String(tgID),
+	}
+	resp, err := vpcLatticeSess.GetTargetGroupWithContext(ctx, &getTGInput)
+	if err != nil {
+		glog.V(2).Infof("Failed to get target group %v, err %v\n", tgID, err)
+		return err
+	}
+
+	if resp.Config != nil {
+		current := resp.Config.HealthCheck
+		if current != nil && current.Port == nil {
+			// the health check without a port is sent to the traffic port
+			withPort := *current
+			withPort.Port = resp.Config.Port
+			current = &withPort
+		}
+		if isHealthCheckConfigSame(healthCheck, current) {
+			return nil
+		}
+	}
+
+	updateTGInput := vpclattice.UpdateTargetGroupInput{
+		HealthCheck:           healthCheck,
+		TargetGroupIdentifier: aws.String(tgID),
+	}
+	_, err = vpcLatticeSess.UpdateTargetGroupWithContext(ctx, &updateTGInput)
+	glog.V(2).Infof("update target group health check >>>> req [%v], err[%v]\n", updateTGInput, err)
+	return err
+}
+
+// updateExportTags updates the ports and weight tags of an existing exported target group when the ports of the
+// k8s service or the weight of the ServiceExport have changed. The weight tag is removed with the weight annotation
+func (s *defaultTargetGroupManager) updateExportTags(ctx context.Context, tgArn string, tgConfig latticemodel.TargetGroupConfig) error {
+	if !tgConfig.IsServiceExport {
+		return nil
+	}
+
+	vpcLatticeSess := s.cloud.Lattice()
+	tagsOutput, err := vpcLatticeSess.ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+		ResourceArn: aws.String(tgArn),
+	})
+	if err != nil {
+		glog.V(2).Infof("Failed to list tags of target group %v, err %v\n", tgArn, err)
+		return err
+	}
+
+	tags := make(map[string]*string)
+	if len(tgConfig.K8SServiceExportPorts) > 0 {
+		ports := formatExportPorts(tgConfig.K8SServiceExportPorts)
+		if aws.StringValue(tagsOutput.Tags[latticemodel.K8SServiceExportPortsKey]) != ports {
+			tags[latticemodel.K8SServiceExportPortsKey] = aws.String(ports)
+		}
+	}
+	currentWeight, hasWeight := tagsOutput.Tags[latticemodel.K8SServiceExportWeightKey]
+	if tgConfig.K8SServiceExportWeight != nil {
+		weight := strconv.FormatInt(*tgConfig.K8SServiceExportWeight, 10)
+		if aws.StringValue(currentWeight) != weight {
+			tags[latticemodel.K8SServiceExportWeightKey] = aws.String(weight)
+		}
+	} else if hasWeight {
+		untagInput := vpclattice.UntagResourceInput{
+			ResourceArn: aws.String(tgArn),
+			TagKeys:     []*string{aws.String(latticemodel.K8SServiceExportWeightKey)},
+		}
+		_, err = vpcLatticeSess.UntagResourceWithContext(ctx, &untagInput)
+		glog.V(2).Infof("remove target group export weight tag >>>> req [%v], err[%v]\n", untagInput, err)
+		if err != nil {
+			return err
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tagInput := vpclattice.TagResourceInput{
+		ResourceArn: aws.String(tgArn),
+		Tags:        tags,
+	}
+	_, err = vpcLatticeSess.TagResourceWithContext(ctx, &tagInput)
+	glog.V(2).Infof("update target group export tags >>>> req [%v], err[%v]\n", tagInput, err)
+	return err
+}
+
+// formatExportPorts returns the sorted, comma separated ports for the K8SServiceExportPortsKey tag
+func formatExportPorts(ports []int32) string {
+	sorted := append([]int32{}, ports...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var values []string
+	for _, port := range sorted {
+		values = append(values, strconv.Itoa(int(port)))
+	}
+	return strings.Join(values, ",")
+}
+
+// parseExportPorts returns the ports of a K8SServiceExportPortsKey tag, ignoring malformed values
+func parseExportPorts(tag string) []int32 {
+	var ports []int32
+	for _, value := range strings.Split(tag, ",") {
+		port, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			continue
+		}
+		ports = append(ports, int32(port))
+	}
+	return ports
+}
+
+// parseExportWeight returns the weight of a K8SServiceExportWeightKey tag, k8s.DefaultServiceExportWeight when
+// the tag is missing or malformed
+func parseExportWeight(tag *string) int64 {
+	if tag == nil {
+		return k8s.DefaultServiceExportWeight
+	}
+	weight, err := strconv.ParseInt(aws.StringValue(tag), 10, 64)
+	if err != nil || weight < 0 || weight > k8s.MaxServiceExportWeight {
+		return k8s.DefaultServiceExportWeight
+	}
+	return weight
+}
+
+// defaultHealthCheckConfig returns the health check lattice creates a target group of the config with
+func defaultHealthCheckConfig(tgConfig latticemodel.TargetGroupConfig) *vpclattice.HealthCheckConfig {
+	healthCheck := &vpclattice.HealthCheckConfig{
+		Enabled:                    aws.Bool(true),
+		HealthCheckIntervalSeconds: aws.Int64(30),
+		HealthCheckTimeoutSeconds:  aws.Int64(5),
+		HealthyThresholdCount:      aws.Int64(5),
+		UnhealthyThresholdCount:    aws.Int64(2),
+		Matcher: &vpclattice.Matcher{
+			HttpCode: aws.String("200"),
+		},
+		Path:            aws.String("/"),
+		ProtocolVersion: aws.String("HTTP1"),
+	}
+	if tgConfig.Port > 0 {
+		healthCheck.Port = aws.Int64(int64(tgConfig.Port))
+	}
+	if tgConfig.Protocol == "HTTP" || tgConfig.Protocol == "HTTPS" {
+		healthCheck.Protocol = aws.String(tgConfig.Protocol)
+	}
+	return healthCheck
+}
+
+// isHealthCheckConfigSame returns whether the fields set in the desired health check match the current one,
+// lattice fills in defaults for the fields which are not set
+func isHealthCheckConfigSame(desired *vpclattice.HealthCheckConfig, current *vpclattice.HealthCheckConfig) bool {
+	if current == nil {
+		return false
+	}
+
+	if desired.Enabled != nil && aws.BoolValue(desired.Enabled) != aws.BoolValue(current.Enabled) {
+		return false
+	}
+	for _, pair := range [][2]*int64{
+		{desired.HealthCheckIntervalSeconds, current.HealthCheckIntervalSeconds},
+		{desired.HealthCheckTimeoutSeconds, current.HealthCheckTimeoutSeconds},
+		{desired.HealthyThresholdCount, current.HealthyThresholdCount},
+		{desired.UnhealthyThresholdCount, current.UnhealthyThresholdCount},
+		{desired.Port, current.Port},
+	} {
+		if pair[0] != nil && aws.Int64Value(pair[0]) != aws.Int64Value(pair[1]) {
+			return false
+		}
+	}
+	for _, pair := range [][2]*string{
+		{desired.Path, current.Path},
+		{desired.Protocol, current.Protocol},
+		{desired.ProtocolVersion, current.ProtocolVersion},
+	} {
+		if pair[0] != nil && aws.StringValue(pair[0]) != aws.StringValue(pair[1]) {
+			return false
+		}
+	}
+	if desired.Matcher != nil && desired.Matcher.HttpCode != nil {
+		if current.Matcher == nil || aws.StringValue(desired.Matcher.HttpCode) != aws.StringValue(current.Matcher.HttpCode) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *defaultTargetGroupManager) Get(ctx context.Context, targetGroup *latticemodel.TargetGroup) (latticemodel.TargetGroupStatus, error) {
 	glog.V(6).Infof("Create Lattice Target Group API call for name %s \n", targetGroup.Spec.Name)
 
+	// the protocol version of the exported target group is not known by the importer, look up all of them
+	var latticeTGNames []string
+	for _, suffix := range latticeTGNameSuffixes {
+		latticeTGNames = append(latticeTGNames, getLatticeTGBaseName(targetGroup)+suffix)
+	}
+	// the target group may still have the name of the previous release
+	latticeTGNames = append(latticeTGNames, getLegacyLatticeTGName(targetGroup))
+
 	// check if exists
-	tgSummary, err := s.findTGByName(ctx, getLatticeTGName(targetGroup))
+	tgSummary, err := s.findTGByName(ctx, latticeTGNames...)
 	if err != nil {
 		return latticemodel.TargetGroupStatus{TargetGroupARN: "", TargetGroupID: ""}, err
 	}
@@ -234,7 +627,8 @@ func (s *defaultTargetGroupManager) List(ctx context.Context) ([]targetGroupOutp
 
 		//glog.V(6).Infof("Manager-List: tg-vpc %v , config.vpc %v\n", aws.StringValue(tgOutput.Config.VpcId), config.VpcID)
 
-		if tgOutput.Config != nil && aws.StringValue(tgOutput.Config.VpcIdentifier) == config.VpcID {
+		isLambda := aws.StringValue(tgOutput.Type) == string(latticemodel.TargetGroupTypeLambda)
+		if isLambda || (tgOutput.Config != nil && aws.StringValue(tgOutput.Config.VpcIdentifier) == config.VpcID) {
 			// retrieve target group tags
 			//ListTagsForResourceWithContext
 			tagsInput := vpclattice.ListTagsForResourceInput{
@@ -249,6 +643,9 @@ func (s *defaultTargetGroupManager) List(ctx context.Context) ([]targetGroupOutp
 				// setting it to nil, so the caller knows there is tag resource associated to this target group
 				tagsOutput = nil
 			}
+			if isLambda && !isLambdaTGOfCluster(tagsOutput) {
+				continue
+			}
 			tgOutput := targetGroupOutput{
 				getTargetGroupOutput: *tgOutput,
 				targetGroupTags:      tagsOutput,
@@ -259,28 +656,101 @@ func (s *defaultTargetGroupManager) List(ctx context.Context) ([]targetGroupOutp
 	return tgList, err
 }
 
-func (s *defaultTargetGroupManager) findTGByName(ctx context.Context, targetGroup string) (*vpclattice.TargetGroupSummary, error) {
+// ListExported returns the target groups exported for the k8s service name/namespace, by the clusters of any
+// vpc. Unlike List, target groups of other vpcs are included, since those are what a ServiceImport refers to
+func (s *defaultTargetGroupManager) ListExported(ctx context.Context, name string, namespace string) ([]ExportedTargetGroup, error) {
+	exportedTGs, err := s.listExported(ctx, func(tags map[string]*string) bool {
+		return aws.StringValue(tags[latticemodel.K8SServiceNameKey]) == name &&
+			aws.StringValue(tags[latticemodel.K8SServiceNamespaceKey]) == namespace
+	})
+	glog.V(6).Infof("ListExported, service %s-%s, exported target groups %v, err %v\n", name, namespace, exportedTGs, err)
+	return exportedTGs, err
+}
+
+// ListAllExported returns the target groups exported for any k8s service by the clusters of any vpc, the
+// service is found in the K8SServiceNameKey and K8SServiceNamespaceKey tags
+func (s *defaultTargetGroupManager) ListAllExported(ctx context.Context) ([]ExportedTargetGroup, error) {
+	return s.listExported(ctx, func(tags map[string]*string) bool {
+		return true
+	})
+}
+
+func (s *defaultTargetGroupManager) listExported(ctx context.Context, match func(tags map[string]*string) bool) ([]ExportedTargetGroup, error) {
+	vpcLatticeSess := s.cloud.Lattice()
+	resp, err := vpcLatticeSess.ListTargetGroupsAsList(ctx, &vpclattice.ListTargetGroupsInput{})
+	if err != nil {
+		glog.V(6).Infof("listExported, listTargetGroupsAsList failed err %v\n", err)
+		return nil, err
+	}
+
+	var exportedTGs []ExportedTargetGroup
+	for _, tg := range resp {
+		if aws.StringValue(tg.Type) == string(latticemodel.TargetGroupTypeLambda) {
+			continue
+		}
+
+		tagsOutput, err := vpcLatticeSess.ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+			ResourceArn: tg.Arn,
+		})
+		if err != nil {
+			glog.V(6).Infof("listExported, failed to list tags of target group %v, err %v\n", aws.StringValue(tg.Arn), err)
+			continue
+		}
+		if aws.StringValue(tagsOutput.Tags[latticemodel.K8SParentRefTypeKey]) != latticemodel.K8SServiceExportType ||
+			!match(tagsOutput.Tags) {
+			continue
+		}
+
+		exportedTGs = append(exportedTGs, ExportedTargetGroup{
+			ID:       aws.StringValue(tg.Id),
+			ARN:      aws.StringValue(tg.Arn),
+			Name:     aws.StringValue(tg.Name),
+			VpcID:    aws.StringValue(tg.VpcIdentifier),
+			Status:   aws.StringValue(tg.Status),
+			Protocol: aws.StringValue(tg.Protocol),
+			Ports:    parseExportPorts(aws.StringValue(tagsOutput.Tags[latticemodel.K8SServiceExportPortsKey])),
+			Weight:   parseExportWeight(tagsOutput.Tags[latticemodel.K8SServiceExportWeightKey]),
+			Tags:     tagsOutput.Tags,
+		})
+	}
+	return exportedTGs, nil
+}
+
+// isLambdaTGOfCluster returns whether the lambda target group was created by the controller of this cluster,
+// by the vpc tag since lambda target groups are not in a vpc
+func isLambdaTGOfCluster(tagsOutput *vpclattice.ListTagsForResourceOutput) bool {
+	if tagsOutput == nil {
+		return false
+	}
+	return aws.StringValue(tagsOutput.Tags[latticemodel.K8SClusterVpcIDKey]) == config.VpcID
+}
+
+// findTGByName returns the first target group found by name, the names are looked up in order
+func (s *defaultTargetGroupManager) findTGByName(ctx context.Context, targetGroups ...string) (*vpclattice.TargetGroupSummary, error) {
 	vpcLatticeSess := s.cloud.Lattice()
 	targetGroupListInput := vpclattice.ListTargetGroupsInput{}
 	resp, err := vpcLatticeSess.ListTargetGroupsAsList(ctx, &targetGroupListInput)
 
 	if err == nil {
 		glog.V(6).Infof("findTGByName: resp %v \n", resp)
-		for _, r := range resp {
-			if aws.StringValue(r.Name) == targetGroup {
-				glog.V(6).Info("targetgroup ", targetGroup, " already exists with arn ", *r.Arn, "\n")
-				status := aws.StringValue(r.Status)
-				switch status {
-				case vpclattice.TargetGroupStatusCreateInProgress:
-					return nil, errors.New(LATTICE_RETRY)
-				case vpclattice.TargetGroupStatusActive:
-					return r, nil
-				case vpclattice.TargetGroupStatusCreateFailed:
-					return nil, nil
-				case vpclattice.TargetGroupStatusDeleteFailed:
-					return r, nil
-				case vpclattice.TargetGroupStatusDeleteInProgress:
-					return nil, errors.New(LATTICE_RETRY)
+	nextName:
+		for _, targetGroup := range targetGroups {
+			for _, r := range resp {
+				if aws.StringValue(r.Name) == targetGroup {
+					glog.V(6).Info("targetgroup ", targetGroup, " already exists with arn ", *r.Arn, "\n")
+					status := aws.StringValue(r.Status)
+					switch status {
+					case vpclattice.TargetGroupStatusCreateInProgress:
+						return nil, errors.New(LATTICE_RETRY)
+					case vpclattice.TargetGroupStatusActive:
+						return r, nil
+					case vpclattice.TargetGroupStatusCreateFailed:
+						continue nextName
+					case vpclattice.TargetGroupStatusDeleteFailed:
+						return r, nil
+					case vpclattice.TargetGroupStatusDeleteInProgress:
+						return nil, errors.New(LATTICE_RETRY)
+					}
 				}
 			}
 		}
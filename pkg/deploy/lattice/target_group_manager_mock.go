@@ -93,3 +93,33 @@ func (mr *MockTargetGroupManagerMockRecorder) List(ctx interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTargetGroupManager)(nil).List), ctx)
 }
+
+// ListExported mocks base method.
+func (m *MockTargetGroupManager) ListExported(ctx context.Context, name, namespace string) ([]ExportedTargetGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExported", ctx, name, namespace)
+	ret0, _ := ret[0].([]ExportedTargetGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExported indicates an expected call of ListExported.
+func (mr *MockTargetGroupManagerMockRecorder) ListExported(ctx, name, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExported", reflect.TypeOf((*MockTargetGroupManager)(nil).ListExported), ctx, name, namespace)
+}
+
+// ListAllExported mocks base method.
+func (m *MockTargetGroupManager) ListAllExported(ctx context.Context) ([]ExportedTargetGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllExported", ctx)
+	ret0, _ := ret[0].([]ExportedTargetGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllExported indicates an expected call of ListAllExported.
+func (mr *MockTargetGroupManagerMockRecorder) ListAllExported(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllExported", reflect.TypeOf((*MockTargetGroupManager)(nil).ListAllExported), ctx)
+}
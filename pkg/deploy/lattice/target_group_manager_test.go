@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 	"testing"
 
@@ -118,6 +121,66 @@ func Test_CreateTargetGroup_TGNotExist_Active(t *testing.T) {
 	}
 }
 
+// lambda target group is created without config and tagged with the vpc of the cluster
+func Test_CreateTargetGroup_Lambda(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	tgSpec := latticemodel.TargetGroupSpec{
+		Name: "k8s-fn-default-lambda",
+		Type: latticemodel.TargetGroupTypeLambda,
+		Config: latticemodel.TargetGroupConfig{
+			VpcID:                 config.VpcID,
+			K8SServiceName:        "fn",
+			K8SServiceNamespace:   "default",
+			K8SHTTPRouteName:      "httproute1",
+			K8SHTTPRouteNamespace: "default",
+		},
+	}
+	tgCreateInput := latticemodel.TargetGroup{
+		ResourceMeta: core.ResourceMeta{},
+		Spec:         tgSpec,
+	}
+
+	arn := "12345678912345678912"
+	id := "12345678912345678912"
+	tgStatus := vpclattice.TargetGroupStatusActive
+	tgCreateOutput := &vpclattice.CreateTargetGroupOutput{
+		Arn:    &arn,
+		Id:     &id,
+		Name:   &tgSpec.Name,
+		Status: &tgStatus,
+	}
+
+	routeType := latticemodel.K8SHTTPRouteType
+	createTargetGroupInput := vpclattice.CreateTargetGroupInput{
+		Name: aws.String("k8s-fn-default-lambda"),
+		Type: aws.String("LAMBDA"),
+		Tags: map[string]*string{
+			latticemodel.K8SServiceNameKey:        &tgSpec.Config.K8SServiceName,
+			latticemodel.K8SServiceNamespaceKey:   &tgSpec.Config.K8SServiceNamespace,
+			latticemodel.K8SClusterVpcIDKey:       &config.VpcID,
+			latticemodel.K8SParentRefTypeKey:      &routeType,
+			latticemodel.K8SHTTPRouteNameKey:      &tgSpec.Config.K8SHTTPRouteName,
+			latticemodel.K8SHTTPRouteNamespaceKey: &tgSpec.Config.K8SHTTPRouteNamespace,
+		},
+	}
+
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return([]*vpclattice.TargetGroupSummary{}, nil)
+	mockVpcLatticeSess.EXPECT().CreateTargetGroupWithContext(ctx, &createTargetGroupInput).Return(tgCreateOutput, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+	tgManager := NewTargetGroupManager(mockCloud)
+	resp, err := tgManager.Create(ctx, &tgCreateInput)
+
+	assert.Nil(t, err)
+	assert.Equal(t, arn, resp.TargetGroupARN)
+	assert.Equal(t, id, resp.TargetGroupID)
+}
+
 // target group status is failed, and is active after creation
 func Test_CreateTargetGroup_TGFailed_Active(t *testing.T) {
 	c := gomock.NewController(t)
@@ -194,6 +257,10 @@ func Test_CreateTargetGroup_TGActive_ACTIVE(t *testing.T) {
 
 	mockCloud := mocks_aws.NewMockCloud(c)
 	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTgOutput, nil)
+	// the target group has the lattice default health check
+	mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(&vpclattice.GetTargetGroupOutput{
+		Config: &vpclattice.TargetGroupConfig{HealthCheck: defaultHealthCheckConfig(tgSpec.Config)},
+	}, nil)
 	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
 	tgManager := NewTargetGroupManager(mockCloud)
 	resp, err := tgManager.Create(ctx, &tgCreateInput)
@@ -203,6 +270,369 @@ func Test_CreateTargetGroup_TGActive_ACTIVE(t *testing.T) {
 	assert.Equal(t, resp.TargetGroupID, id)
 }
 
+// upgrade from the previous release, which named the target group of a backendRef with a port without the port
+func Test_CreateTargetGroup_LegacyTG(t *testing.T) {
+	config.VpcID = "current-vpc"
+	legacyName := latticestore.TargetGroupName("svc", "ns")
+	tgConfig := latticemodel.TargetGroupConfig{
+		Port:                  8080,
+		Protocol:              vpclattice.TargetGroupProtocolHttp,
+		ProtocolVersion:       vpclattice.TargetGroupProtocolVersionHttp1,
+		VpcID:                 config.VpcID,
+		K8SServiceName:        "svc",
+		K8SServiceNamespace:   "ns",
+		K8SServicePort:        80,
+		K8SHTTPRouteName:      "route",
+		K8SHTTPRouteNamespace: "ns",
+	}
+	routeTags := map[string]*string{
+		latticemodel.K8SParentRefTypeKey:      aws.String(latticemodel.K8SHTTPRouteType),
+		latticemodel.K8SServiceNameKey:        aws.String("svc"),
+		latticemodel.K8SServiceNamespaceKey:   aws.String("ns"),
+		latticemodel.K8SHTTPRouteNameKey:      aws.String("route"),
+		latticemodel.K8SHTTPRouteNamespaceKey: aws.String("ns"),
+	}
+	adoptedTags := map[string]*string{
+		latticemodel.K8SServicePortKey: aws.String("8443"),
+	}
+	for key, value := range routeTags {
+		adoptedTags[key] = value
+	}
+
+	tests := []struct {
+		name                  string
+		legacyProtocolVersion string
+		legacyTags            map[string]*string
+		wantAdopted           bool
+	}{
+		{
+			name:                  "legacy target group is taken over",
+			legacyProtocolVersion: vpclattice.TargetGroupProtocolVersionHttp1,
+			legacyTags:            routeTags,
+			wantAdopted:           true,
+		},
+		{
+			name:                  "legacy target group with another protocol version is replaced",
+			legacyProtocolVersion: vpclattice.TargetGroupProtocolVersionHttp2,
+		},
+		{
+			name:                  "legacy target group taken over for another port is not taken over again",
+			legacyProtocolVersion: vpclattice.TargetGroupProtocolVersionHttp1,
+			legacyTags:            adoptedTags,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			tgCreateInput := latticemodel.TargetGroup{
+				Spec: latticemodel.TargetGroupSpec{
+					Name:   latticestore.TargetGroupPortName("svc", "ns", 80),
+					Type:   latticemodel.TargetGroupTypeIP,
+					Config: tgConfig,
+				},
+			}
+			legacyTG := &vpclattice.TargetGroupSummary{
+				Arn:    aws.String("legacy-arn"),
+				Id:     aws.String("legacy-id"),
+				Name:   aws.String(legacyName),
+				Status: aws.String(vpclattice.TargetGroupStatusActive),
+			}
+			getTGOutput := &vpclattice.GetTargetGroupOutput{
+				Config: &vpclattice.TargetGroupConfig{
+					Port:            aws.Int64(8080),
+					Protocol:        aws.String(vpclattice.TargetGroupProtocolHttp),
+					ProtocolVersion: aws.String(tt.legacyProtocolVersion),
+					HealthCheck:     defaultHealthCheckConfig(tgConfig),
+				},
+			}
+
+			mockVpcLatticeSess := mocks.NewMockLattice(c)
+			mockCloud := mocks_aws.NewMockCloud(c)
+			mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+			mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(
+				[]*vpclattice.TargetGroupSummary{legacyTG}, nil)
+			mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, &vpclattice.GetTargetGroupInput{
+				TargetGroupIdentifier: aws.String("legacy-id"),
+			}).Return(getTGOutput, nil).MinTimes(1)
+			if tt.legacyTags != nil {
+				mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+					ResourceArn: aws.String("legacy-arn"),
+				}).Return(&vpclattice.ListTagsForResourceOutput{Tags: tt.legacyTags}, nil)
+			}
+
+			if tt.wantAdopted {
+				mockVpcLatticeSess.EXPECT().TagResourceWithContext(ctx, &vpclattice.TagResourceInput{
+					ResourceArn: aws.String("legacy-arn"),
+					Tags: map[string]*string{
+						latticemodel.K8SServicePortKey: aws.String("80"),
+					},
+				}).Return(&vpclattice.TagResourceOutput{}, nil)
+			} else {
+				mockVpcLatticeSess.EXPECT().CreateTargetGroupWithContext(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, input *vpclattice.CreateTargetGroupInput, opts ...interface{}) (*vpclattice.CreateTargetGroupOutput, error) {
+						assert.Equal(t, latticestore.TargetGroupPortName("svc", "ns", 80), aws.StringValue(input.Name))
+						return &vpclattice.CreateTargetGroupOutput{
+							Arn:    aws.String("new-arn"),
+							Id:     aws.String("new-id"),
+							Status: aws.String(vpclattice.TargetGroupStatusActive),
+						}, nil
+					})
+			}
+
+			tgManager := NewTargetGroupManager(mockCloud)
+			resp, err := tgManager.Create(ctx, &tgCreateInput)
+
+			assert.Nil(t, err)
+			if tt.wantAdopted {
+				assert.Equal(t, "legacy-arn", resp.TargetGroupARN)
+				assert.Equal(t, "legacy-id", resp.TargetGroupID)
+			} else {
+				assert.Equal(t, "new-arn", resp.TargetGroupARN)
+				assert.Equal(t, "new-id", resp.TargetGroupID)
+			}
+		})
+	}
+}
+
+// target group is active before creation, its health check drifted from the desired one and is updated
+func Test_CreateTargetGroup_TGActive_UpdateHealthCheck(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	healthCheck := &vpclattice.HealthCheckConfig{
+		Enabled: aws.Bool(true),
+		Path:    aws.String("/health"),
+	}
+	tgSpec := latticemodel.TargetGroupSpec{
+		Name: "test",
+		Config: latticemodel.TargetGroupConfig{
+			HealthCheckConfig: healthCheck,
+		},
+	}
+	tgCreateInput := latticemodel.TargetGroup{
+		ResourceMeta: core.ResourceMeta{},
+		Spec:         tgSpec,
+	}
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	arn := "12345678912345678912"
+	id := "12345678912345678912"
+	name := "test"
+
+	beforeCreateStatus := vpclattice.TargetGroupStatusActive
+	tgSummary := vpclattice.TargetGroupSummary{
+		Arn:    &arn,
+		Id:     &id,
+		Name:   &name,
+		Status: &beforeCreateStatus,
+	}
+	listTgOutput := []*vpclattice.TargetGroupSummary{&tgSummary}
+	getTGOutput := &vpclattice.GetTargetGroupOutput{
+		Config: &vpclattice.TargetGroupConfig{
+			HealthCheck: &vpclattice.HealthCheckConfig{
+				Enabled: aws.Bool(true),
+				Path:    aws.String("/"),
+			},
+		},
+	}
+	updateTGInput := &vpclattice.UpdateTargetGroupInput{
+		HealthCheck:           healthCheck,
+		TargetGroupIdentifier: &id,
+	}
+
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTgOutput, nil)
+	mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(getTGOutput, nil)
+	mockVpcLatticeSess.EXPECT().UpdateTargetGroupWithContext(ctx, updateTGInput).Return(&vpclattice.UpdateTargetGroupOutput{}, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+	tgManager := NewTargetGroupManager(mockCloud)
+	resp, err := tgManager.Create(ctx, &tgCreateInput)
+
+	assert.Nil(t, err)
+	assert.Equal(t, resp.TargetGroupARN, arn)
+	assert.Equal(t, resp.TargetGroupID, id)
+}
+
+// target group is active before creation, the TargetGroupPolicy of its service was removed and the lattice
+// default health check is restored
+func Test_CreateTargetGroup_TGActive_ResetHealthCheck(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	tgSpec := latticemodel.TargetGroupSpec{
+		Name: "test",
+		Type: latticemodel.TargetGroupTypeIP,
+		Config: latticemodel.TargetGroupConfig{
+			Port:     80,
+			Protocol: "HTTP",
+		},
+	}
+	tgCreateInput := latticemodel.TargetGroup{
+		ResourceMeta: core.ResourceMeta{},
+		Spec:         tgSpec,
+	}
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	arn := "12345678912345678912"
+	id := "12345678912345678912"
+	name := "test"
+
+	beforeCreateStatus := vpclattice.TargetGroupStatusActive
+	tgSummary := vpclattice.TargetGroupSummary{
+		Arn:    &arn,
+		Id:     &id,
+		Name:   &name,
+		Status: &beforeCreateStatus,
+	}
+	listTgOutput := []*vpclattice.TargetGroupSummary{&tgSummary}
+
+	tests := []struct {
+		name       string
+		current    *vpclattice.HealthCheckConfig
+		wantUpdate bool
+	}{
+		{
+			name: "policy health check is reset",
+			current: &vpclattice.HealthCheckConfig{
+				Enabled:                    aws.Bool(true),
+				HealthCheckIntervalSeconds: aws.Int64(10),
+				HealthCheckTimeoutSeconds:  aws.Int64(5),
+				HealthyThresholdCount:      aws.Int64(5),
+				UnhealthyThresholdCount:    aws.Int64(2),
+				Matcher:                    &vpclattice.Matcher{HttpCode: aws.String("200")},
+				Path:                       aws.String("/health"),
+				Port:                       aws.Int64(8080),
+				Protocol:                   aws.String("HTTP"),
+				ProtocolVersion:            aws.String("HTTP1"),
+			},
+			wantUpdate: true,
+		},
+		{
+			name: "default health check on the traffic port is kept",
+			current: &vpclattice.HealthCheckConfig{
+				Enabled:                    aws.Bool(true),
+				HealthCheckIntervalSeconds: aws.Int64(30),
+				HealthCheckTimeoutSeconds:  aws.Int64(5),
+				HealthyThresholdCount:      aws.Int64(5),
+				UnhealthyThresholdCount:    aws.Int64(2),
+				Matcher:                    &vpclattice.Matcher{HttpCode: aws.String("200")},
+				Path:                       aws.String("/"),
+				Protocol:                   aws.String("HTTP"),
+				ProtocolVersion:            aws.String("HTTP1"),
+			},
+			wantUpdate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getTGOutput := &vpclattice.GetTargetGroupOutput{
+				Config: &vpclattice.TargetGroupConfig{
+					Port:        aws.Int64(80),
+					Protocol:    aws.String("HTTP"),
+					HealthCheck: tt.current,
+				},
+			}
+
+			mockCloud := mocks_aws.NewMockCloud(c)
+			mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTgOutput, nil)
+			mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(getTGOutput, nil)
+			if tt.wantUpdate {
+				updateTGInput := &vpclattice.UpdateTargetGroupInput{
+					HealthCheck: &vpclattice.HealthCheckConfig{
+						Enabled:                    aws.Bool(true),
+						HealthCheckIntervalSeconds: aws.Int64(30),
+						HealthCheckTimeoutSeconds:  aws.Int64(5),
+						HealthyThresholdCount:      aws.Int64(5),
+						UnhealthyThresholdCount:    aws.Int64(2),
+						Matcher:                    &vpclattice.Matcher{HttpCode: aws.String("200")},
+						Path:                       aws.String("/"),
+						Port:                       aws.Int64(80),
+						Protocol:                   aws.String("HTTP"),
+						ProtocolVersion:            aws.String("HTTP1"),
+					},
+					TargetGroupIdentifier: &id,
+				}
+				mockVpcLatticeSess.EXPECT().UpdateTargetGroupWithContext(ctx, updateTGInput).Return(&vpclattice.UpdateTargetGroupOutput{}, nil)
+			}
+			mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+			tgManager := NewTargetGroupManager(mockCloud)
+			resp, err := tgManager.Create(ctx, &tgCreateInput)
+
+			assert.Nil(t, err)
+			assert.Equal(t, resp.TargetGroupARN, arn)
+			assert.Equal(t, resp.TargetGroupID, id)
+		})
+	}
+}
+
+func Test_isHealthCheckConfigSame(t *testing.T) {
+	current := &vpclattice.HealthCheckConfig{
+		Enabled:                    aws.Bool(true),
+		HealthCheckIntervalSeconds: aws.Int64(30),
+		Path:                       aws.String("/"),
+		Matcher: &vpclattice.Matcher{
+			HttpCode: aws.String("200"),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		desired *vpclattice.HealthCheckConfig
+		current *vpclattice.HealthCheckConfig
+		want    bool
+	}{
+		{
+			name:    "no current health check",
+			desired: &vpclattice.HealthCheckConfig{Enabled: aws.Bool(true)},
+			current: nil,
+			want:    false,
+		},
+		{
+			name:    "unset fields are ignored",
+			desired: &vpclattice.HealthCheckConfig{Path: aws.String("/")},
+			current: current,
+			want:    true,
+		},
+		{
+			name: "same",
+			desired: &vpclattice.HealthCheckConfig{
+				Enabled:                    aws.Bool(true),
+				HealthCheckIntervalSeconds: aws.Int64(30),
+				Matcher: &vpclattice.Matcher{
+					HttpCode: aws.String("200"),
+				},
+			},
+			current: current,
+			want:    true,
+		},
+		{
+			name:    "interval differs",
+			desired: &vpclattice.HealthCheckConfig{HealthCheckIntervalSeconds: aws.Int64(10)},
+			current: current,
+			want:    false,
+		},
+		{
+			name: "matcher differs",
+			desired: &vpclattice.HealthCheckConfig{
+				Matcher: &vpclattice.Matcher{
+					HttpCode: aws.String("200-299"),
+				},
+			},
+			current: current,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isHealthCheckConfigSame(tt.desired, tt.current))
+		})
+	}
+}
+
 // target group status is create-in-progress before creation, return Retry
 func Test_CreateTargetGroup_TGCreateInProgress_Retry(t *testing.T) {
 	c := gomock.NewController(t)
@@ -832,6 +1262,52 @@ func Test_ListTG_TGsExist(t *testing.T) {
 	assert.Equal(t, tgList, expect)
 }
 
+func Test_ListTG_LambdaTGs(t *testing.T) {
+	arn := "123456789"
+	id := "123456789"
+	name1 := "test1-lambda"
+	name2 := "test2-lambda"
+	listTGOutput := []*vpclattice.TargetGroupSummary{
+		{Arn: &arn, Id: &id, Name: &name1},
+		{Arn: &arn, Id: &id, Name: &name2},
+	}
+
+	lambdaType := string(latticemodel.TargetGroupTypeLambda)
+	getTG := &vpclattice.GetTargetGroupOutput{
+		Type: &lambdaType,
+	}
+	otherVpcID := "other-vpc"
+	tags1 := &vpclattice.ListTagsForResourceOutput{
+		Tags: map[string]*string{latticemodel.K8SClusterVpcIDKey: &config.VpcID},
+	}
+	tags2 := &vpclattice.ListTagsForResourceOutput{
+		Tags: map[string]*string{latticemodel.K8SClusterVpcIDKey: &otherVpcID},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTGOutput, nil)
+	mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(getTG, nil).Times(2)
+	mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, gomock.Any()).Return(tags1, nil)
+	mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, gomock.Any()).Return(tags2, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	tgManager := NewTargetGroupManager(mockCloud)
+	tgList, err := tgManager.List(ctx)
+
+	// only the lambda target group of the cluster is listed
+	assert.Nil(t, err)
+	assert.Equal(t, []targetGroupOutput{
+		{
+			getTargetGroupOutput: *getTG,
+			targetGroupTags:      tags1,
+		},
+	}, tgList)
+}
+
 func Test_ListTG_NoTG(t *testing.T) {
 	listTGOutput := []*vpclattice.TargetGroupSummary{}
 
@@ -934,6 +1410,30 @@ func Test_Get(t *testing.T) {
 			tgStatus:       vpclattice.TargetGroupStatusDeleteInProgress,
 			tgStatusFailed: vpclattice.TargetGroupStatusDeleteFailed,
 		},
+		{
+			// the exported target group has a non default protocol version
+			wantErr: nil,
+			tgId:    "tg-id-012345",
+			tgArn:   "tg-arn-123456",
+			tgName:  "tg-test-1-grpc",
+			input: &latticemodel.TargetGroup{
+				ResourceMeta: core.ResourceMeta{},
+				Spec: latticemodel.TargetGroupSpec{
+					Name:      "tg-test-1",
+					Config:    latticemodel.TargetGroupConfig{},
+					Type:      "",
+					IsDeleted: false,
+					LatticeID: "",
+				},
+				Status: nil,
+			},
+			wantOutput:     latticemodel.TargetGroupStatus{TargetGroupARN: "tg-arn-123456", TargetGroupID: "tg-id-012345"},
+			randomArn:      "random-tg-arn-12345",
+			randomId:       "random-tg-id-12345",
+			randomName:     "tgrandom-1",
+			tgStatus:       vpclattice.TargetGroupStatusActive,
+			tgStatusFailed: vpclattice.TargetGroupStatusCreateFailed,
+		},
 		{
 			wantErr: errors.New("Non existing Target Group"),
 			tgId:    "tg-id-012345",
@@ -1000,3 +1500,360 @@ func Test_Get(t *testing.T) {
 		}
 	}
 }
+
+func Test_getLatticeTGName(t *testing.T) {
+	tests := []struct {
+		name     string
+		tgConfig latticemodel.TargetGroupConfig
+		want     string
+	}{
+		{
+			name:     "default protocol and protocol version",
+			tgConfig: latticemodel.TargetGroupConfig{Protocol: "HTTP", ProtocolVersion: "HTTP1"},
+			want:     "k8s-svc-ns",
+		},
+		{
+			name:     "unset protocol version",
+			tgConfig: latticemodel.TargetGroupConfig{Protocol: "HTTP"},
+			want:     "k8s-svc-ns",
+		},
+		{
+			name:     "HTTP2",
+			tgConfig: latticemodel.TargetGroupConfig{Protocol: "HTTP", ProtocolVersion: "HTTP2"},
+			want:     "k8s-svc-ns-http2",
+		},
+		{
+			name:     "HTTPS and GRPC",
+			tgConfig: latticemodel.TargetGroupConfig{Protocol: "HTTPS", ProtocolVersion: "GRPC"},
+			want:     "k8s-svc-ns-https-grpc",
+		},
+		{
+			name:     "TCP",
+			tgConfig: latticemodel.TargetGroupConfig{Protocol: "TCP"},
+			want:     "k8s-svc-ns-tcp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := &latticemodel.TargetGroup{
+				Spec: latticemodel.TargetGroupSpec{
+					Name:   "k8s-svc-ns",
+					Config: tt.tgConfig,
+				},
+			}
+			name := getLatticeTGName(tg)
+			assert.Equal(t, tt.want, name)
+			assert.Contains(t, latticeTGNameSuffixes, name[len("k8s-svc-ns"):])
+		})
+	}
+}
+
+func Test_ListExported(t *testing.T) {
+	ipType := string(latticemodel.TargetGroupTypeIP)
+	lambdaType := string(latticemodel.TargetGroupTypeLambda)
+	activeStatus := vpclattice.TargetGroupStatusActive
+	listTGOutput := []*vpclattice.TargetGroupSummary{
+		{Arn: aws.String("arn1"), Id: aws.String("tg-1"), Name: aws.String("k8s-export-ns1"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-1"), Status: &activeStatus, Protocol: aws.String("HTTP")},
+		{Arn: aws.String("arn2"), Id: aws.String("tg-2"), Name: aws.String("k8s-export-ns1-http2"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-2"), Status: &activeStatus, Protocol: aws.String("HTTP")},
+		{Arn: aws.String("arn3"), Id: aws.String("tg-3"), Name: aws.String("k8s-export-ns1-route"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-1"), Status: &activeStatus, Protocol: aws.String("HTTP")},
+		{Arn: aws.String("arn4"), Id: aws.String("tg-4"), Name: aws.String("k8s-export-ns1-lambda"), Type: &lambdaType},
+		{Arn: aws.String("arn5"), Id: aws.String("tg-5"), Name: aws.String("k8s-other-ns1"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-1"), Status: &activeStatus, Protocol: aws.String("HTTP")},
+	}
+
+	exportTags := func(name string, ports string) map[string]*string {
+		tags := map[string]*string{
+			latticemodel.K8SServiceNameKey:      aws.String(name),
+			latticemodel.K8SServiceNamespaceKey: aws.String("ns1"),
+			latticemodel.K8SParentRefTypeKey:    aws.String(latticemodel.K8SServiceExportType),
+		}
+		if ports != "" {
+			tags[latticemodel.K8SServiceExportPortsKey] = aws.String(ports)
+		}
+		return tags
+	}
+	tags1 := exportTags("export", "80,8080")
+	tags1[latticemodel.K8SServiceExportWeightKey] = aws.String("80")
+	tags2 := exportTags("export", "")
+	// target group of a route using the service as backend
+	tags3 := map[string]*string{
+		latticemodel.K8SServiceNameKey:      aws.String("export"),
+		latticemodel.K8SServiceNamespaceKey: aws.String("ns1"),
+		latticemodel.K8SParentRefTypeKey:    aws.String(latticemodel.K8SHTTPRouteType),
+	}
+	tags5 := exportTags("other", "80")
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTGOutput, nil)
+	for arn, tags := range map[string]map[string]*string{"arn1": tags1, "arn2": tags2, "arn3": tags3, "arn5": tags5} {
+		mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+			ResourceArn: aws.String(arn),
+		}).Return(&vpclattice.ListTagsForResourceOutput{Tags: tags}, nil)
+	}
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	tgManager := NewTargetGroupManager(mockCloud)
+	exportedTGs, err := tgManager.ListExported(ctx, "export", "ns1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []ExportedTargetGroup{
+		{ID: "tg-1", ARN: "arn1", Name: "k8s-export-ns1", VpcID: "vpc-1", Status: activeStatus, Protocol: "HTTP",
+			Ports: []int32{80, 8080}, Weight: 80, Tags: tags1},
+		{ID: "tg-2", ARN: "arn2", Name: "k8s-export-ns1-http2", VpcID: "vpc-2", Status: activeStatus, Protocol: "HTTP",
+			Weight: 1, Tags: tags2},
+	}, exportedTGs)
+}
+
+func Test_ListAllExported(t *testing.T) {
+	ipType := string(latticemodel.TargetGroupTypeIP)
+	listTGOutput := []*vpclattice.TargetGroupSummary{
+		{Arn: aws.String("arn1"), Id: aws.String("tg-1"), Name: aws.String("k8s-export1-ns1"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-1")},
+		{Arn: aws.String("arn2"), Id: aws.String("tg-2"), Name: aws.String("k8s-export2-ns2"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-2")},
+		{Arn: aws.String("arn3"), Id: aws.String("tg-3"), Name: aws.String("k8s-svc-ns1-route"), Type: &ipType,
+			VpcIdentifier: aws.String("vpc-1")},
+	}
+	tags1 := map[string]*string{
+		latticemodel.K8SServiceNameKey:      aws.String("export1"),
+		latticemodel.K8SServiceNamespaceKey: aws.String("ns1"),
+		latticemodel.K8SParentRefTypeKey:    aws.String(latticemodel.K8SServiceExportType),
+	}
+	tags2 := map[string]*string{
+		latticemodel.K8SServiceNameKey:        aws.String("export2"),
+		latticemodel.K8SServiceNamespaceKey:   aws.String("ns2"),
+		latticemodel.K8SParentRefTypeKey:      aws.String(latticemodel.K8SServiceExportType),
+		latticemodel.K8SServiceExportPortsKey: aws.String("80"),
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTGOutput, nil)
+	mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+		ResourceArn: aws.String("arn1"),
+	}).Return(&vpclattice.ListTagsForResourceOutput{Tags: tags1}, nil)
+	mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+		ResourceArn: aws.String("arn2"),
+	}).Return(&vpclattice.ListTagsForResourceOutput{Tags: tags2}, nil)
+	// tags of the route target group cannot be read
+	mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, &vpclattice.ListTagsForResourceInput{
+		ResourceArn: aws.String("arn3"),
+	}).Return(nil, errors.New("no tags"))
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	tgManager := NewTargetGroupManager(mockCloud)
+	exportedTGs, err := tgManager.ListAllExported(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []ExportedTargetGroup{
+		{ID: "tg-1", ARN: "arn1", Name: "k8s-export1-ns1", VpcID: "vpc-1", Weight: 1, Tags: tags1},
+		{ID: "tg-2", ARN: "arn2", Name: "k8s-export2-ns2", VpcID: "vpc-2", Ports: []int32{80}, Weight: 1, Tags: tags2},
+	}, exportedTGs)
+}
+
+func Test_ListExported_ListError(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(nil, errors.New("list error"))
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess)
+
+	tgManager := NewTargetGroupManager(mockCloud)
+	_, err := tgManager.ListExported(ctx, "export", "ns1")
+
+	assert.NotNil(t, err)
+}
+
+func Test_CreateTargetGroup_ExportPortsTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		existingTag *string
+		wantTag     bool
+	}{
+		{
+			name:        "ports tag up to date",
+			existingTag: aws.String("80,8080"),
+			wantTag:     false,
+		},
+		{
+			name:        "ports tag out of date",
+			existingTag: aws.String("80"),
+			wantTag:     true,
+		},
+		{
+			name:        "ports tag missing",
+			existingTag: nil,
+			wantTag:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+			mockVpcLatticeSess := mocks.NewMockLattice(c)
+			mockCloud := mocks_aws.NewMockCloud(c)
+			mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+			tgSpec := latticemodel.TargetGroupSpec{
+				Name: "export",
+				Type: latticemodel.TargetGroupTypeIP,
+				Config: latticemodel.TargetGroupConfig{
+					IsServiceExport:       true,
+					K8SServiceName:        "export",
+					K8SServiceNamespace:   "ns1",
+					K8SServiceExportPorts: []int32{8080, 80},
+				},
+			}
+			tg := latticemodel.NewTargetGroup(core.NewDefaultStack(core.StackID{Name: "foo", Namespace: "bar"}), "export", tgSpec)
+
+			activeStatus := vpclattice.TargetGroupStatusActive
+			listTGOutput := []*vpclattice.TargetGroupSummary{
+				{Arn: aws.String("arn"), Id: aws.String("tg-id"), Name: aws.String("export"), Status: &activeStatus},
+			}
+			mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTGOutput, nil)
+			mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(&vpclattice.GetTargetGroupOutput{
+				Config: &vpclattice.TargetGroupConfig{HealthCheck: defaultHealthCheckConfig(tgSpec.Config)},
+			}, nil)
+			tags := map[string]*string{}
+			if tt.existingTag != nil {
+				tags[latticemodel.K8SServiceExportPortsKey] = tt.existingTag
+			}
+			mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, gomock.Any()).Return(
+				&vpclattice.ListTagsForResourceOutput{Tags: tags}, nil)
+			if tt.wantTag {
+				mockVpcLatticeSess.EXPECT().TagResourceWithContext(ctx, &vpclattice.TagResourceInput{
+					ResourceArn: aws.String("arn"),
+					Tags:        map[string]*string{latticemodel.K8SServiceExportPortsKey: aws.String("80,8080")},
+				}).Return(&vpclattice.TagResourceOutput{}, nil)
+			}
+
+			tgManager := NewTargetGroupManager(mockCloud)
+			resp, err := tgManager.Create(ctx, tg)
+
+			assert.Nil(t, err)
+			assert.Equal(t, "arn", resp.TargetGroupARN)
+		})
+	}
+}
+
+func Test_CreateTargetGroup_ExportWeightTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		weight      *int64
+		existingTag *string
+		wantTag     *string
+		wantUntag   bool
+	}{
+		{
+			name:        "weight tag up to date",
+			weight:      aws.Int64(80),
+			existingTag: aws.String("80"),
+		},
+		{
+			name:        "weight tag out of date",
+			weight:      aws.Int64(20),
+			existingTag: aws.String("80"),
+			wantTag:     aws.String("20"),
+		},
+		{
+			name:    "weight tag missing",
+			weight:  aws.Int64(0),
+			wantTag: aws.String("0"),
+		},
+		{
+			name:        "weight annotation removed",
+			existingTag: aws.String("80"),
+			wantUntag:   true,
+		},
+		{
+			name: "no weight",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+			mockVpcLatticeSess := mocks.NewMockLattice(c)
+			mockCloud := mocks_aws.NewMockCloud(c)
+			mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+			tgSpec := latticemodel.TargetGroupSpec{
+				Name: "export",
+				Type: latticemodel.TargetGroupTypeIP,
+				Config: latticemodel.TargetGroupConfig{
+					IsServiceExport:        true,
+					K8SServiceName:         "export",
+					K8SServiceNamespace:    "ns1",
+					K8SServiceExportPorts:  []int32{80},
+					K8SServiceExportWeight: tt.weight,
+				},
+			}
+			tg := latticemodel.NewTargetGroup(core.NewDefaultStack(core.StackID{Name: "foo", Namespace: "bar"}), "export", tgSpec)
+
+			activeStatus := vpclattice.TargetGroupStatusActive
+			listTGOutput := []*vpclattice.TargetGroupSummary{
+				{Arn: aws.String("arn"), Id: aws.String("tg-id"), Name: aws.String("export"), Status: &activeStatus},
+			}
+			mockVpcLatticeSess.EXPECT().ListTargetGroupsAsList(ctx, gomock.Any()).Return(listTGOutput, nil)
+			mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, gomock.Any()).Return(&vpclattice.GetTargetGroupOutput{
+				Config: &vpclattice.TargetGroupConfig{HealthCheck: defaultHealthCheckConfig(tgSpec.Config)},
+			}, nil)
+			tags := map[string]*string{latticemodel.K8SServiceExportPortsKey: aws.String("80")}
+			if tt.existingTag != nil {
+				tags[latticemodel.K8SServiceExportWeightKey] = tt.existingTag
+			}
+			mockVpcLatticeSess.EXPECT().ListTagsForResourceWithContext(ctx, gomock.Any()).Return(
+				&vpclattice.ListTagsForResourceOutput{Tags: tags}, nil)
+			if tt.wantTag != nil {
+				mockVpcLatticeSess.EXPECT().TagResourceWithContext(ctx, &vpclattice.TagResourceInput{
+					ResourceArn: aws.String("arn"),
+					Tags:        map[string]*string{latticemodel.K8SServiceExportWeightKey: tt.wantTag},
+				}).Return(&vpclattice.TagResourceOutput{}, nil)
+			}
+			if tt.wantUntag {
+				mockVpcLatticeSess.EXPECT().UntagResourceWithContext(ctx, &vpclattice.UntagResourceInput{
+					ResourceArn: aws.String("arn"),
+					TagKeys:     []*string{aws.String(latticemodel.K8SServiceExportWeightKey)},
+				}).Return(&vpclattice.UntagResourceOutput{}, nil)
+			}
+
+			tgManager := NewTargetGroupManager(mockCloud)
+			resp, err := tgManager.Create(ctx, tg)
+
+			assert.Nil(t, err)
+			assert.Equal(t, "arn", resp.TargetGroupARN)
+		})
+	}
+}
+
+func Test_parseExportWeight(t *testing.T) {
+	assert.Equal(t, int64(80), parseExportWeight(aws.String("80")))
+	assert.Equal(t, int64(0), parseExportWeight(aws.String("0")))
+	assert.Equal(t, k8s.DefaultServiceExportWeight, parseExportWeight(nil))
+	assert.Equal(t, k8s.DefaultServiceExportWeight, parseExportWeight(aws.String("foo")))
+	assert.Equal(t, k8s.DefaultServiceExportWeight, parseExportWeight(aws.String("1000")))
+}
+
+func Test_parseExportPorts(t *testing.T) {
+	assert.Equal(t, []int32{80, 8080}, parseExportPorts("80,8080"))
+	assert.Equal(t, []int32{80}, parseExportPorts("80,foo"))
+	assert.Nil(t, parseExportPorts(""))
+	assert.Equal(t, "80,443,8080", formatExportPorts([]int32{8080, 80, 443}))
+}
@@ -3,17 +3,23 @@ package lattice
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
 	"github.com/golang/glog"
 
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
@@ -35,6 +41,8 @@ type targetGroupSynthesizer struct {
 	targetGroupManager TargetGroupManager
 	stack              core.Stack
 	latticeDataStore   *latticestore.LatticeDataStore
+	// replacedSDKTGs are still in use until the rules are repointed to their replacements
+	replacedSDKTGs []latticemodel.TargetGroup
 }
 
 func (t *targetGroupSynthesizer) Synthesize(ctx context.Context) error {
@@ -100,10 +108,17 @@ func (t *targetGroupSynthesizer) SynthesizeTriggeredTargetGroup(ctx context.Cont
 				//Ingnore TG delete since this is an import from elsewhere
 				continue
 			}
-			tgStatus, err := t.targetGroupManager.Get(ctx, resTargetGroup)
-
+			// the service may be exported by the clusters of several vpcs, traffic is split across all of them
+			exportedTGs, err := t.targetGroupManager.ListExported(ctx, resTargetGroup.Spec.Config.K8SServiceName,
+				resTargetGroup.Spec.Config.K8SServiceNamespace)
 			if err != nil {
-				glog.V(6).Infof("Error on t.targetGroupManager.Get for %v err %v\n", resTargetGroup, err)
+				glog.V(6).Infof("Error on t.targetGroupManager.ListExported for %v err %v\n", resTargetGroup, err)
+				returnErr = true
+				continue
+			}
+			clusters := buildClusterTargetGroups(ActiveExportedTargetGroups(exportedTGs, resTargetGroup.Spec.Config.VpcID))
+			if len(clusters) == 0 {
+				glog.V(6).Infof("No active exported target group found for %v\n", resTargetGroup)
 				returnErr = true
 				continue
 			}
@@ -111,10 +126,12 @@ func (t *targetGroupSynthesizer) SynthesizeTriggeredTargetGroup(ctx context.Cont
 			// for serviceimport, the httproutename is ""
 
 			t.latticeDataStore.AddTargetGroup(resTargetGroup.Spec.Name,
-				resTargetGroup.Spec.Config.VpcID, tgStatus.TargetGroupARN, tgStatus.TargetGroupID,
+				resTargetGroup.Spec.Config.VpcID, clusters[0].ARN, clusters[0].ID,
 				resTargetGroup.Spec.Config.IsServiceImport, "")
+			t.latticeDataStore.SetTargetGroupClusters(resTargetGroup.Spec.Name, "",
+				resTargetGroup.Spec.Config.IsServiceImport, clusters)
 
-			glog.V(6).Infof("targetGroup Synthesized successfully for %s: %v\n", resTargetGroup.Spec.Name, tgStatus)
+			glog.V(6).Infof("targetGroup Synthesized successfully for %s: %v\n", resTargetGroup.Spec.Name, clusters)
 
 		} else {
 			if resTargetGroup.Spec.IsDeleted {
@@ -175,7 +192,9 @@ func (t *targetGroupSynthesizer) SynthesizeSDKTargetGroups(ctx context.Context)
 	for _, sdkTG := range sdkTGs {
 		tgRouteName := ""
 
-		if *sdkTG.getTargetGroupOutput.Config.VpcIdentifier != config.VpcID {
+		// lambda target groups are not in a vpc, the manager only lists the ones of this cluster
+		isLambda := aws.StringValue(sdkTG.getTargetGroupOutput.Type) == string(latticemodel.TargetGroupTypeLambda)
+		if !isLambda && aws.StringValue(sdkTG.getTargetGroupOutput.Config.VpcIdentifier) != config.VpcID {
 			glog.V(6).Infof("Ignore target group ARN %v Name %v for other VPCs",
 				*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
 			continue
@@ -213,6 +232,14 @@ func (t *targetGroupSynthesizer) SynthesizeSDKTargetGroups(ctx context.Context)
 			continue
 		}
 
+		// the target group of a backendRef with a port is tagged with the service port
+		var srvPort int32
+		if portTag, ok := tgTags.Tags[latticemodel.K8SServicePortKey]; ok && portTag != nil {
+			if port, err := strconv.ParseInt(*portTag, 10, 32); err == nil {
+				srvPort = int32(port)
+			}
+		}
+
 		// if its parentref is service export,  check the parent service export exist
 		// Ignore if service export exists
 		if *parentRef == latticemodel.K8SServiceExportType {
@@ -228,6 +255,11 @@ func (t *targetGroupSynthesizer) SynthesizeSDKTargetGroups(ctx context.Context)
 			}
 			srvExport := &mcs_api.ServiceExport{}
 			if err := t.client.Get(ctx, srvExportName, srvExport); err == nil {
+				// for serviceexport, the routename is ""
+				if t.isTargetGroupReplaced(sdkTG, latticestore.TargetGroupName(*srvName, *srvNamespace), "") {
+					t.addReplacedSDKTG(sdkTG, "")
+					continue
+				}
 
 				glog.V(6).Infof("Ignore TargetGroup(triggered by serviceexport) %v, %v since serviceexport object is found",
 					*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
@@ -235,53 +267,71 @@ func (t *targetGroupSynthesizer) SynthesizeSDKTargetGroups(ctx context.Context)
 			}
 		}
 
-		// if its parentref is HTTP/route, check the parent HTTPRoute exist
-		// Ignore if httpRoute does NOT exist
-		if *parentRef == latticemodel.K8SHTTPRouteType {
-			glog.V(6).Infof("TargetGroup %v, %v is referenced by HTTPRoute",
-				*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
+		// if its parentref is HTTP/route, GRPC/route or TLS/route, check the parent route exist
+		// Ignore if route does NOT exist
+		if *parentRef == latticemodel.K8SHTTPRouteType || *parentRef == latticemodel.K8SGRPCRouteType ||
+			*parentRef == latticemodel.K8STLSRouteType {
+			glog.V(6).Infof("TargetGroup %v, %v is referenced by %v",
+				*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name, *parentRef)
 
 			httpName, ok := tgTags.Tags[latticemodel.K8SHTTPRouteNameKey]
-			tgRouteName = *httpName
 
 			if !ok || httpName == nil {
-				glog.V(6).Infof("Ignore TargetGroup(triggered by httpRoute) %v, %v have no httproute name tag",
+				glog.V(6).Infof("Ignore TargetGroup(triggered by route) %v, %v have no route name tag",
 					*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
 				continue
 			}
+			tgRouteName = *httpName
 
 			httpNamespace, ok := tgTags.Tags[latticemodel.K8SHTTPRouteNamespaceKey]
 
 			if !ok || httpNamespace == nil {
-				glog.V(6).Infof("Ignore TargetGroup(triggered by httpRoute) %v, %v have no httproute namespace tag",
+				glog.V(6).Infof("Ignore TargetGroup(triggered by route) %v, %v have no route namespace tag",
 					*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
 				continue
 			}
 
-			httprouteName := types.NamespacedName{
+			routeName := types.NamespacedName{
 				Namespace: *httpNamespace,
 				Name:      *httpName,
 			}
 
-			httpRoute := &gateway_api.HTTPRoute{}
+			var route client.Object = &gateway_api.HTTPRoute{}
+			switch *parentRef {
+			case latticemodel.K8SGRPCRouteType:
+				route = &gateway_api_v1alpha2.GRPCRoute{}
+			case latticemodel.K8STLSRouteType:
+				route = &gateway_api_v1alpha2.TLSRoute{}
+			}
 
-			tgName := latticestore.TargetGroupName(*srvName, *srvNamespace)
+			tgName := latticestore.TargetGroupPortName(*srvName, *srvNamespace, srvPort)
+			if isLambda {
+				tgName = latticestore.LambdaTargetGroupName(*srvName, *srvNamespace)
+			}
 
-			if err := t.client.Get(ctx, httprouteName, httpRoute); err != nil {
-				glog.V(6).Infof("tgname %v is not used by httproute %v\n", tgName, httpRoute)
+			if err := t.client.Get(ctx, routeName, route); err != nil {
+				glog.V(6).Infof("tgname %v is not used by route %v\n", tgName, routeName)
 
 			} else {
 
-				isUsed := t.isTargetGroupUsedByaHTTPRoute(ctx, tgName, httpRoute)
+				isUsed := t.isTargetGroupUsedByRoute(ctx, tgName, route)
 
 				if isUsed {
+					if t.isTargetGroupReplaced(sdkTG, tgName, tgRouteName) {
+						t.addReplacedSDKTG(sdkTG, tgRouteName)
+						continue
+					}
 
-					glog.V(6).Infof("Ignore TargetGroup(triggered by HTTProute) %v, %v since httproute object is found",
+					glog.V(6).Infof("Ignore TargetGroup(triggered by route) %v, %v since route object is found",
 						*sdkTG.getTargetGroupOutput.Arn, *sdkTG.getTargetGroupOutput.Name)
 
+					continue
+				} else if srvPort == 0 && !isLambda && t.isLegacyTargetGroupOfRoute(tgName, route) {
+					// the previous release named the target group of a backendRef with a port without the port
+					t.addReplacedSDKTG(sdkTG, tgRouteName)
 					continue
 				} else {
-					glog.V(6).Infof("tgname %v is not used by httproute %v\n", tgName, httpRoute)
+					glog.V(6).Infof("tgname %v is not used by route %v\n", tgName, routeName)
 				}
 			}
 
@@ -332,30 +382,209 @@ func (t *targetGroupSynthesizer) SynthesizeSDKTargetGroups(ctx context.Context)
 
 }
 
-func (t *targetGroupSynthesizer) isTargetGroupUsedByaHTTPRoute(ctx context.Context, tgName string, httpRoute *gateway_api.HTTPRoute) bool {
+func (t *targetGroupSynthesizer) isTargetGroupUsedByRoute(ctx context.Context, tgName string, route client.Object) bool {
 
-	for _, httpRule := range httpRoute.Spec.Rules {
-		for _, httpBackendRef := range httpRule.BackendRefs {
-			if string(*httpBackendRef.BackendObjectReference.Kind) != "Service" {
-				continue
-			}
-			namespace := httpRoute.Namespace
-			if httpBackendRef.BackendObjectReference.Namespace != nil {
-				namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-			}
-			refTGName := latticestore.TargetGroupName(string(httpBackendRef.BackendObjectReference.Name), namespace)
+	routeInfo, ok := k8s.NewRouteInfo(route)
+	if !ok {
+		return false
+	}
 
-			if tgName == refTGName {
-				return true
-			}
+	for _, backendRef := range routeInfo.BackendRefs {
+		isLambda := k8s.IsLambdaTargetBackendRef(backendRef.BackendObjectReference)
+		if !isLambda && backendRef.Kind != nil && string(*backendRef.Kind) != "Service" {
+			continue
+		}
+		namespace := routeInfo.Namespace
+		if backendRef.Namespace != nil {
+			namespace = string(*backendRef.Namespace)
+		}
+		var port int32
+		if backendRef.Port != nil {
+			port = int32(*backendRef.Port)
+		}
+		refTGName := latticestore.TargetGroupPortName(string(backendRef.Name), namespace, port)
+		if isLambda {
+			refTGName = latticestore.LambdaTargetGroupName(string(backendRef.Name), namespace)
+		}
+
+		if tgName == refTGName {
+			return true
+		}
+	}
 
+	return false
+}
+
+// isLegacyTargetGroupOfRoute returns whether the target group has the name the previous release gave the target
+// group of a backendRef of the route with a port. It is replaced by the target group named with the port then
+func (t *targetGroupSynthesizer) isLegacyTargetGroupOfRoute(tgName string, route client.Object) bool {
+	routeInfo, ok := k8s.NewRouteInfo(route)
+	if !ok {
+		return false
+	}
+
+	for _, backendRef := range routeInfo.BackendRefs {
+		if backendRef.Port == nil || (backendRef.Kind != nil && string(*backendRef.Kind) != "Service") {
+			continue
 		}
+		namespace := routeInfo.Namespace
+		if backendRef.Namespace != nil {
+			namespace = string(*backendRef.Namespace)
+		}
+		if tgName == latticestore.TargetGroupName(string(backendRef.Name), namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTargetGroupReplaced returns whether the lattice target group no longer has the protocol or protocol version of
+// its target group in the stack, e.g. because the appProtocol of the service changed. Both are immutable, so a new
+// lattice target group replaces it. Lattice is checked rather than the datastore, which is empty after a restart
+func (t *targetGroupSynthesizer) isTargetGroupReplaced(sdkTG targetGroupOutput, tgName string, routeName string) bool {
+	sdkConfig := sdkTG.getTargetGroupOutput.Config
+	if t.stack == nil || sdkConfig == nil {
+		return false
 	}
 
+	var resTargetGroups []*latticemodel.TargetGroup
+	t.stack.ListResources(&resTargetGroups)
+	for _, resTargetGroup := range resTargetGroups {
+		spec := resTargetGroup.Spec
+		if spec.Name != tgName || spec.Config.K8SHTTPRouteName != routeName || spec.IsDeleted ||
+			spec.Config.IsServiceImport || spec.Type == latticemodel.TargetGroupTypeLambda {
+			continue
+		}
+
+		protocolVersion := spec.Config.ProtocolVersion
+		if spec.Config.Protocol == vpclattice.TargetGroupProtocolTcp {
+			// TCP target groups have no protocol version
+			protocolVersion = ""
+		}
+		return aws.StringValue(sdkConfig.Protocol) != spec.Config.Protocol ||
+			aws.StringValue(sdkConfig.ProtocolVersion) != protocolVersion
+	}
 	return false
 }
 
+func (t *targetGroupSynthesizer) addReplacedSDKTG(sdkTG targetGroupOutput, routeName string) {
+	glog.V(2).Infof("Target group Name %v, ARN %v is replaced, delete it once it is no longer referenced\n",
+		aws.StringValue(sdkTG.getTargetGroupOutput.Name), aws.StringValue(sdkTG.getTargetGroupOutput.Arn))
+
+	t.replacedSDKTGs = append(t.replacedSDKTGs, latticemodel.TargetGroup{
+		Spec: latticemodel.TargetGroupSpec{
+			Name: aws.StringValue(sdkTG.getTargetGroupOutput.Name),
+			Config: latticemodel.TargetGroupConfig{
+				K8SHTTPRouteName: routeName,
+			},
+			LatticeID: aws.StringValue(sdkTG.getTargetGroupOutput.Id),
+		},
+	})
+}
+
+// PostSynthesize deletes the replaced target groups, rules have been repointed to their replacements by now.
+// Once no service references a replaced target group its targets are deregistered, it is deleted after they
+// finished draining
 func (t *targetGroupSynthesizer) PostSynthesize(ctx context.Context) error {
-	// nothing to do here
+	retry := false
+
+	for _, replacedTG := range t.replacedSDKTGs {
+		getTGInput := vpclattice.GetTargetGroupInput{
+			TargetGroupIdentifier: aws.String(replacedTG.Spec.LatticeID),
+		}
+		resp, err := t.cloud.Lattice().GetTargetGroupWithContext(ctx, &getTGInput)
+		if err != nil {
+			glog.V(2).Infof("Failed to get replaced target group %v, err %v\n", replacedTG.Spec.LatticeID, err)
+			retry = true
+			continue
+		}
+
+		if len(resp.ServiceArns) > 0 {
+			glog.V(2).Infof("Replaced target group %v is still referenced by %v, retry later\n",
+				replacedTG.Spec.Name, aws.StringValueSlice(resp.ServiceArns))
+			retry = true
+			continue
+		}
+
+		drained, err := t.drainTargetGroup(ctx, replacedTG.Spec.LatticeID)
+		if err != nil {
+			glog.V(2).Infof("Failed to drain replaced target group %v, err %v\n", replacedTG.Spec.Name, err)
+			retry = true
+			continue
+		}
+		if !drained {
+			glog.V(2).Infof("Targets of replaced target group %v are draining, retry later\n", replacedTG.Spec.Name)
+			retry = true
+			continue
+		}
+
+		if err := t.targetGroupManager.Delete(ctx, &replacedTG); err != nil {
+			glog.V(2).Infof("Failed to delete replaced target group %v, err %v\n", replacedTG.Spec.Name, err)
+			retry = true
+		}
+	}
+
+	if retry {
+		return errors.New(LATTICE_RETRY)
+	}
 	return nil
 }
+
+// drainTargetGroup deregisters the targets of the target group and returns whether none is left, targets stay
+// listed as DRAINING until the connections to them are closed
+func (t *targetGroupSynthesizer) drainTargetGroup(ctx context.Context, tgID string) (bool, error) {
+	listTargetsInput := vpclattice.ListTargetsInput{
+		TargetGroupIdentifier: aws.String(tgID),
+	}
+	targets, err := t.cloud.Lattice().ListTargetsAsList(ctx, &listTargetsInput)
+	if err != nil {
+		return false, err
+	}
+	if len(targets) == 0 {
+		return true, nil
+	}
+
+	var registered []*vpclattice.Target
+	for _, target := range targets {
+		if aws.StringValue(target.Status) == vpclattice.TargetStatusDraining {
+			continue
+		}
+		registered = append(registered, &vpclattice.Target{
+			Id:   target.Id,
+			Port: target.Port,
+		})
+	}
+	if len(registered) > 0 {
+		deregisterInput := vpclattice.DeregisterTargetsInput{
+			TargetGroupIdentifier: aws.String(tgID),
+			Targets:               registered,
+		}
+		_, err := t.cloud.Lattice().DeregisterTargetsWithContext(ctx, &deregisterInput)
+		glog.V(2).Infof("Deregister targets of replaced target group >>>> req [%v], err [%v]\n", deregisterInput, err)
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// buildClusterTargetGroups returns one exported target group per vpc, sorted by vpc. A cluster exports a single
+// target group for the service, except while its protocol is changed, the first one found is used then
+func buildClusterTargetGroups(exportedTGs []ExportedTargetGroup) []latticestore.ClusterTargetGroup {
+	vpcSet := make(map[string]bool)
+	var clusters []latticestore.ClusterTargetGroup
+	for _, tg := range exportedTGs {
+		if vpcSet[tg.VpcID] {
+			continue
+		}
+		vpcSet[tg.VpcID] = true
+		clusters = append(clusters, latticestore.ClusterTargetGroup{
+			VpcID:  tg.VpcID,
+			ARN:    tg.ARN,
+			ID:     tg.ID,
+			Weight: tg.Weight,
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].VpcID < clusters[j].VpcID })
+	return clusters
+}
@@ -20,6 +20,8 @@ import (
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	mock_client "github.com/aws/aws-application-networking-k8s/mocks/controller-runtime/client"
+	mocks_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	mocks "github.com/aws/aws-application-networking-k8s/pkg/aws/services"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -258,7 +260,9 @@ func Test_SynthersizeTriggeredByServiceImport(t *testing.T) {
 				Name: tgImport.name,
 				Type: latticemodel.TargetGroupTypeIP,
 				Config: latticemodel.TargetGroupConfig{
-					IsServiceImport: true,
+					IsServiceImport:     true,
+					K8SServiceName:      tgImport.name,
+					K8SServiceNamespace: "default",
 				},
 				IsDeleted: tt.isDeleted,
 			}
@@ -271,9 +275,11 @@ func Test_SynthersizeTriggeredByServiceImport(t *testing.T) {
 			}
 
 			if tgImport.mgrErr {
-				mockTGManager.EXPECT().Get(ctx, tg).Return(latticemodel.TargetGroupStatus{}, errors.New("tgmgr err"))
+				mockTGManager.EXPECT().ListExported(ctx, tgImport.name, "default").Return(nil, errors.New("tgmgr err"))
 			} else {
-				mockTGManager.EXPECT().Get(ctx, tg).Return(latticemodel.TargetGroupStatus{TargetGroupARN: tgImport.tgARN, TargetGroupID: tgImport.tgID}, nil)
+				mockTGManager.EXPECT().ListExported(ctx, tgImport.name, "default").Return([]ExportedTargetGroup{
+					{ID: tgImport.tgID, ARN: tgImport.tgARN, VpcID: "vpc-1", Status: vpclattice.TargetGroupStatusActive, Weight: 1},
+				}, nil)
 			}
 		}
 		synthesizer := NewTargetGroupSynthesizer(nil, nil, mockTGManager, stack, ds)
@@ -298,6 +304,9 @@ func Test_SynthersizeTriggeredByServiceImport(t *testing.T) {
 					assert.Nil(t, err)
 					assert.Equal(t, tgImport.tgARN, tg.ARN)
 					assert.Equal(t, tgImport.tgID, tg.ID)
+					assert.Equal(t, []latticestore.ClusterTargetGroup{
+						{VpcID: "vpc-1", ARN: tgImport.tgARN, ID: tgImport.tgID, Weight: 1},
+					}, tg.Clusters)
 
 				}
 			}
@@ -306,6 +315,85 @@ func Test_SynthersizeTriggeredByServiceImport(t *testing.T) {
 	}
 }
 
+func Test_SynthesizeTriggeredByServiceImport_MultiCluster(t *testing.T) {
+	active := vpclattice.TargetGroupStatusActive
+	exportedTGs := []ExportedTargetGroup{
+		{ID: "tg-2", ARN: "arn-2", VpcID: "vpc-2", Status: active, Weight: 20},
+		{ID: "tg-1", ARN: "arn-1", VpcID: "vpc-1", Status: active, Weight: 80},
+		// the cluster of vpc-1 is changing the protocol of the export
+		{ID: "tg-1-http2", ARN: "arn-1-http2", VpcID: "vpc-1", Status: active, Weight: 80},
+		{ID: "tg-3", ARN: "arn-3", VpcID: "vpc-3", Status: vpclattice.TargetGroupStatusCreateInProgress, Weight: 1},
+	}
+
+	tests := []struct {
+		name         string
+		vpcID        string
+		exportedTGs  []ExportedTargetGroup
+		wantClusters []latticestore.ClusterTargetGroup
+	}{
+		{
+			name:        "all active clusters",
+			exportedTGs: exportedTGs,
+			wantClusters: []latticestore.ClusterTargetGroup{
+				{VpcID: "vpc-1", ARN: "arn-1", ID: "tg-1", Weight: 80},
+				{VpcID: "vpc-2", ARN: "arn-2", ID: "tg-2", Weight: 20},
+			},
+		},
+		{
+			name:        "cluster of the vpc annotation",
+			vpcID:       "vpc-2",
+			exportedTGs: exportedTGs,
+			wantClusters: []latticestore.ClusterTargetGroup{
+				{VpcID: "vpc-2", ARN: "arn-2", ID: "tg-2", Weight: 20},
+			},
+		},
+		{
+			name:        "no active cluster",
+			vpcID:       "vpc-3",
+			exportedTGs: exportedTGs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			mockTGManager := NewMockTargetGroupManager(c)
+			ds := latticestore.NewLatticeDataStore()
+			stack := core.NewDefaultStack(core.StackID(types.NamespacedName{Namespace: "tt", Name: "name"}))
+
+			tgSpec := latticemodel.TargetGroupSpec{
+				Name: "service-import",
+				Type: latticemodel.TargetGroupTypeIP,
+				Config: latticemodel.TargetGroupConfig{
+					VpcID:               tt.vpcID,
+					IsServiceImport:     true,
+					K8SServiceName:      "export",
+					K8SServiceNamespace: "default",
+				},
+			}
+			latticemodel.NewTargetGroup(stack, "service-import", tgSpec)
+			mockTGManager.EXPECT().ListExported(ctx, "export", "default").Return(tt.exportedTGs, nil)
+
+			synthesizer := NewTargetGroupSynthesizer(nil, nil, mockTGManager, stack, ds)
+			err := synthesizer.SynthesizeTriggeredTargetGroup(ctx)
+
+			tg, dsErr := ds.GetTargetGroup("service-import", "", true)
+			if tt.wantClusters == nil {
+				assert.NotNil(t, err)
+				assert.NotNil(t, dsErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Nil(t, dsErr)
+			assert.Equal(t, tt.wantClusters[0].ID, tg.ID)
+			assert.Equal(t, tt.wantClusters, tg.Clusters)
+		})
+	}
+}
+
 type sdkTGDef struct {
 	name string
 	id   string
@@ -751,3 +839,262 @@ func Test_SynthesizeTriggeredService(t *testing.T) {
 
 	}
 }
+
+func Test_SynthesizeSDKTargetGroups_ReplacedTargetGroup(t *testing.T) {
+	config.VpcID = "current-vpc"
+	srvname := "test-svc1"
+	srvnamespace := "default"
+	tgName := latticestore.TargetGroupName(srvname, srvnamespace)
+
+	tests := []struct {
+		name              string
+		protocolVersion   string
+		serviceArns       []*string
+		targets           []*vpclattice.TargetSummary
+		wantDeregister    []*vpclattice.Target
+		wantDelete        bool
+		wantPostSynthErr  error
+		wantReplacedCount int
+	}{
+		{
+			name:              "replaced target group is deleted once it is no longer referenced and drained",
+			protocolVersion:   vpclattice.TargetGroupProtocolVersionHttp2,
+			wantDelete:        true,
+			wantReplacedCount: 1,
+		},
+		{
+			name:              "replaced target group is kept while it is referenced",
+			protocolVersion:   vpclattice.TargetGroupProtocolVersionHttp2,
+			serviceArns:       []*string{aws.String("svc-arn")},
+			wantPostSynthErr:  errors.New(LATTICE_RETRY),
+			wantReplacedCount: 1,
+		},
+		{
+			name:            "targets of replaced target group are deregistered before it is deleted",
+			protocolVersion: vpclattice.TargetGroupProtocolVersionHttp2,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusHealthy)},
+				{Id: aws.String("10.0.0.2"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusDraining)},
+			},
+			wantDeregister: []*vpclattice.Target{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(8080)},
+			},
+			wantPostSynthErr:  errors.New(LATTICE_RETRY),
+			wantReplacedCount: 1,
+		},
+		{
+			name:            "replaced target group is kept while its targets are draining",
+			protocolVersion: vpclattice.TargetGroupProtocolVersionHttp2,
+			targets: []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.2"), Port: aws.Int64(8080), Status: aws.String(vpclattice.TargetStatusDraining)},
+			},
+			wantPostSynthErr:  errors.New(LATTICE_RETRY),
+			wantReplacedCount: 1,
+		},
+		{
+			name:            "target group in use is not replaced",
+			protocolVersion: vpclattice.TargetGroupProtocolVersionHttp1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.Background()
+
+			// the protocol version of the target group changed, e.g. with the appProtocol of the service. The
+			// datastore is empty like after a controller restart
+			ds := latticestore.NewLatticeDataStore()
+			stack := core.NewDefaultStack(core.StackID{Name: srvname, Namespace: srvnamespace})
+			latticemodel.NewTargetGroup(stack, tgName, latticemodel.TargetGroupSpec{
+				Name: tgName,
+				Type: latticemodel.TargetGroupTypeIP,
+				Config: latticemodel.TargetGroupConfig{
+					Protocol:        vpclattice.TargetGroupProtocolHttp,
+					ProtocolVersion: tt.protocolVersion,
+					IsServiceExport: true,
+				},
+			})
+
+			sdkTG := targetGroupOutput{
+				getTargetGroupOutput: vpclattice.GetTargetGroupOutput{
+					Name: aws.String(tgName),
+					Id:   aws.String("old-tg-id"),
+					Arn:  aws.String("old-tg-arn"),
+					Config: &vpclattice.TargetGroupConfig{
+						VpcIdentifier:   aws.String(config.VpcID),
+						Protocol:        aws.String(vpclattice.TargetGroupProtocolHttp),
+						ProtocolVersion: aws.String(vpclattice.TargetGroupProtocolVersionHttp1),
+					},
+				},
+				targetGroupTags: &vpclattice.ListTagsForResourceOutput{
+					Tags: map[string]*string{
+						latticemodel.K8SParentRefTypeKey:    aws.String(latticemodel.K8SServiceExportType),
+						latticemodel.K8SServiceNameKey:      aws.String(srvname),
+						latticemodel.K8SServiceNamespaceKey: aws.String(srvnamespace),
+					},
+				},
+			}
+
+			mockTGManager := NewMockTargetGroupManager(c)
+			mockTGManager.EXPECT().List(ctx).Return([]targetGroupOutput{sdkTG}, nil)
+
+			k8sClient := mock_client.NewMockClient(c)
+			k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(nil)
+
+			mockVpcLatticeSess := mocks.NewMockLattice(c)
+			mockCloud := mocks_aws.NewMockCloud(c)
+			mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+			if tt.wantReplacedCount > 0 {
+				mockVpcLatticeSess.EXPECT().GetTargetGroupWithContext(ctx, &vpclattice.GetTargetGroupInput{
+					TargetGroupIdentifier: aws.String("old-tg-id"),
+				}).Return(&vpclattice.GetTargetGroupOutput{ServiceArns: tt.serviceArns}, nil)
+			}
+
+			if tt.wantReplacedCount > 0 && len(tt.serviceArns) == 0 {
+				mockVpcLatticeSess.EXPECT().ListTargetsAsList(ctx, &vpclattice.ListTargetsInput{
+					TargetGroupIdentifier: aws.String("old-tg-id"),
+				}).Return(tt.targets, nil)
+			}
+
+			if tt.wantDeregister != nil {
+				mockVpcLatticeSess.EXPECT().DeregisterTargetsWithContext(ctx, &vpclattice.DeregisterTargetsInput{
+					TargetGroupIdentifier: aws.String("old-tg-id"),
+					Targets:               tt.wantDeregister,
+				}).Return(&vpclattice.DeregisterTargetsOutput{}, nil)
+			}
+
+			if tt.wantDelete {
+				mockTGManager.EXPECT().Delete(ctx, &latticemodel.TargetGroup{
+					Spec: latticemodel.TargetGroupSpec{
+						Name:      tgName,
+						LatticeID: "old-tg-id",
+					},
+				}).Return(nil)
+			}
+
+			synthesizer := NewTargetGroupSynthesizer(mockCloud, k8sClient, mockTGManager, stack, ds)
+
+			err := synthesizer.SynthesizeSDKTargetGroups(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantReplacedCount, len(synthesizer.replacedSDKTGs))
+
+			err = synthesizer.PostSynthesize(ctx)
+			assert.Equal(t, tt.wantPostSynthErr, err)
+		})
+	}
+}
+
+// the previous release named the target group of a backendRef with a port without the port
+func Test_SynthesizeSDKTargetGroups_LegacyTargetGroup(t *testing.T) {
+	config.VpcID = "current-vpc"
+	srvname := "test-svc1"
+	srvnamespace := "default"
+	routename := "test-route"
+	legacyTGName := latticestore.TargetGroupName(srvname, srvnamespace)
+	port := gateway_api.PortNumber(80)
+
+	tests := []struct {
+		name              string
+		portTag           *string
+		backendRefName    string
+		wantReplacedCount int
+		wantDelete        bool
+	}{
+		{
+			name:              "legacy target group is replaced by the target group named with the port",
+			backendRefName:    srvname,
+			wantReplacedCount: 1,
+		},
+		{
+			name:           "legacy target group taken over for the port is kept",
+			portTag:        aws.String("80"),
+			backendRefName: srvname,
+		},
+		{
+			name:           "legacy target group of a service no longer referenced is deleted",
+			backendRefName: "other-svc",
+			wantDelete:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.Background()
+
+			tags := map[string]*string{
+				latticemodel.K8SParentRefTypeKey:      aws.String(latticemodel.K8SHTTPRouteType),
+				latticemodel.K8SServiceNameKey:        aws.String(srvname),
+				latticemodel.K8SServiceNamespaceKey:   aws.String(srvnamespace),
+				latticemodel.K8SHTTPRouteNameKey:      aws.String(routename),
+				latticemodel.K8SHTTPRouteNamespaceKey: aws.String(srvnamespace),
+			}
+			if tt.portTag != nil {
+				tags[latticemodel.K8SServicePortKey] = tt.portTag
+			}
+			sdkTG := targetGroupOutput{
+				getTargetGroupOutput: vpclattice.GetTargetGroupOutput{
+					Name: aws.String(legacyTGName),
+					Id:   aws.String("legacy-tg-id"),
+					Arn:  aws.String("legacy-tg-arn"),
+					Config: &vpclattice.TargetGroupConfig{
+						VpcIdentifier:   aws.String(config.VpcID),
+						Protocol:        aws.String(vpclattice.TargetGroupProtocolHttp),
+						ProtocolVersion: aws.String(vpclattice.TargetGroupProtocolVersionHttp1),
+					},
+				},
+				targetGroupTags: &vpclattice.ListTagsForResourceOutput{Tags: tags},
+			}
+
+			mockTGManager := NewMockTargetGroupManager(c)
+			mockTGManager.EXPECT().List(ctx).Return([]targetGroupOutput{sdkTG}, nil)
+
+			k8sClient := mock_client.NewMockClient(c)
+			k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, name types.NamespacedName, httpRoute *gateway_api.HTTPRoute, arg3 ...interface{}) error {
+					httpRoute.Name = routename
+					httpRoute.Namespace = srvnamespace
+					httpRoute.Spec.Rules = []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Kind: kindPtr("Service"),
+											Name: gateway_api.ObjectName(tt.backendRefName),
+											Port: &port,
+										},
+									},
+								},
+							},
+						},
+					}
+					return nil
+				},
+			)
+
+			if tt.wantDelete {
+				mockTGManager.EXPECT().Delete(ctx, &latticemodel.TargetGroup{
+					Spec: latticemodel.TargetGroupSpec{
+						Name: legacyTGName,
+						Config: latticemodel.TargetGroupConfig{
+							K8SHTTPRouteName: routename,
+						},
+						LatticeID: "legacy-tg-id",
+					},
+				}).Return(nil)
+			}
+
+			stack := core.NewDefaultStack(core.StackID{Name: routename, Namespace: srvnamespace})
+			synthesizer := NewTargetGroupSynthesizer(nil, k8sClient, mockTGManager, stack, latticestore.NewLatticeDataStore())
+
+			err := synthesizer.SynthesizeSDKTargetGroups(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantReplacedCount, len(synthesizer.replacedSDKTGs))
+		})
+	}
+}
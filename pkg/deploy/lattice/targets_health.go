@@ -0,0 +1,81 @@
+package lattice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+)
+
+// TargetsHealth counts the lattice targets of a service by health
+type TargetsHealth struct {
+	Total   int
+	Healthy int
+	// Reasons counts the unhealthy targets by reason code, or by status when lattice gives no reason
+	Reasons map[string]int
+}
+
+// NewTargetsHealth returns an empty TargetsHealth, targets are counted with Add
+func NewTargetsHealth() *TargetsHealth {
+	return &TargetsHealth{
+		Reasons: make(map[string]int),
+	}
+}
+
+// Add counts the targets. Draining targets are leaving the target group and are not counted, see
+// IsTargetHealthy for the healthy ones
+func (h *TargetsHealth) Add(targets []*vpclattice.TargetSummary) {
+	for _, target := range targets {
+		status := aws.StringValue(target.Status)
+		switch {
+		case status == vpclattice.TargetStatusDraining:
+			continue
+		case IsTargetHealthy(target):
+			h.Total++
+			h.Healthy++
+		default:
+			h.Total++
+			reason := aws.StringValue(target.ReasonCode)
+			if reason == "" {
+				reason = status
+			}
+			h.Reasons[reason]++
+		}
+	}
+}
+
+// IsTargetHealthy returns whether the target receives traffic, targets of target groups with health checks
+// disabled are UNAVAILABLE
+func IsTargetHealthy(target *vpclattice.TargetSummary) bool {
+	status := aws.StringValue(target.Status)
+	return status == vpclattice.TargetStatusHealthy || status == vpclattice.TargetStatusUnavailable
+}
+
+// Unhealthy returns the number of targets which are not healthy
+func (h *TargetsHealth) Unhealthy() int {
+	return h.Total - h.Healthy
+}
+
+// IsHealthy returns whether there are targets, and all of them are healthy
+func (h *TargetsHealth) IsHealthy() bool {
+	return h.Total > 0 && h.Unhealthy() == 0
+}
+
+// String summarizes the health, e.g. "3/5 targets unhealthy: HealthCheckFailed"
+func (h *TargetsHealth) String() string {
+	if h.Total == 0 {
+		return "no targets"
+	}
+	if h.Unhealthy() == 0 {
+		return fmt.Sprintf("%d/%d targets healthy", h.Healthy, h.Total)
+	}
+
+	var reasons []string
+	for reason := range h.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("%d/%d targets unhealthy: %s", h.Unhealthy(), h.Total, strings.Join(reasons, ", "))
+}
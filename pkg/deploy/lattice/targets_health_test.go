@@ -0,0 +1,73 @@
+package lattice
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TargetsHealth(t *testing.T) {
+	target := func(status string, reasonCode string) *vpclattice.TargetSummary {
+		summary := &vpclattice.TargetSummary{
+			Id:     aws.String("10.10.1.1"),
+			Port:   aws.Int64(80),
+			Status: aws.String(status),
+		}
+		if reasonCode != "" {
+			summary.ReasonCode = aws.String(reasonCode)
+		}
+		return summary
+	}
+
+	tests := []struct {
+		name              string
+		targets           []*vpclattice.TargetSummary
+		expectedTotal     int
+		expectedUnhealthy int
+		expectedHealthy   bool
+		expectedMessage   string
+	}{
+		{
+			name:            "no targets",
+			expectedMessage: "no targets",
+		},
+		{
+			name: "all healthy, draining targets are not counted",
+			targets: []*vpclattice.TargetSummary{
+				target(vpclattice.TargetStatusHealthy, ""),
+				target(vpclattice.TargetStatusUnavailable, ""),
+				target(vpclattice.TargetStatusDraining, ""),
+			},
+			expectedTotal:   2,
+			expectedHealthy: true,
+			expectedMessage: "2/2 targets healthy",
+		},
+		{
+			name: "unhealthy targets with reasons",
+			targets: []*vpclattice.TargetSummary{
+				target(vpclattice.TargetStatusHealthy, ""),
+				target(vpclattice.TargetStatusHealthy, ""),
+				target(vpclattice.TargetStatusUnhealthy, "HealthCheckFailed"),
+				target(vpclattice.TargetStatusUnhealthy, "HealthCheckFailed"),
+				target(vpclattice.TargetStatusInitial, ""),
+			},
+			expectedTotal:     5,
+			expectedUnhealthy: 3,
+			expectedMessage:   "3/5 targets unhealthy: HealthCheckFailed, INITIAL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewTargetsHealth()
+			health.Add(tt.targets)
+
+			assert.Equal(t, tt.expectedTotal, health.Total)
+			assert.Equal(t, tt.expectedUnhealthy, health.Unhealthy())
+			assert.Equal(t, tt.expectedHealthy, health.IsHealthy())
+			assert.Equal(t, tt.expectedMessage, health.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package lattice
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/glog"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -13,8 +14,23 @@ import (
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 )
 
+// maxTargetsPerCall is the maximum number of targets of a lattice register or deregister targets call
+const maxTargetsPerCall = 100
+
+// TargetsFailedError lists the targets lattice failed to register or deregister, with the failure reasons
+type TargetsFailedError struct {
+	RegisterFailures   []*vpclattice.TargetFailure
+	DeregisterFailures []*vpclattice.TargetFailure
+}
+
+func (e *TargetsFailedError) Error() string {
+	return fmt.Sprintf("%s: failed to register %d targets, failed to deregister %d targets",
+		LATTICE_RETRY, len(e.RegisterFailures), len(e.DeregisterFailures))
+}
+
 type TargetsManager interface {
-	Create(ctx context.Context, targets *latticemodel.Targets) error
+	Create(ctx context.Context, targets *latticemodel.Targets) (latticemodel.TargetsStatus, error)
+	List(ctx context.Context, targetGroupID string) ([]*vpclattice.TargetSummary, error)
 }
 
 type defaultTargetsManager struct {
@@ -29,23 +45,27 @@ func NewTargetsManager(cloud lattice_aws.Cloud, datastore *latticestore.LatticeD
 	}
 }
 
-// Create will try to register targets to the target group
+// Create will try to register the targets missing from the target group, and deregister the stale targets.
+// Register and deregister calls are sent in batches of maxTargetsPerCall targets
 // return Retry when:
 //
 //		Target group does not exist
-//		nonempty unsuccessfully registered targets list
+//	return TargetsFailedError when:
+//
+//		nonempty unsuccessfully registered or deregistered targets list, only those are retried
 //	otherwise:
-//	nil
-func (s *defaultTargetsManager) Create(ctx context.Context, targets *latticemodel.Targets) error {
+//	nil, the status counts the pending targets, stale targets which are still draining or terminating
+//	targets waiting for their replacements
+func (s *defaultTargetsManager) Create(ctx context.Context, targets *latticemodel.Targets) (latticemodel.TargetsStatus, error) {
 	glog.V(6).Infof("Update Lattice targets API call for %v \n", targets)
 
 	// Need to find TargetGroup ID from datastore
-	tgName := latticestore.TargetGroupName(targets.Spec.Name, targets.Spec.Namespace)
+	tgName := targetsTargetGroupName(targets)
 	tg, err := s.datastore.GetTargetGroup(tgName, targets.Spec.RouteName, false) // isServiceImport=false
 
 	if err != nil {
 		glog.V(6).Infof("Failed to Create targets, service ( name %v namespace %v) not found, retry later\n", targets.Spec.Name, targets.Spec.Namespace)
-		return errors.New(LATTICE_RETRY)
+		return latticemodel.TargetsStatus{}, errors.New(LATTICE_RETRY)
 	}
 	vpcLatticeSess := s.cloud.Lattice()
 	// find out sdk target list
@@ -53,68 +73,153 @@ func (s *defaultTargetsManager) Create(ctx context.Context, targets *latticemode
 		TargetGroupIdentifier: &tg.ID,
 	}
 
-	var delTargetsList []*vpclattice.Target
 	listTargetsOutput, err := vpcLatticeSess.ListTargetsAsList(ctx, &listTargetsInput)
 	glog.V(6).Infof("TargetsManager-Create, listTargetsOutput %v, err %v \n", listTargetsOutput, err)
 	if err != nil {
 		glog.V(6).Infof("Failed to create target, tgName %v tg %v\n", tgName, tg)
-		return err
+		return latticemodel.TargetsStatus{}, err
 	}
-	for _, sdkT := range listTargetsOutput {
-		// check if sdkT is in input target list
-		isStale := true
 
-		for _, t := range targets.Spec.TargetIPList {
-			if (aws.StringValue(sdkT.Id) == t.TargetIP) && (aws.Int64Value(sdkT.Port) == t.Port) {
-				isStale = false
-				break
-			}
-		}
-
-		if isStale {
-			delTargetsList = append(delTargetsList, &vpclattice.Target{Id: sdkT.Id, Port: sdkT.Port})
-		}
-	}
+	registerTargetsList, delTargetsList, pendingTargets := buildTargetsDelta(targets.Spec.TargetIPList,
+		targets.Spec.TerminatingTargetList, listTargetsOutput)
 
-	if len(delTargetsList) > 0 {
+	targetsErr := &TargetsFailedError{}
+	for _, chunk := range chunkTargets(delTargetsList) {
 		deRegisterTargetsInput := vpclattice.DeregisterTargetsInput{
 			TargetGroupIdentifier: &tg.ID,
-			Targets:               delTargetsList,
+			Targets:               chunk,
 		}
 		deRegisterTargetsOutput, err := vpcLatticeSess.DeregisterTargetsWithContext(ctx, &deRegisterTargetsInput)
 		glog.V(6).Infof("TargetManager-Create, deregister deleted targets input %v, output %v, err %v\n", deRegisterTargetsInput, deRegisterTargetsOutput, err)
+		if err != nil {
+			return latticemodel.TargetsStatus{}, err
+		}
+		targetsErr.DeregisterFailures = append(targetsErr.DeregisterFailures, deRegisterTargetsOutput.Unsuccessful...)
 	}
-	// TODO following should be done at model level
-	var targetList []*vpclattice.Target
-	for _, target := range targets.Spec.TargetIPList {
-		port := target.Port
-		targetIP := target.TargetIP
-		t := vpclattice.Target{
-			Id:   &targetIP,
-			Port: &port,
+
+	for _, chunk := range chunkTargets(registerTargetsList) {
+		registerRouteInput := vpclattice.RegisterTargetsInput{
+			TargetGroupIdentifier: &tg.ID,
+			Targets:               chunk,
+		}
+		glog.V(6).Infof("Calling Lattice API register targets input %v \n", registerRouteInput)
+
+		resp, err := vpcLatticeSess.RegisterTargetsWithContext(ctx, &registerRouteInput)
+		glog.V(6).Infof("register pod to target group resp[%v]\n", resp)
+		glog.V(6).Infof("register pod to target group err[%v]\n", err)
+		if err != nil {
+			glog.V(6).Infof("Fail to register target err[%v]\n", err)
+			return latticemodel.TargetsStatus{}, err
 		}
-		targetList = append(targetList, &t)
+		targetsErr.RegisterFailures = append(targetsErr.RegisterFailures, resp.Unsuccessful...)
 	}
 
-	registerRouteInput := vpclattice.RegisterTargetsInput{
-		TargetGroupIdentifier: &tg.ID,
-		Targets:               targetList,
+	if len(targetsErr.RegisterFailures) > 0 || len(targetsErr.DeregisterFailures) > 0 {
+		glog.V(6).Infof("Targets register unsuccessfully, will retry later: %v\n", targetsErr)
+		return latticemodel.TargetsStatus{}, targetsErr
 	}
-	glog.V(6).Infof("Calling Lattice API register targets input %v \n", registerRouteInput)
+	glog.V(6).Infof("Targets register successfully\n")
 
-	resp, err := vpcLatticeSess.RegisterTargetsWithContext(ctx, &registerRouteInput)
-	glog.V(6).Infof("register pod to target group resp[%v]\n", resp)
-	glog.V(6).Infof("register pod to target group err[%v]\n", err)
-	if err != nil {
-		glog.V(6).Infof("Fail to register target err[%v]\n", err)
-		return err
+	if pendingTargets > 0 {
+		glog.V(6).Infof("%d targets of tg %v are draining or waiting to be drained, will check again later\n", pendingTargets, tgName)
 	}
+	return latticemodel.TargetsStatus{PendingTargets: pendingTargets}, nil
+}
 
-	isTargetRegisteredUnsuccessful := len(resp.Unsuccessful) > 0
-	if isTargetRegisteredUnsuccessful {
-		glog.V(6).Infof("Targets register unsuccessfully, will retry later\n")
-		return errors.New(LATTICE_RETRY)
+// buildTargetsDelta compares the targets to the targets registered in lattice, and returns the targets to
+// register, the stale targets to deregister, and the number of stale targets lattice still lists. Terminating targets stay registered while a target is not healthy yet, so they keep serving until their
+// replacements do, they are deregistered and drained after that
+func buildTargetsDelta(targets []latticemodel.Target, terminatingTargets []latticemodel.Target,
+	sdkTargets []*vpclattice.TargetSummary) ([]*vpclattice.Target, []*vpclattice.Target, int) {
+	var registerTargetsList, delTargetsList []*vpclattice.Target
+	pendingTargets := 0
+
+	sdkTargetStatus := make(map[latticemodel.Target]string)
+	for _, sdkT := range sdkTargets {
+		sdkTargetStatus[latticemodel.Target{
+			TargetIP: aws.StringValue(sdkT.Id),
+			Port:     aws.Int64Value(sdkT.Port),
+		}] = aws.StringValue(sdkT.Status)
 	}
-	glog.V(6).Infof("Targets register successfully\n")
-	return nil
+
+	desired := make(map[latticemodel.Target]bool)
+	replacementsPending := false
+	for _, target := range targets {
+		desired[target] = true
+		status, ok := sdkTargetStatus[target]
+		if !ok || status == vpclattice.TargetStatusInitial || status == vpclattice.TargetStatusDraining {
+			replacementsPending = true
+		}
+		// draining targets are registered again
+		if ok && status != vpclattice.TargetStatusDraining {
+			continue
+		}
+		sdkTarget := &vpclattice.Target{
+			Id: aws.String(target.TargetIP),
+		}
+		// lambda functions are registered without port
+		if target.Port != 0 {
+			sdkTarget.Port = aws.Int64(target.Port)
+		}
+		registerTargetsList = append(registerTargetsList, sdkTarget)
+	}
+
+	terminating := make(map[latticemodel.Target]bool)
+	for _, target := range terminatingTargets {
+		terminating[target] = true
+	}
+
+	for _, sdkT := range sdkTargets {
+		target := latticemodel.Target{
+			TargetIP: aws.StringValue(sdkT.Id),
+			Port:     aws.Int64Value(sdkT.Port),
+		}
+		if desired[target] {
+			continue
+		}
+
+		// stale targets stay listed by lattice until they finished draining
+		pendingTargets++
+		if aws.StringValue(sdkT.Status) == vpclattice.TargetStatusDraining {
+			// already deregistered
+			continue
+		}
+		if terminating[target] && replacementsPending {
+			// still serving, deregistered once the replacements are healthy
+			continue
+		}
+		delTargetsList = append(delTargetsList, &vpclattice.Target{Id: sdkT.Id, Port: sdkT.Port})
+	}
+
+	return registerTargetsList, delTargetsList, pendingTargets
+}
+
+// targetsTargetGroupName returns the datastore name of the target group of the targets
+func targetsTargetGroupName(targets *latticemodel.Targets) string {
+	if targets.Spec.IsLambda {
+		return latticestore.LambdaTargetGroupName(targets.Spec.Name, targets.Spec.Namespace)
+	}
+	return latticestore.TargetGroupPortName(targets.Spec.Name, targets.Spec.Namespace, targets.Spec.Port)
+}
+
+// chunkTargets splits the targets in batches of at most maxTargetsPerCall targets
+func chunkTargets(targets []*vpclattice.Target) [][]*vpclattice.Target {
+	var chunks [][]*vpclattice.Target
+	for len(targets) > maxTargetsPerCall {
+		chunks = append(chunks, targets[:maxTargetsPerCall])
+		targets = targets[maxTargetsPerCall:]
+	}
+	if len(targets) > 0 {
+		chunks = append(chunks, targets)
+	}
+	return chunks
+}
+
+// List returns the targets registered to the target group, along with their health status
+func (s *defaultTargetsManager) List(ctx context.Context, targetGroupID string) ([]*vpclattice.TargetSummary, error) {
+	listTargetsInput := vpclattice.ListTargetsInput{
+		TargetGroupIdentifier: aws.String(targetGroupID),
+	}
+
+	return s.cloud.Lattice().ListTargetsAsList(ctx, &listTargetsInput)
 }
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	lattice "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	vpclattice "github.com/aws/aws-sdk-go/service/vpclattice"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -36,11 +37,12 @@ func (m *MockTargetsManager) EXPECT() *MockTargetsManagerMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockTargetsManager) Create(ctx context.Context, targets *lattice.Targets) error {
+func (m *MockTargetsManager) Create(ctx context.Context, targets *lattice.Targets) (lattice.TargetsStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx, targets)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(lattice.TargetsStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
@@ -48,3 +50,18 @@ func (mr *MockTargetsManagerMockRecorder) Create(ctx, targets interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTargetsManager)(nil).Create), ctx, targets)
 }
+
+// List mocks base method.
+func (m *MockTargetsManager) List(ctx context.Context, targetGroupID string) ([]*vpclattice.TargetSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, targetGroupID)
+	ret0, _ := ret[0].([]*vpclattice.TargetSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTargetsManagerMockRecorder) List(ctx, targetGroupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTargetsManager)(nil).List), ctx, targetGroupID)
+}
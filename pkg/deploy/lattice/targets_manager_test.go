@@ -3,8 +3,10 @@ package lattice
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +23,8 @@ case1: register targets Successfully
 case2: target group does not exist
 case3: failed register targets
 case4: register targets Unsuccessfully
+case5: stale targets are draining
+case6: only the missing targets are registered, in batches
 */
 
 func Test_RegisterTargets_RegisterSuccessfully(t *testing.T) {
@@ -68,7 +72,7 @@ func Test_RegisterTargets_RegisterSuccessfully(t *testing.T) {
 	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
 
 	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
-	err := targetsManager.Create(ctx, &createInput)
+	_, err := targetsManager.Create(ctx, &createInput)
 
 	assert.Nil(t, err)
 }
@@ -91,7 +95,7 @@ func Test_RegisterTargets_TGNotExist(t *testing.T) {
 	mockCloud := mocks_aws.NewMockCloud(c)
 
 	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
-	err := targetsManager.Create(ctx, &createInput)
+	_, err := targetsManager.Create(ctx, &createInput)
 
 	assert.NotNil(t, err)
 	assert.Equal(t, err, errors.New(LATTICE_RETRY))
@@ -120,7 +124,12 @@ func Test_RegisterTargets_Registerfailed(t *testing.T) {
 		Name:          "test",
 		Namespace:     "",
 		TargetGroupID: "123456789",
-		TargetIPList:  nil,
+		TargetIPList: []latticemodel.Target{
+			{
+				TargetIP: "123.456.78",
+				Port:     int64(8080),
+			},
+		},
 	}
 	planToRegister := latticemodel.Targets{
 		ResourceMeta: core.ResourceMeta{},
@@ -144,7 +153,7 @@ func Test_RegisterTargets_Registerfailed(t *testing.T) {
 	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
 
 	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
-	err := targetsManager.Create(ctx, &planToRegister)
+	_, err := targetsManager.Create(ctx, &planToRegister)
 
 	assert.NotNil(t, err)
 	assert.Equal(t, err, errors.New("Register_Targets_Failed"))
@@ -228,8 +237,308 @@ func Test_RegisterTargets_RegisterUnsuccessfully(t *testing.T) {
 	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
 
 	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
-	err := targetsManager.Create(ctx, &planToRegister)
+	_, err := targetsManager.Create(ctx, &planToRegister)
 
 	assert.NotNil(t, err)
-	assert.Equal(t, err, errors.New(LATTICE_RETRY))
+	assert.Equal(t, &TargetsFailedError{RegisterFailures: unsuccessful}, err)
+}
+
+// stale targets are deregistered once, and reported pending until they finished draining
+func Test_RegisterTargets_StaleTargetsDraining(t *testing.T) {
+	tgId := "123456789"
+	port := int64(8080)
+	registeredIP := "10.10.1.1"
+	staleIP := "10.10.2.2"
+	drainingIP := "10.10.3.3"
+	newIP := "10.10.4.4"
+	healthy := vpclattice.TargetStatusHealthy
+	draining := vpclattice.TargetStatusDraining
+
+	listTargetOutput := []*vpclattice.TargetSummary{
+		{Id: &registeredIP, Port: &port, Status: &healthy},
+		{Id: &staleIP, Port: &port, Status: &healthy},
+		{Id: &drainingIP, Port: &port, Status: &draining},
+	}
+
+	targetsSpec := latticemodel.TargetsSpec{
+		Name:          "test",
+		TargetGroupID: tgId,
+		TargetIPList: []latticemodel.Target{
+			{
+				TargetIP: registeredIP,
+				Port:     port,
+			},
+			{
+				TargetIP: newIP,
+				Port:     port,
+			},
+		},
+	}
+	planToRegister := latticemodel.Targets{
+		ResourceMeta: core.ResourceMeta{},
+		Spec:         targetsSpec,
+	}
+
+	deRegisterTargetsInput := &vpclattice.DeregisterTargetsInput{
+		TargetGroupIdentifier: &tgId,
+		Targets:               []*vpclattice.Target{{Id: &staleIP, Port: &port}},
+	}
+	registerTargetsInput := &vpclattice.RegisterTargetsInput{
+		TargetGroupIdentifier: &tgId,
+		Targets:               []*vpclattice.Target{{Id: &newIP, Port: &port}},
+	}
+
+	latticeDataStore := latticestore.NewLatticeDataStore()
+	tgName := latticestore.TargetGroupName("test", "")
+	latticeDataStore.AddTargetGroup(tgName, "vpc-123456789", "123456789", tgId, false, "")
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+
+	mockVpcLatticeSess.EXPECT().ListTargetsAsList(ctx, gomock.Any()).Return(listTargetOutput, nil)
+	mockVpcLatticeSess.EXPECT().DeregisterTargetsWithContext(ctx, deRegisterTargetsInput).Return(&vpclattice.DeregisterTargetsOutput{}, nil)
+	mockVpcLatticeSess.EXPECT().RegisterTargetsWithContext(ctx, registerTargetsInput).Return(&vpclattice.RegisterTargetsOutput{}, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
+	status, err := targetsManager.Create(ctx, &planToRegister)
+
+	assert.Nil(t, err)
+	// the stale and the draining target
+	assert.Equal(t, 2, status.PendingTargets)
+}
+
+func Test_ListTargets(t *testing.T) {
+	tgId := "123456789"
+	ip := "10.10.1.1"
+	port := int64(8080)
+	healthy := vpclattice.TargetStatusHealthy
+	listTargetOutput := []*vpclattice.TargetSummary{
+		{Id: &ip, Port: &port, Status: &healthy},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+
+	listTargetsInput := &vpclattice.ListTargetsInput{
+		TargetGroupIdentifier: &tgId,
+	}
+	mockVpcLatticeSess.EXPECT().ListTargetsAsList(ctx, listTargetsInput).Return(listTargetOutput, nil)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+	targetsManager := NewTargetsManager(mockCloud, latticestore.NewLatticeDataStore())
+	targets, err := targetsManager.List(ctx, tgId)
+
+	assert.Nil(t, err)
+	assert.Equal(t, listTargetOutput, targets)
+}
+
+func Test_RegisterTargets_Batches(t *testing.T) {
+	tgId := "123456789"
+	port := int64(8080)
+
+	var targetIPList []latticemodel.Target
+	var listTargetOutput []*vpclattice.TargetSummary
+	// 10 targets are already registered, 150 are registered in 2 calls
+	for i := 0; i < 160; i++ {
+		ip := fmt.Sprintf("10.10.%d.%d", i/100, i%100)
+		targetIPList = append(targetIPList, latticemodel.Target{TargetIP: ip, Port: port})
+		if i < 10 {
+			listTargetOutput = append(listTargetOutput, &vpclattice.TargetSummary{
+				Id:     aws.String(ip),
+				Port:   aws.Int64(port),
+				Status: aws.String(vpclattice.TargetStatusHealthy),
+			})
+		}
+	}
+	expectedChunks := []int{maxTargetsPerCall, 50}
+
+	planToRegister := latticemodel.Targets{
+		ResourceMeta: core.ResourceMeta{},
+		Spec: latticemodel.TargetsSpec{
+			Name:          "test",
+			TargetGroupID: tgId,
+			TargetIPList:  targetIPList,
+		},
+	}
+
+	latticeDataStore := latticestore.NewLatticeDataStore()
+	tgName := latticestore.TargetGroupName("test", "")
+	latticeDataStore.AddTargetGroup(tgName, "vpc-123456789", "123456789", tgId, false, "")
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+	mockCloud := mocks_aws.NewMockCloud(c)
+	mockVpcLatticeSess := mocks.NewMockLattice(c)
+
+	var registered []string
+	var chunks []int
+	mockVpcLatticeSess.EXPECT().ListTargetsAsList(ctx, gomock.Any()).Return(listTargetOutput, nil)
+	mockVpcLatticeSess.EXPECT().RegisterTargetsWithContext(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, input *vpclattice.RegisterTargetsInput, opts ...interface{}) (*vpclattice.RegisterTargetsOutput, error) {
+			chunks = append(chunks, len(input.Targets))
+			for _, target := range input.Targets {
+				registered = append(registered, aws.StringValue(target.Id))
+			}
+			return &vpclattice.RegisterTargetsOutput{}, nil
+		}).Times(2)
+	mockCloud.EXPECT().Lattice().Return(mockVpcLatticeSess).AnyTimes()
+
+	targetsManager := NewTargetsManager(mockCloud, latticeDataStore)
+	_, err := targetsManager.Create(ctx, &planToRegister)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedChunks, chunks)
+	assert.Equal(t, 150, len(registered))
+	assert.NotContains(t, registered, "10.10.0.0")
+}
+
+func Test_buildTargetsDelta(t *testing.T) {
+	port := int64(80)
+	sdkTargets := []*vpclattice.TargetSummary{
+		{Id: aws.String("10.0.0.1"), Port: aws.Int64(port), Status: aws.String(vpclattice.TargetStatusHealthy)},
+		{Id: aws.String("10.0.0.2"), Port: aws.Int64(port), Status: aws.String(vpclattice.TargetStatusDraining)},
+		{Id: aws.String("10.0.0.3"), Port: aws.Int64(port), Status: aws.String(vpclattice.TargetStatusUnhealthy)},
+		{Id: aws.String("10.0.0.4"), Port: aws.Int64(port), Status: aws.String(vpclattice.TargetStatusDraining)},
+	}
+	targets := []latticemodel.Target{
+		{TargetIP: "10.0.0.1", Port: port},
+		// draining target which is back
+		{TargetIP: "10.0.0.2", Port: port},
+		{TargetIP: "10.0.0.5", Port: port},
+	}
+
+	register, deregister, draining := buildTargetsDelta(targets, nil, sdkTargets)
+
+	assert.Equal(t, []*vpclattice.Target{
+		{Id: aws.String("10.0.0.2"), Port: aws.Int64(port)},
+		{Id: aws.String("10.0.0.5"), Port: aws.Int64(port)},
+	}, register)
+	assert.Equal(t, []*vpclattice.Target{
+		{Id: aws.String("10.0.0.3"), Port: aws.Int64(port)},
+	}, deregister)
+	assert.Equal(t, 2, draining)
+}
+
+func Test_buildTargetsDelta_Terminating(t *testing.T) {
+	port := int64(80)
+	terminating := []latticemodel.Target{
+		{TargetIP: "10.0.0.1", Port: port},
+	}
+
+	tests := []struct {
+		name           string
+		newStatus      *string
+		wantRegister   []*vpclattice.Target
+		wantDeregister []*vpclattice.Target
+	}{
+		{
+			name:      "terminating target stays registered while its replacement is registered",
+			newStatus: nil,
+			wantRegister: []*vpclattice.Target{
+				{Id: aws.String("10.0.0.2"), Port: aws.Int64(port)},
+			},
+		},
+		{
+			name:      "terminating target stays registered while its replacement is initial",
+			newStatus: aws.String(vpclattice.TargetStatusInitial),
+		},
+		{
+			name:      "terminating target is deregistered once its replacement is healthy",
+			newStatus: aws.String(vpclattice.TargetStatusHealthy),
+			wantDeregister: []*vpclattice.Target{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(port)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdkTargets := []*vpclattice.TargetSummary{
+				{Id: aws.String("10.0.0.1"), Port: aws.Int64(port), Status: aws.String(vpclattice.TargetStatusHealthy)},
+			}
+			if tt.newStatus != nil {
+				sdkTargets = append(sdkTargets, &vpclattice.TargetSummary{
+					Id: aws.String("10.0.0.2"), Port: aws.Int64(port), Status: tt.newStatus,
+				})
+			}
+			targets := []latticemodel.Target{
+				{TargetIP: "10.0.0.2", Port: port},
+			}
+
+			register, deregister, pending := buildTargetsDelta(targets, terminating, sdkTargets)
+
+			assert.Equal(t, tt.wantRegister, register)
+			assert.Equal(t, tt.wantDeregister, deregister)
+			// retried until the terminating target finished draining
+			assert.Equal(t, 1, pending)
+		})
+	}
+}
+
+func Test_buildTargetsDelta_Lambda(t *testing.T) {
+	oldARN := "arn:aws:lambda:us-west-2:123456789012:function:fn:1"
+	newARN := "arn:aws:lambda:us-west-2:123456789012:function:fn:2"
+	sdkTargets := []*vpclattice.TargetSummary{
+		{Id: aws.String(oldARN), Status: aws.String(vpclattice.TargetStatusHealthy)},
+	}
+	targets := []latticemodel.Target{
+		{TargetIP: newARN},
+	}
+
+	register, deregister, _ := buildTargetsDelta(targets, nil, sdkTargets)
+
+	// functions are registered without port
+	assert.Equal(t, []*vpclattice.Target{{Id: aws.String(newARN)}}, register)
+	assert.Equal(t, []*vpclattice.Target{{Id: aws.String(oldARN)}}, deregister)
+
+	register, deregister, _ = buildTargetsDelta(targets, nil, []*vpclattice.TargetSummary{
+		{Id: aws.String(newARN), Status: aws.String(vpclattice.TargetStatusHealthy)},
+	})
+	assert.Nil(t, register)
+	assert.Nil(t, deregister)
+}
+
+func Test_targetsTargetGroupName(t *testing.T) {
+	targets := &latticemodel.Targets{
+		Spec: latticemodel.TargetsSpec{
+			Name:      "name",
+			Namespace: "ns",
+			Port:      8080,
+		},
+	}
+	assert.Equal(t, latticestore.TargetGroupPortName("name", "ns", 8080), targetsTargetGroupName(targets))
+
+	targets.Spec.IsLambda = true
+	assert.Equal(t, latticestore.LambdaTargetGroupName("name", "ns"), targetsTargetGroupName(targets))
+}
+
+func Test_chunkTargets(t *testing.T) {
+	tests := []struct {
+		count          int
+		expectedChunks []int
+	}{
+		{count: 0, expectedChunks: nil},
+		{count: 1, expectedChunks: []int{1}},
+		{count: maxTargetsPerCall, expectedChunks: []int{maxTargetsPerCall}},
+		{count: 2*maxTargetsPerCall + 1, expectedChunks: []int{maxTargetsPerCall, maxTargetsPerCall, 1}},
+	}
+
+	for _, tt := range tests {
+		var targets []*vpclattice.Target
+		for i := 0; i < tt.count; i++ {
+			targets = append(targets, &vpclattice.Target{Id: aws.String(fmt.Sprintf("10.0.%d.%d", i/256, i%256))})
+		}
+
+		var chunks []int
+		for _, chunk := range chunkTargets(targets) {
+			chunks = append(chunks, len(chunk))
+		}
+		assert.Equal(t, tt.expectedChunks, chunks)
+	}
 }
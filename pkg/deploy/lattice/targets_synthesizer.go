@@ -2,7 +2,6 @@ package lattice
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/golang/glog"
 
@@ -38,18 +37,25 @@ func (t *targetsSynthesizer) Synthesize(ctx context.Context) error {
 
 }
 
+// SynthesizeTargets registers the targets of all target groups, a failure of one target group does not stop the
+// others. Targets still draining are no failure, they are left in the status for the Service reconciler to requeue
 func (t *targetsSynthesizer) SynthesizeTargets(ctx context.Context, resTargets []*latticemodel.Targets) error {
+	var synthesizeErr error
 
 	for _, targets := range resTargets {
-		err := t.targetsManager.Create(ctx, targets)
+		status, err := t.targetsManager.Create(ctx, targets)
 
 		if err != nil {
 			errmsg := fmt.Sprintf("TargetSynthesize: Failed to create targets :%v , err:%v\n", targets, err)
 			glog.V(6).Infof("Errmsg: %s \n", errmsg)
-			return errors.New(errmsg)
-
+			if synthesizeErr == nil {
+				// keep the failed targets of the error
+				synthesizeErr = fmt.Errorf("TargetSynthesize: Failed to create targets :%v , err:%w", targets, err)
+			}
+			continue
 		}
-		tgName := latticestore.TargetGroupName(targets.Spec.Name, targets.Spec.Namespace)
+		targets.Status = &status
+		tgName := targetsTargetGroupName(targets)
 
 		var targetList []latticestore.Target
 
@@ -63,7 +69,7 @@ func (t *targetsSynthesizer) SynthesizeTargets(ctx context.Context, resTargets [
 		t.latticeDataStore.UpdateTargetsForTargetGroup(tgName, targets.Spec.RouteName, targetList)
 
 	}
-	return nil
+	return synthesizeErr
 
 }
 
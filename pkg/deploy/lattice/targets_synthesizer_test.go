@@ -2,6 +2,7 @@ package lattice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -102,7 +103,7 @@ func Test_SynthesizeTargets(t *testing.T) {
 
 		resTargetsList = append(resTargetsList, &modelTarget)
 
-		mockTargetsManager.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		mockTargetsManager.EXPECT().Create(ctx, gomock.Any()).Return(latticemodel.TargetsStatus{}, nil)
 
 		err = targetsSynthesizer.SynthesizeTargets(ctx, resTargetsList)
 		assert.Nil(t, err)
@@ -119,3 +120,46 @@ func Test_SynthesizeTargets(t *testing.T) {
 	}
 
 }
+
+// a failure or draining targets of one target group do not stop the targets of the others
+func Test_SynthesizeTargets_AllTargetGroups(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	ds := latticestore.NewLatticeDataStore()
+	mockTargetsManager := NewMockTargetsManager(c)
+	stack := core.NewDefaultStack(core.StackID{Name: "route", Namespace: "default"})
+	targetsSynthesizer := NewTargetsSynthesizer(nil, mockTargetsManager, stack, ds)
+
+	var resTargets []*latticemodel.Targets
+	for _, name := range []string{"failed", "draining", "registered"} {
+		tgName := latticestore.TargetGroupName(name, "default")
+		assert.Nil(t, ds.AddTargetGroup(tgName, "", "", "", false, "route"))
+		resTargets = append(resTargets, &latticemodel.Targets{
+			Spec: latticemodel.TargetsSpec{
+				Name:         name,
+				Namespace:    "default",
+				RouteName:    "route",
+				TargetIPList: []latticemodel.Target{{TargetIP: "10.10.1.1", Port: 8080}},
+			},
+		})
+	}
+
+	gomock.InOrder(
+		mockTargetsManager.EXPECT().Create(ctx, resTargets[0]).Return(latticemodel.TargetsStatus{}, errors.New("register failed")),
+		mockTargetsManager.EXPECT().Create(ctx, resTargets[1]).Return(latticemodel.TargetsStatus{PendingTargets: 1}, nil),
+		mockTargetsManager.EXPECT().Create(ctx, resTargets[2]).Return(latticemodel.TargetsStatus{}, nil),
+	)
+
+	err := targetsSynthesizer.SynthesizeTargets(ctx, resTargets)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, resTargets[0].Status)
+	assert.Equal(t, 1, resTargets[1].Status.PendingTargets)
+	assert.Equal(t, 0, resTargets[2].Status.PendingTargets)
+
+	dsTG, err := ds.GetTargetGroup(latticestore.TargetGroupName("registered", "default"), "route", false)
+	assert.Nil(t, err)
+	assert.Equal(t, []latticestore.Target{{TargetIP: "10.10.1.1", TargetPort: 8080}}, dsTG.EndPoints)
+}
@@ -152,24 +152,31 @@ func (d *latticeTargetsStackDeploy) Deploy(ctx context.Context, stack core.Stack
 
 	d.stack.ListResources(&resTargets)
 
+	var deployErr error
 	for _, targets := range resTargets {
-		err := d.targetsManager.Create(ctx, targets)
-		if err == nil {
-			tgName := latticestore.TargetGroupName(targets.Spec.Name, targets.Spec.Namespace)
-
-			var targetList []latticestore.Target
-			for _, target := range targetList {
-				t := latticestore.Target{
-					TargetIP:   target.TargetIP,
-					TargetPort: target.TargetPort,
-				}
+		status, err := d.targetsManager.Create(ctx, targets)
+		if err != nil {
+			if deployErr == nil {
+				deployErr = err
+			}
+			continue
+		}
+		// the caller requeues while the deregistered targets are draining
+		targets.Status = &status
+		tgName := latticestore.TargetGroupPortName(targets.Spec.Name, targets.Spec.Namespace, targets.Spec.Port)
+
+		var targetList []latticestore.Target
+		for _, target := range targets.Spec.TargetIPList {
+			t := latticestore.Target{
+				TargetIP:   target.TargetIP,
+				TargetPort: target.Port,
+			}
 
-				targetList = append(targetList, t)
+			targetList = append(targetList, t)
 
-			}
-			d.latticeDataStore.UpdateTargetsForTargetGroup(tgName, targets.Spec.RouteName, targetList)
 		}
+		d.latticeDataStore.UpdateTargetsForTargetGroup(tgName, targets.Spec.RouteName, targetList)
 
 	}
-	return nil
+	return deployErr
 }
@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+const (
+	LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH = "LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH"
+)
+
+type GRPCLatticeServiceBuilder interface {
+	Build(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) (core.Stack, *latticemodel.Service, error)
+}
+
+type grpcLatticeServiceModelBuilder struct {
+	client.Client
+	Datastore *latticestore.LatticeDataStore
+
+	cloud lattice_aws.Cloud
+}
+
+func NewGRPCLatticeServiceBuilder(client client.Client, datastore *latticestore.LatticeDataStore, cloud lattice_aws.Cloud) *grpcLatticeServiceModelBuilder {
+	return &grpcLatticeServiceModelBuilder{
+		Client:    client,
+		Datastore: datastore,
+		cloud:     cloud,
+	}
+}
+
+// Build translates the GRPCRoute into its HTTP equivalent, gRPC calls are HTTP/2 POSTs to /<service>/<method>,
+// and builds the lattice service from it with GRPC target groups
+func (b *grpcLatticeServiceModelBuilder) Build(ctx context.Context, grpcRoute *gateway_api_v1alpha2.GRPCRoute) (core.Stack, *latticemodel.Service, error) {
+	stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(grpcRoute)))
+
+	httpRoute, err := translateGRPCRoute(grpcRoute)
+	if err != nil {
+		glog.V(2).Infof("GRPCLatticeServiceBuilder: failed to translate grpcroute %v-%v, err %v\n",
+			grpcRoute.Name, grpcRoute.Namespace, err)
+		return stack, nil, err
+	}
+
+	task := &latticeServiceModelBuildTask{
+		httpRoute: httpRoute,
+		routeType: latticemodel.K8SGRPCRouteType,
+		stack:     stack,
+		Client:    b.Client,
+		tgByResID: make(map[string]*latticemodel.TargetGroup),
+		Datastore: b.Datastore,
+	}
+
+	if err := task.run(ctx); err != nil {
+		return stack, task.latticeService, fmt.Errorf("failed to build grpcroute %s-%s: %w",
+			grpcRoute.Name, grpcRoute.Namespace, err)
+	}
+
+	return task.stack, task.latticeService, nil
+}
+
+func translateGRPCRoute(grpcRoute *gateway_api_v1alpha2.GRPCRoute) (*gateway_api.HTTPRoute, error) {
+	httpRoute := &gateway_api.HTTPRoute{
+		ObjectMeta: *grpcRoute.ObjectMeta.DeepCopy(),
+		Spec: gateway_api.HTTPRouteSpec{
+			CommonRouteSpec: *grpcRoute.Spec.CommonRouteSpec.DeepCopy(),
+			Hostnames:       grpcRoute.Spec.Hostnames,
+		},
+	}
+
+	for _, grpcRule := range grpcRoute.Spec.Rules {
+		httpRule := gateway_api.HTTPRouteRule{}
+
+		for _, grpcMatch := range grpcRule.Matches {
+			httpMatch, err := translateGRPCRouteMatch(grpcMatch)
+			if err != nil {
+				return nil, err
+			}
+			httpRule.Matches = append(httpRule.Matches, httpMatch)
+		}
+
+		for _, grpcFilter := range grpcRule.Filters {
+			httpRule.Filters = append(httpRule.Filters, gateway_api.HTTPRouteFilter{
+				Type:                   gateway_api.HTTPRouteFilterType(grpcFilter.Type),
+				RequestHeaderModifier:  grpcFilter.RequestHeaderModifier,
+				ResponseHeaderModifier: grpcFilter.ResponseHeaderModifier,
+				RequestMirror:          grpcFilter.RequestMirror,
+				ExtensionRef:           grpcFilter.ExtensionRef,
+			})
+		}
+
+		for _, grpcBackendRef := range grpcRule.BackendRefs {
+			if len(grpcBackendRef.Filters) != 0 {
+				glog.V(2).Infof("Unsupported backendRef filters %v for grpcroute %s namespace %s",
+					grpcBackendRef.Filters, grpcRoute.Name, grpcRoute.Namespace)
+				return nil, errors.New(LATTICE_UNSUPPORTED_FILTER)
+			}
+			httpRule.BackendRefs = append(httpRule.BackendRefs, gateway_api.HTTPBackendRef{
+				BackendRef: *grpcBackendRef.BackendRef.DeepCopy(),
+			})
+		}
+
+		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, httpRule)
+	}
+
+	return httpRoute, nil
+}
+
+func translateGRPCRouteMatch(grpcMatch gateway_api_v1alpha2.GRPCRouteMatch) (gateway_api.HTTPRouteMatch, error) {
+	httpMatch := gateway_api.HTTPRouteMatch{}
+
+	if grpcMatch.Method != nil {
+		method := grpcMatch.Method
+
+		if method.Type != nil && *method.Type != gateway_api_v1alpha2.GRPCMethodMatchExact {
+			glog.V(2).Infof("Unsupported grpc method match type %v\n", *method.Type)
+			return httpMatch, errors.New(LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH)
+		}
+
+		if method.Service == nil || *method.Service == "" {
+			if method.Method != nil && *method.Method != "" {
+				// lattice can not match the method of any service
+				glog.V(2).Infof("Unsupported grpc method match without service, method %v\n", *method.Method)
+				return httpMatch, errors.New(LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH)
+			}
+		} else if method.Method == nil || *method.Method == "" {
+			pathType := gateway_api.PathMatchPathPrefix
+			pathValue := fmt.Sprintf("/%s/", *method.Service)
+			httpMatch.Path = &gateway_api.HTTPPathMatch{
+				Type:  &pathType,
+				Value: &pathValue,
+			}
+		} else {
+			pathType := gateway_api.PathMatchExact
+			pathValue := fmt.Sprintf("/%s/%s", *method.Service, *method.Method)
+			httpMatch.Path = &gateway_api.HTTPPathMatch{
+				Type:  &pathType,
+				Value: &pathValue,
+			}
+		}
+	}
+
+	for _, header := range grpcMatch.Headers {
+		httpMatch.Headers = append(httpMatch.Headers, gateway_api.HTTPHeaderMatch{
+			Type:  header.Type,
+			Name:  gateway_api.HTTPHeaderName(header.Name),
+			Value: header.Value,
+		})
+	}
+
+	return httpMatch, nil
+}
@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func Test_GRPCRouteTranslate(t *testing.T) {
+	var serviceKind gateway_api.Kind = "Service"
+	var namespace = gateway_api.Namespace("default")
+	var grpcService = "helloworld.Greeter"
+	var grpcMethod = "SayHello"
+	var emptyString = ""
+	var exactType = gateway_api_v1alpha2.GRPCMethodMatchExact
+	var regexType = gateway_api_v1alpha2.GRPCMethodMatchRegularExpression
+	var headerExactType = gateway_api.HeaderMatchExact
+	var k8sPathMatchExactType = gateway_api.PathMatchExact
+	var k8sPathMatchPrefixType = gateway_api.PathMatchPathPrefix
+	var exactPath = "/helloworld.Greeter/SayHello"
+	var prefixPath = "/helloworld.Greeter/"
+
+	var backendRef1 = gateway_api.BackendRef{
+		BackendObjectReference: gateway_api.BackendObjectReference{
+			Name:      "targetgroup1",
+			Namespace: &namespace,
+			Kind:      &serviceKind,
+		},
+	}
+
+	var fixedResponseRef = gateway_api.LocalObjectReference{
+		Group: FixedResponseFilterGroup,
+		Kind:  FixedResponseFilterKind,
+		Name:  "503",
+	}
+
+	tests := []struct {
+		name            string
+		methodMatch     *gateway_api_v1alpha2.GRPCMethodMatch
+		headers         []gateway_api_v1alpha2.GRPCHeaderMatch
+		filters         []gateway_api_v1alpha2.GRPCRouteFilter
+		expectedMatches []gateway_api.HTTPRouteMatch
+		expectedFilters []gateway_api.HTTPRouteFilter
+		wantErr         error
+	}{
+		{
+			name: "service and method match",
+			methodMatch: &gateway_api_v1alpha2.GRPCMethodMatch{
+				Type:    &exactType,
+				Service: &grpcService,
+				Method:  &grpcMethod,
+			},
+			expectedMatches: []gateway_api.HTTPRouteMatch{
+				{
+					Path: &gateway_api.HTTPPathMatch{
+						Type:  &k8sPathMatchExactType,
+						Value: &exactPath,
+					},
+				},
+			},
+		},
+		{
+			name: "service only match, default type",
+			methodMatch: &gateway_api_v1alpha2.GRPCMethodMatch{
+				Service: &grpcService,
+				Method:  &emptyString,
+			},
+			expectedMatches: []gateway_api.HTTPRouteMatch{
+				{
+					Path: &gateway_api.HTTPPathMatch{
+						Type:  &k8sPathMatchPrefixType,
+						Value: &prefixPath,
+					},
+				},
+			},
+		},
+		{
+			name: "header match only",
+			headers: []gateway_api_v1alpha2.GRPCHeaderMatch{
+				{
+					Type:  &headerExactType,
+					Name:  "env",
+					Value: "test",
+				},
+			},
+			expectedMatches: []gateway_api.HTTPRouteMatch{
+				{
+					Headers: []gateway_api.HTTPHeaderMatch{
+						{
+							Type:  &headerExactType,
+							Name:  "env",
+							Value: "test",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Negative, method only match",
+			methodMatch: &gateway_api_v1alpha2.GRPCMethodMatch{
+				Type:   &exactType,
+				Method: &grpcMethod,
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH),
+		},
+		{
+			name: "fixed response filter",
+			methodMatch: &gateway_api_v1alpha2.GRPCMethodMatch{
+				Type:    &exactType,
+				Service: &grpcService,
+				Method:  &grpcMethod,
+			},
+			filters: []gateway_api_v1alpha2.GRPCRouteFilter{
+				{
+					Type:         gateway_api_v1alpha2.GRPCRouteFilterExtensionRef,
+					ExtensionRef: &fixedResponseRef,
+				},
+			},
+			expectedMatches: []gateway_api.HTTPRouteMatch{
+				{
+					Path: &gateway_api.HTTPPathMatch{
+						Type:  &k8sPathMatchExactType,
+						Value: &exactPath,
+					},
+				},
+			},
+			expectedFilters: []gateway_api.HTTPRouteFilter{
+				{
+					Type:         gateway_api.HTTPRouteFilterExtensionRef,
+					ExtensionRef: &fixedResponseRef,
+				},
+			},
+		},
+		{
+			name: "Negative, regular expression match",
+			methodMatch: &gateway_api_v1alpha2.GRPCMethodMatch{
+				Type:    &regexType,
+				Service: &grpcService,
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_GRPC_METHOD_MATCH),
+		},
+	}
+
+	for _, tt := range tests {
+		fmt.Printf("Testing >>> %v\n", tt.name)
+
+		grpcRoute := &gateway_api_v1alpha2.GRPCRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service1",
+				Namespace: "default",
+			},
+			Spec: gateway_api_v1alpha2.GRPCRouteSpec{
+				CommonRouteSpec: gateway_api.CommonRouteSpec{
+					ParentRefs: []gateway_api.ParentReference{
+						{
+							Name: "mesh1",
+						},
+					},
+				},
+				Rules: []gateway_api_v1alpha2.GRPCRouteRule{
+					{
+						Matches: []gateway_api_v1alpha2.GRPCRouteMatch{
+							{
+								Method:  tt.methodMatch,
+								Headers: tt.headers,
+							},
+						},
+						Filters: tt.filters,
+						BackendRefs: []gateway_api_v1alpha2.GRPCBackendRef{
+							{
+								BackendRef: backendRef1,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		httpRoute, err := translateGRPCRoute(grpcRoute)
+
+		if tt.wantErr != nil {
+			assert.Equal(t, tt.wantErr, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, grpcRoute.Name, httpRoute.Name)
+		assert.Equal(t, grpcRoute.Namespace, httpRoute.Namespace)
+		assert.Equal(t, grpcRoute.Spec.ParentRefs, httpRoute.Spec.ParentRefs)
+		assert.Equal(t, 1, len(httpRoute.Spec.Rules))
+		assert.Equal(t, tt.expectedMatches, httpRoute.Spec.Rules[0].Matches)
+		assert.Equal(t, tt.expectedFilters, httpRoute.Spec.Rules[0].Filters)
+		assert.Equal(t, []gateway_api.HTTPBackendRef{{BackendRef: backendRef1}}, httpRoute.Spec.Rules[0].BackendRefs)
+	}
+}
@@ -2,7 +2,6 @@ package gateway
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/golang/glog"
 
@@ -55,7 +54,8 @@ func (b *latticeServiceModelBuilder) Build(ctx context.Context, httpRoute *gatew
 	}
 
 	if err := task.run(ctx); err != nil {
-		return stack, task.latticeService, errors.New("LATTICE_RETRY")
+		return stack, task.latticeService, fmt.Errorf("failed to build httproute %s-%s: %w",
+			httpRoute.Name, httpRoute.Namespace, err)
 	}
 
 	return task.stack, task.latticeService, nil
@@ -128,9 +128,27 @@ func (t *latticeServiceModelBuildTask) buildLatticeService(ctx context.Context)
 		//ServiceNetworkNames: string(t.httpRoute.Spec.ParentRefs[0].Name),
 	}
 
-	for _, parentRef := range t.httpRoute.Spec.ParentRefs {
-		spec.ServiceNetworkNames = append(spec.ServiceNetworkNames, string(parentRef.Name))
+	route := k8s.RouteInfo{
+		Kind:       t.routeKind(),
+		Name:       t.httpRoute.Name,
+		Namespace:  t.httpRoute.Namespace,
+		ParentRefs: t.httpRoute.Spec.ParentRefs,
+	}
 
+	// the service is only associated to the service networks of the gateways which accept the route
+	serviceNetworks := make(map[string]bool)
+	for _, parent := range k8s.ValidateRouteParents(ctx, t.Client, route) {
+		if !parent.Accepted {
+			glog.V(6).Infof("Skip service network %v for route %s-%s, %s\n",
+				parent.ParentRef.Name, t.httpRoute.Name, t.httpRoute.Namespace, parent.Message)
+			continue
+		}
+		// several parentRefs can point to different sections of the same gateway
+		if serviceNetworks[string(parent.ParentRef.Name)] {
+			continue
+		}
+		serviceNetworks[string(parent.ParentRef.Name)] = true
+		spec.ServiceNetworkNames = append(spec.ServiceNetworkNames, string(parent.ParentRef.Name))
 	}
 	defaultGateway, err := config.GetClusterLocalGateway()
 	if err == nil {
@@ -164,6 +182,8 @@ func (t *latticeServiceModelBuildTask) buildLatticeService(ctx context.Context)
 
 type latticeServiceModelBuildTask struct {
 	httpRoute *gateway_api.HTTPRoute
+	// routeType is the kind of the k8s route the httpRoute is built from, empty for HTTPRoute
+	routeType string
 	client.Client
 
 	latticeService  *latticemodel.Service
@@ -175,3 +195,23 @@ type latticeServiceModelBuildTask struct {
 	Datastore *latticestore.LatticeDataStore
 	cloud     lattice_aws.Cloud
 }
+
+// routeKind returns the kind of the k8s route the httpRoute is built from
+func (t *latticeServiceModelBuildTask) routeKind() string {
+	switch t.routeType {
+	case latticemodel.K8SGRPCRouteType:
+		return k8s.GRPCRouteKind
+	case latticemodel.K8STLSRouteType:
+		return k8s.TLSRouteKind
+	}
+	return k8s.HTTPRouteKind
+}
+
+// isBackendRefPermitted returns whether the route may refer to backendRef. While the route is being deleted
+// every backendRef is permitted, so that whatever was built for it before is cleaned up
+func (t *latticeServiceModelBuildTask) isBackendRefPermitted(ctx context.Context, backendRef gateway_api.BackendObjectReference) bool {
+	if !t.httpRoute.DeletionTimestamp.IsZero() {
+		return true
+	}
+	return k8s.IsBackendRefPermitted(ctx, t.Client, t.routeKind(), t.httpRoute.Namespace, backendRef)
+}
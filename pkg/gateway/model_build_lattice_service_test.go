@@ -15,6 +15,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 
@@ -203,3 +204,123 @@ func Test_LatticeServiceModelBuild(t *testing.T) {
 		})
 	}
 }
+
+func Test_LatticeServiceModelBuild_ServiceNetworks(t *testing.T) {
+	var httpSectionName gateway_api.SectionName = "http"
+	var tlsSectionName gateway_api.SectionName = "tls"
+
+	gwClasses := []*gateway_api.GatewayClass{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "amazon-vpc-lattice"},
+			Spec:       gateway_api.GatewayClassSpec{ControllerName: config.LatticeGatewayControllerName},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other"},
+			Spec:       gateway_api.GatewayClassSpec{ControllerName: "example.com/other-controller"},
+		},
+	}
+
+	gateways := []*gateway_api.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway1", Namespace: "default"},
+			Spec: gateway_api.GatewaySpec{
+				GatewayClassName: "amazon-vpc-lattice",
+				Listeners: []gateway_api.Listener{
+					{Name: httpSectionName, Port: 80, Protocol: gateway_api.HTTPProtocolType},
+				},
+			},
+		},
+		{
+			// only allows TLSRoutes
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway2", Namespace: "default"},
+			Spec: gateway_api.GatewaySpec{
+				GatewayClassName: "amazon-vpc-lattice",
+				Listeners: []gateway_api.Listener{
+					{Name: tlsSectionName, Port: 443, Protocol: gateway_api.TLSProtocolType},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway3", Namespace: "default"},
+			Spec: gateway_api.GatewaySpec{
+				GatewayClassName: "other",
+				Listeners: []gateway_api.Listener{
+					{Name: httpSectionName, Port: 80, Protocol: gateway_api.HTTPProtocolType},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                    string
+		parentRefs              []gateway_api.ParentReference
+		wantServiceNetworkNames []string
+	}{
+		{
+			name: "accepted parent",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "gateway1"},
+			},
+			wantServiceNetworkNames: []string{"gateway1"},
+		},
+		{
+			name: "rejected parents are not service networks",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "gateway1", SectionName: &httpSectionName},
+				{Name: "gateway1", SectionName: &tlsSectionName},
+				{Name: "gateway2"},
+				{Name: "gateway3"},
+				{Name: "unknown-gateway"},
+			},
+			wantServiceNetworkNames: []string{"gateway1"},
+		},
+		{
+			name: "no accepted parent",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "gateway2", SectionName: &tlsSectionName},
+			},
+			wantServiceNetworkNames: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			gateway_api.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewFakeClientWithScheme(k8sSchema)
+			for _, gwClass := range gwClasses {
+				assert.NoError(t, k8sClient.Create(ctx, gwClass.DeepCopy()))
+			}
+			for _, gw := range gateways {
+				assert.NoError(t, k8sClient.Create(ctx, gw.DeepCopy()))
+			}
+
+			httpRoute := &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: tt.parentRefs,
+					},
+				},
+			}
+
+			task := &latticeServiceModelBuildTask{
+				httpRoute: httpRoute,
+				stack:     core.NewDefaultStack(core.StackID(k8s.NamespacedName(httpRoute))),
+				Client:    k8sClient,
+				tgByResID: make(map[string]*latticemodel.TargetGroup),
+				Datastore: latticestore.NewLatticeDataStore(),
+			}
+
+			err := task.buildLatticeService(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantServiceNetworkNames, task.latticeService.Spec.ServiceNetworkNames)
+		})
+	}
+}
@@ -7,9 +7,9 @@ import (
 
 	"github.com/golang/glog"
 
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 
-	"k8s.io/apimachinery/pkg/types"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
@@ -17,93 +17,159 @@ const (
 	resourceIDListenerConfig = "ListenerConfig"
 
 	awsCustomCertARN = "application-networking.k8s.aws/certificate-arn"
+
+	// error code, none of the parents of the route has a listener the route can attach to
+	LATTICE_NO_PARENT_LISTENER = "LATTICE_NO_PARENT_LISTENER"
+
+	// error code, parent listeners on the same port have different protocols, a lattice service has
+	// one listener per port
+	LATTICE_CONFLICTING_LISTENER_PROTOCOLS = "LATTICE_CONFLICTING_LISTENER_PROTOCOLS"
+
+	// error code, parent listeners terminate TLS with different certificates, lattice keeps one
+	// certificate per service
+	LATTICE_CONFLICTING_CERTIFICATES = "LATTICE_CONFLICTING_CERTIFICATES"
 )
 
-func (t *latticeServiceModelBuildTask) extractListnerInfo(ctx context.Context, parentRef gateway_api.ParentReference) (int64, string, string, error) {
+// parentListener is a distinct port and protocol among the gateway listeners the route attaches to
+type parentListener struct {
+	port     int64
+	protocol string
+	tlsMode  string
+	certARN  string
+}
 
-	var protocol gateway_api.ProtocolType = gateway_api.HTTPProtocolType
-	if parentRef.SectionName != nil {
-		glog.V(6).Infof("HTTP SectionName %s \n", *parentRef.SectionName)
-	}
+// buildParentListeners validates each parentRef of the route independently and returns the distinct
+// listeners of the parents the route is attached to, parents which can not be resolved are skipped
+func (t *latticeServiceModelBuildTask) buildParentListeners(ctx context.Context) ([]parentListener, error) {
+	routeKind := t.routeKind()
 
-	glog.V(6).Infof("Building Listener for HTTPRoute Name %s NameSpace %s\n", t.httpRoute.Name, t.httpRoute.Namespace)
-	var gwNamespace = t.httpRoute.Namespace
-	if t.httpRoute.Spec.ParentRefs[0].Namespace != nil {
-		gwNamespace = string(*t.httpRoute.Spec.ParentRefs[0].Namespace)
-	}
-	glog.V(6).Infof("build Listener, Parent Name %s Namespace %s\n", t.httpRoute.Spec.ParentRefs[0].Name, gwNamespace)
-	var listenerPort = 0
-	gw := &gateway_api.Gateway{}
-	gwName := types.NamespacedName{
-		Namespace: gwNamespace,
-		Name:      string(t.httpRoute.Spec.ParentRefs[0].Name),
-	}
+	var listeners []parentListener
+	listenerIndex := make(map[string]int)
+	portProtocols := make(map[int64]string)
+	serviceCertARN := ""
 
-	if err := t.Client.Get(ctx, gwName, gw); err != nil {
-		glog.V(2).Infof("Failed to build Listener due to unknow http parent ref , Name %v, err %v \n", gwName, err)
-		return 0, "", "", err
-	}
+	for _, parentRef := range t.httpRoute.Spec.ParentRefs {
+		if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+			glog.V(2).Infof("Ignore parentref %v of kind %v", parentRef.Name, *parentRef.Kind)
+			continue
+		}
 
-	var certARN = ""
-	// go through parent find out the matching section name
-	if parentRef.SectionName != nil {
-		glog.V(6).Infof("HTTP SectionName %s \n", *parentRef.SectionName)
-		for _, section := range gw.Spec.Listeners {
-			glog.V(6).Infof("listener: %v\n", section)
-			if section.Name == *parentRef.SectionName {
-				listenerPort = int(section.Port)
-				protocol = section.Protocol
+		gw := &gateway_api.Gateway{}
+		gwName := k8s.ParentGatewayName(t.httpRoute.Namespace, parentRef)
+		glog.V(6).Infof("build Listener, Parent Name %s Namespace %s\n", gwName.Name, gwName.Namespace)
 
-				if section.TLS != nil {
-					if section.TLS.Mode != nil && *section.TLS.Mode == gateway_api.TLSModeTerminate {
-						curCertARN, ok := section.TLS.Options[awsCustomCertARN]
+		if err := t.Client.Get(ctx, gwName, gw); err != nil {
+			glog.V(2).Infof("Ignore unknown parent ref for route %s-%s, Name %v, err %v \n",
+				t.httpRoute.Name, t.httpRoute.Namespace, gwName, err)
+			continue
+		}
 
-						if ok {
-							glog.V(6).Infof("Found certification %v under section %v",
-								curCertARN, section.Name)
-							certARN = string(curCertARN)
-						}
+		parent := k8s.NewRouteParent(ctx, t.Client, gw, parentRef, routeKind, t.httpRoute.Namespace)
+		if !parent.Accepted {
+			glog.V(2).Infof("Ignore parent ref %v for route %s-%s, %s",
+				gwName, t.httpRoute.Name, t.httpRoute.Namespace, parent.Message)
+			continue
+		}
 
-					}
+		for _, section := range parent.Listeners {
+			glog.V(6).Infof("listener: %v\n", section)
+
+			protocol, tlsMode, ok := latticeListenerProtocol(section)
+			if !ok {
+				glog.V(2).Infof("Ignore listener %v of gateway %v for route %s-%s, protocol %v is not supported",
+					section.Name, gwName, t.httpRoute.Name, t.httpRoute.Namespace, section.Protocol)
+				continue
+			}
+
+			certARN := ""
+			if section.TLS != nil && section.TLS.Mode != nil && *section.TLS.Mode == gateway_api.TLSModeTerminate {
+				if curCertARN, ok := section.TLS.Options[awsCustomCertARN]; ok {
+					glog.V(6).Infof("Found certification %v under section %v", curCertARN, section.Name)
+					certARN = string(curCertARN)
+				}
+			}
 
+			if certARN != "" {
+				if serviceCertARN != "" && serviceCertARN != certARN {
+					glog.V(2).Infof("Conflicting certificates %v and %v of parent listeners for route %s-%s",
+						serviceCertARN, certARN, t.httpRoute.Name, t.httpRoute.Namespace)
+					return nil, errors.New(LATTICE_CONFLICTING_CERTIFICATES)
 				}
+				serviceCertARN = certARN
+			}
 
+			if portProtocol, ok := portProtocols[int64(section.Port)]; ok && portProtocol != protocol {
+				glog.V(2).Infof("Conflicting protocols %v and %v of parent listeners on port %v for route %s-%s",
+					portProtocol, protocol, section.Port, t.httpRoute.Name, t.httpRoute.Namespace)
+				return nil, errors.New(LATTICE_CONFLICTING_LISTENER_PROTOCOLS)
 			}
+			portProtocols[int64(section.Port)] = protocol
+
+			key := fmt.Sprintf("%d-%s", section.Port, protocol)
+			if i, ok := listenerIndex[key]; ok {
+				// the same port and protocol on several gateways is one lattice listener
+				if listeners[i].certARN == "" {
+					listeners[i].certARN = certARN
+				}
+				continue
+			}
+
+			listenerIndex[key] = len(listeners)
+			listeners = append(listeners, parentListener{
+				port:     int64(section.Port),
+				protocol: protocol,
+				tlsMode:  tlsMode,
+				certARN:  certARN,
+			})
 		}
-	} else {
-		// use 1st listener port
-		// TODO check no listerner
-		if len(gw.Spec.Listeners) == 0 {
-			glog.V(2).Infof("Error building listener, there is NO listeners on GW for %v\n",
-				gwName)
-			return 0, "", "", errors.New("Error building listener, there is NO listeners on GW")
-		}
-		listenerPort = int(gw.Spec.Listeners[0].Port)
 	}
 
-	return int64(listenerPort), string(protocol), certARN, nil
+	if len(listeners) == 0 {
+		glog.V(2).Infof("Error building listener, there is NO valid parent listener for route %s-%s\n",
+			t.httpRoute.Name, t.httpRoute.Namespace)
+		return nil, errors.New(LATTICE_NO_PARENT_LISTENER)
+	}
 
+	return listeners, nil
 }
 
-func (t *latticeServiceModelBuildTask) buildListener(ctx context.Context) error {
-
-	for _, parentRef := range t.httpRoute.Spec.ParentRefs {
-		if parentRef.Name != t.httpRoute.Spec.ParentRefs[0].Name {
-			// when a service is associate to multiple service network(s), all listener config MUST be same
-			// so here we are only using the 1st gateway
-			glog.V(2).Infof("Ignore parentref of different gateway %v", parentRef.Name)
+// latticeListenerProtocol returns the lattice listener protocol and TLS mode of a gateway listener. A TLS
+// listener is a lattice TLS passthrough listener, lattice can not terminate TLS for TCP traffic, and an
+// HTTPS listener terminates TLS, lattice can not pass HTTPS through
+func latticeListenerProtocol(listener gateway_api.Listener) (string, string, bool) {
+	tlsMode := gateway_api.TLSModeTerminate
+	if listener.TLS != nil && listener.TLS.Mode != nil {
+		tlsMode = *listener.TLS.Mode
+	}
 
-			continue
+	switch listener.Protocol {
+	case gateway_api.HTTPSProtocolType:
+		if tlsMode != gateway_api.TLSModeTerminate {
+			return "", "", false
+		}
+		return string(listener.Protocol), latticemodel.ListenerTLSModeTerminate, true
+	case gateway_api.TLSProtocolType:
+		if listener.TLS == nil || tlsMode != gateway_api.TLSModePassthrough {
+			return "", "", false
 		}
+		return latticemodel.ListenerProtocolTLSPassthrough, latticemodel.ListenerTLSModePassthrough, true
+	}
+	return string(listener.Protocol), "", true
+}
 
-		port, protocol, certARN, err := t.extractListnerInfo(ctx, parentRef)
+func (t *latticeServiceModelBuildTask) buildListener(ctx context.Context) error {
 
-		if err != nil {
-			glog.V(6).Infof("Error on buildListener %v\n", err)
-			return err
-		}
-		if t.latticeService != nil {
-			t.latticeService.Spec.CustomerCertARN = certARN
+	listeners, err := t.buildParentListeners(ctx)
+	if err != nil {
+		glog.V(6).Infof("Error on buildListener %v\n", err)
+		return err
+	}
+
+	for _, listener := range listeners {
+		port, protocol := listener.port, listener.protocol
+
+		if t.latticeService != nil && listener.certARN != "" {
+			t.latticeService.Spec.CustomerCertARN = listener.certARN
 		}
 
 		glog.V(6).Infof("Building Listener: found matching listner Port %v\n", port)
@@ -113,47 +179,38 @@ func (t *latticeServiceModelBuildTask) buildListener(ctx context.Context) error
 			return errors.New("Error building listener, there are no rules")
 		}
 
-		rule := t.httpRoute.Spec.Rules[0]
-
-		if len(rule.BackendRefs) == 0 {
-			glog.V(6).Infof("Error building listener, there is no backend refs for %v \n", t.httpRoute)
-			return errors.New("Error building listener, there are no backend refs")
+		action, err := t.buildListenerDefaultAction(ctx)
+		if err != nil {
+			return err
 		}
 
-		httpBackendRef := rule.BackendRefs[0]
+		listenerResourceName := fmt.Sprintf("%s-%s-%d-%s", t.httpRoute.Name, t.httpRoute.Namespace, port, protocol)
+		glog.V(6).Infof("listenerResourceName : %v \n", listenerResourceName)
 
-		var is_import = false
-		var targetgroupName = ""
-		var targetgroupNamespace = t.httpRoute.Namespace
+		latticeListener := latticemodel.NewListener(t.stack, listenerResourceName, port, protocol,
+			t.httpRoute.Name, t.httpRoute.Namespace, action)
+		latticeListener.Spec.TLSMode = listener.tlsMode
+		latticeListener.Spec.CertificateARN = listener.certARN
+	}
 
-		if string(*httpBackendRef.Kind) == "Service" {
-			if httpBackendRef.BackendObjectReference.Namespace != nil {
-				targetgroupNamespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-			}
-			targetgroupName = string(httpBackendRef.BackendObjectReference.Name)
-			is_import = false
-		}
+	return nil
 
-		if string(*httpBackendRef.Kind) == "ServiceImport" {
-			is_import = true
-			if httpBackendRef.BackendObjectReference.Namespace != nil {
-				targetgroupNamespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-			}
-			targetgroupName = string(httpBackendRef.BackendObjectReference.Name)
-		}
+}
 
-		action := latticemodel.DefaultAction{
-			Is_Import:               is_import,
-			BackendServiceName:      targetgroupName,
-			BackendServiceNamespace: targetgroupNamespace,
+// buildListenerDefaultAction returns the action of the first catch-all rule of the route, a rule without
+// matches, which is not built as a lattice rule. Without a catch-all rule the listener responds with 404
+func (t *latticeServiceModelBuildTask) buildListenerDefaultAction(ctx context.Context) (latticemodel.RuleAction, error) {
+	for i := range t.httpRoute.Spec.Rules {
+		httpRule := &t.httpRoute.Spec.Rules[i]
+		if len(httpRule.Matches) != 0 {
+			continue
 		}
 
-		listenerResourceName := fmt.Sprintf("%s-%s-%d-%s", t.httpRoute.Name, t.httpRoute.Namespace, port, protocol)
-		glog.V(6).Infof("listenerResourceName : %v \n", listenerResourceName)
-
-		latticemodel.NewListener(t.stack, listenerResourceName, port, protocol, t.httpRoute.Name, t.httpRoute.Namespace, action)
+		glog.V(6).Infof("Building Listener: default action from catch-all rule %v\n", *httpRule)
+		return t.buildRuleAction(ctx, httpRule)
 	}
 
-	return nil
-
+	return latticemodel.RuleAction{
+		FixedResponseStatusCode: noBackendFixedResponseStatusCode,
+	}, nil
 }
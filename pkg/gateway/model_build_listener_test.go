@@ -9,7 +9,9 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -33,6 +35,7 @@ func Test_ListenerModelBuild(t *testing.T) {
 	var httpSectionName gateway_api.SectionName = "http"
 	var serviceKind gateway_api.Kind = "Service"
 	var serviceimportKind gateway_api.Kind = "ServiceImport"
+	var path = "/ver1"
 	var backendRef = gateway_api.BackendRef{
 		BackendObjectReference: gateway_api.BackendObjectReference{
 			Name: "targetgroup1",
@@ -55,9 +58,13 @@ func Test_ListenerModelBuild(t *testing.T) {
 		k8sGetGatewayCall  bool
 		k8sGatewayReturnOK bool
 		tlsTerminate       bool
+		tlsPassthrough     bool
+		routeType          string
 		noTLSOption        bool
 		wrongTLSOption     bool
 		certARN            string
+		// set when the route has no catch-all rule
+		expectFixedResponse int64
 	}{
 		{
 			name:               "listener, default service action",
@@ -225,6 +232,46 @@ func Test_ListenerModelBuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:                "listener, no catch-all rule, fixed response default action",
+			gwListenerPort:      *PortNumberPtr(80),
+			wantErrIsNil:        true,
+			k8sGetGatewayCall:   true,
+			k8sGatewayReturnOK:  true,
+			expectFixedResponse: 404,
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							Matches: []gateway_api.HTTPRouteMatch{
+								{
+									Path: &gateway_api.HTTPPathMatch{
+										Value: &path,
+									},
+								},
+							},
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:              "no parentref ",
 			gwListenerPort:    *PortNumberPtr(80),
@@ -283,6 +330,110 @@ func Test_ListenerModelBuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:               "listener, tls passthrough for tlsroute",
+			gwListenerPort:     *PortNumberPtr(443),
+			gwListenerProtocol: gateway_api.TLSProtocolType,
+			wantErrIsNil:       true,
+			k8sGetGatewayCall:  true,
+			k8sGatewayReturnOK: true,
+			tlsPassthrough:     true,
+			routeType:          latticemodel.K8STLSRouteType,
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:               "listener, https passthrough is not supported",
+			gwListenerPort:     *PortNumberPtr(443),
+			gwListenerProtocol: gateway_api.HTTPSProtocolType,
+			wantErrIsNil:       false,
+			k8sGetGatewayCall:  true,
+			k8sGatewayReturnOK: true,
+			tlsPassthrough:     true,
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:               "listener, tls terminate is not supported for tlsroute",
+			gwListenerPort:     *PortNumberPtr(443),
+			gwListenerProtocol: gateway_api.TLSProtocolType,
+			wantErrIsNil:       false,
+			k8sGetGatewayCall:  true,
+			k8sGatewayReturnOK: true,
+			tlsTerminate:       true,
+			routeType:          latticemodel.K8STLSRouteType,
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -299,12 +450,23 @@ func Test_ListenerModelBuild(t *testing.T) {
 				func(ctx context.Context, gwName types.NamespacedName, gw *gateway_api.Gateway, arg3 ...interface{}) error {
 
 					if tt.k8sGatewayReturnOK {
+						protocol := gateway_api.HTTPProtocolType
+						if tt.gwListenerProtocol != "" {
+							protocol = tt.gwListenerProtocol
+						}
 						listener := gateway_api.Listener{
 							Port:     tt.gwListenerPort,
-							Protocol: "HTTP",
+							Protocol: protocol,
 							Name:     *tt.httpRoute.Spec.ParentRefs[0].SectionName,
 						}
 
+						if tt.tlsPassthrough {
+							mode := gateway_api.TLSModePassthrough
+							listener.TLS = &gateway_api.GatewayTLSConfig{
+								Mode: &mode,
+							}
+						}
+
 						if tt.tlsTerminate {
 							mode := gateway_api.TLSModeTerminate
 							var tlsConfig gateway_api.GatewayTLSConfig
@@ -346,6 +508,7 @@ func Test_ListenerModelBuild(t *testing.T) {
 
 		task := &latticeServiceModelBuildTask{
 			httpRoute:       tt.httpRoute,
+			routeType:       tt.routeType,
 			stack:           stack,
 			Client:          k8sClient,
 			listenerByResID: make(map[string]*latticemodel.Listener),
@@ -379,20 +542,31 @@ func Test_ListenerModelBuild(t *testing.T) {
 		assert.Equal(t, resListener[0].Spec.Port, int64(tt.gwListenerPort))
 		assert.Equal(t, resListener[0].Spec.Name, tt.httpRoute.ObjectMeta.Name)
 		assert.Equal(t, resListener[0].Spec.Namespace, tt.httpRoute.ObjectMeta.Namespace)
-		assert.Equal(t, resListener[0].Spec.Protocol, "HTTP")
-
-		assert.Equal(t, resListener[0].Spec.DefaultAction.BackendServiceName,
-			string(tt.httpRoute.Spec.Rules[0].BackendRefs[0].BackendRef.Name))
-		if ns := tt.httpRoute.Spec.Rules[0].BackendRefs[0].BackendRef.Namespace; ns != nil {
-			assert.Equal(t, resListener[0].Spec.DefaultAction.BackendServiceNamespace, *ns)
+		if tt.tlsPassthrough {
+			assert.Equal(t, resListener[0].Spec.Protocol, latticemodel.ListenerProtocolTLSPassthrough)
+			assert.Equal(t, resListener[0].Spec.TLSMode, latticemodel.ListenerTLSModePassthrough)
 		} else {
-			assert.Equal(t, resListener[0].Spec.DefaultAction.BackendServiceNamespace, tt.httpRoute.ObjectMeta.Namespace)
+			assert.Equal(t, resListener[0].Spec.Protocol, "HTTP")
 		}
 
-		if *tt.httpRoute.Spec.Rules[0].BackendRefs[0].Kind == "Service" {
-			assert.Equal(t, resListener[0].Spec.DefaultAction.Is_Import, false)
+		if tt.expectFixedResponse != 0 {
+			assert.Equal(t, tt.expectFixedResponse, resListener[0].Spec.DefaultAction.FixedResponseStatusCode)
+			assert.Empty(t, resListener[0].Spec.DefaultAction.TargetGroups)
 		} else {
-			assert.Equal(t, resListener[0].Spec.DefaultAction.Is_Import, true)
+			defaultTGs := resListener[0].Spec.DefaultAction.TargetGroups
+			assert.Equal(t, 1, len(defaultTGs))
+			assert.Equal(t, defaultTGs[0].Name, string(tt.httpRoute.Spec.Rules[0].BackendRefs[0].BackendRef.Name))
+			if ns := tt.httpRoute.Spec.Rules[0].BackendRefs[0].BackendRef.Namespace; ns != nil {
+				assert.Equal(t, defaultTGs[0].Namespace, string(*ns))
+			} else {
+				assert.Equal(t, defaultTGs[0].Namespace, tt.httpRoute.ObjectMeta.Namespace)
+			}
+
+			if *tt.httpRoute.Spec.Rules[0].BackendRefs[0].Kind == "Service" {
+				assert.Equal(t, defaultTGs[0].IsServiceImport, false)
+			} else {
+				assert.Equal(t, defaultTGs[0].IsServiceImport, true)
+			}
 		}
 
 		if tt.tlsTerminate && !tt.noTLSOption && !tt.wrongTLSOption {
@@ -403,3 +577,268 @@ func Test_ListenerModelBuild(t *testing.T) {
 
 	}
 }
+
+func Test_ListenerModelBuildMultipleParents(t *testing.T) {
+	var serviceKind gateway_api.Kind = "Service"
+	var httpSectionName gateway_api.SectionName = "http"
+	var httpsSectionName gateway_api.SectionName = "https"
+	var unknownSectionName gateway_api.SectionName = "unknown"
+	var tcpRouteKind = gateway_api.Kind("TCPRoute")
+	var infraNamespace = gateway_api.Namespace("infra")
+	var fromAll = gateway_api.NamespacesFromAll
+	var fromSelector = gateway_api.NamespacesFromSelector
+	var tlsTerminate = gateway_api.TLSModeTerminate
+	certTLS := func(certARN string) *gateway_api.GatewayTLSConfig {
+		return &gateway_api.GatewayTLSConfig{
+			Mode: &tlsTerminate,
+			Options: map[gateway_api.AnnotationKey]gateway_api.AnnotationValue{
+				awsCustomCertARN: gateway_api.AnnotationValue(certARN),
+			},
+		}
+	}
+
+	namespaceLabels := map[string]map[string]string{
+		"default": {"team": "a"},
+	}
+
+	gateways := map[string][]gateway_api.Listener{
+		"mesh1": {
+			{Name: httpSectionName, Port: 80, Protocol: gateway_api.HTTPProtocolType},
+		},
+		"mesh2": {
+			{Name: httpSectionName, Port: 80, Protocol: gateway_api.HTTPProtocolType},
+			{Name: httpsSectionName, Port: 443, Protocol: gateway_api.HTTPSProtocolType},
+		},
+		"mesh3": {
+			{
+				Name:     httpSectionName,
+				Port:     8080,
+				Protocol: gateway_api.HTTPProtocolType,
+				AllowedRoutes: &gateway_api.AllowedRoutes{
+					Kinds: []gateway_api.RouteGroupKind{{Kind: tcpRouteKind}},
+				},
+			},
+		},
+		"mesh4": {
+			{Name: "tls", Port: 8443, Protocol: gateway_api.TLSProtocolType},
+			{Name: httpSectionName, Port: 8085, Protocol: gateway_api.HTTPProtocolType},
+		},
+		"mesh5": {
+			{Name: httpsSectionName, Port: 80, Protocol: gateway_api.HTTPSProtocolType},
+		},
+		"cert-a": {
+			{Name: httpsSectionName, Port: 443, Protocol: gateway_api.HTTPSProtocolType, TLS: certTLS("cert-a")},
+		},
+		"cert-b": {
+			{Name: httpsSectionName, Port: 8443, Protocol: gateway_api.HTTPSProtocolType, TLS: certTLS("cert-b")},
+		},
+		// gateways in the infra namespace
+		"shared-all": {
+			{
+				Name:     httpSectionName,
+				Port:     8081,
+				Protocol: gateway_api.HTTPProtocolType,
+				AllowedRoutes: &gateway_api.AllowedRoutes{
+					Namespaces: &gateway_api.RouteNamespaces{From: &fromAll},
+				},
+			},
+		},
+		"shared-same": {
+			{Name: httpSectionName, Port: 8082, Protocol: gateway_api.HTTPProtocolType},
+		},
+		"shared-team-a": {
+			{
+				Name:     httpSectionName,
+				Port:     8083,
+				Protocol: gateway_api.HTTPProtocolType,
+				AllowedRoutes: &gateway_api.AllowedRoutes{
+					Namespaces: &gateway_api.RouteNamespaces{
+						From: &fromSelector,
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"team": "a"},
+						},
+					},
+				},
+			},
+		},
+		"shared-team-b": {
+			{
+				Name:     httpSectionName,
+				Port:     8084,
+				Protocol: gateway_api.HTTPProtocolType,
+				AllowedRoutes: &gateway_api.AllowedRoutes{
+					Namespaces: &gateway_api.RouteNamespaces{
+						From: &fromSelector,
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"team": "b"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		parentRefs      []gateway_api.ParentReference
+		expectListeners []string
+		expectCertARN   string
+		wantErr         bool
+	}{
+		{
+			name: "same port and protocol on two gateways is one listener",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "mesh1", SectionName: &httpSectionName},
+				{Name: "mesh2", SectionName: &httpSectionName},
+				{Name: "mesh2", SectionName: &httpsSectionName},
+			},
+			expectListeners: []string{"service1-default-80-HTTP", "service1-default-443-HTTPS"},
+		},
+		{
+			name: "unknown gateway, section and disallowed route kind are skipped",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "unknown-gateway", SectionName: &httpSectionName},
+				{Name: "mesh1", SectionName: &unknownSectionName},
+				{Name: "mesh3", SectionName: &httpSectionName},
+				{Name: "mesh2", SectionName: &httpsSectionName},
+			},
+			expectListeners: []string{"service1-default-443-HTTPS"},
+		},
+		{
+			name: "gateway in another namespace allows routes by namespace policy",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "shared-all", Namespace: &infraNamespace},
+				{Name: "shared-same", Namespace: &infraNamespace},
+				{Name: "shared-team-a", Namespace: &infraNamespace},
+				{Name: "shared-team-b", Namespace: &infraNamespace},
+			},
+			expectListeners: []string{"service1-default-8081-HTTP", "service1-default-8083-HTTP"},
+		},
+		{
+			name: "parentRef without sectionName attaches to every listener allowing the route",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "mesh2"},
+				{Name: "mesh3"},
+				{Name: "mesh4"},
+			},
+			expectListeners: []string{"service1-default-80-HTTP", "service1-default-443-HTTPS",
+				"service1-default-8085-HTTP"},
+		},
+		{
+			name: "Negative, no valid parent",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "unknown-gateway", SectionName: &httpSectionName},
+				{Name: "mesh3", SectionName: &httpSectionName},
+			},
+			wantErr: true,
+		},
+		{
+			name: "certificate of one parent is the certificate of the service",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "mesh2", SectionName: &httpsSectionName},
+				{Name: "cert-a", SectionName: &httpsSectionName},
+			},
+			expectListeners: []string{"service1-default-443-HTTPS"},
+			expectCertARN:   "cert-a",
+		},
+		{
+			name: "Negative, parents with different certificates",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "cert-a", SectionName: &httpsSectionName},
+				{Name: "cert-b", SectionName: &httpsSectionName},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative, same port with different protocols",
+			parentRefs: []gateway_api.ParentReference{
+				{Name: "mesh1", SectionName: &httpSectionName},
+				{Name: "mesh5", SectionName: &httpsSectionName},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sClient := mock_client.NewMockClient(c)
+			k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, name types.NamespacedName, obj client.Object, arg3 ...interface{}) error {
+					switch obj := obj.(type) {
+					case *gateway_api.Gateway:
+						listeners, ok := gateways[name.Name]
+						if !ok {
+							return errors.New("unknown k8s object")
+						}
+						obj.Name = name.Name
+						obj.Namespace = name.Namespace
+						obj.Spec.Listeners = listeners
+						return nil
+					case *corev1.Namespace:
+						obj.Name = name.Name
+						obj.Labels = namespaceLabels[name.Name]
+						return nil
+					}
+					return errors.New("unknown k8s object")
+				},
+			).AnyTimes()
+
+			httpRoute := &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: tt.parentRefs,
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name: "targetgroup1",
+											Kind: &serviceKind,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(httpRoute)))
+			task := &latticeServiceModelBuildTask{
+				httpRoute:       httpRoute,
+				stack:           stack,
+				Client:          k8sClient,
+				listenerByResID: make(map[string]*latticemodel.Listener),
+				Datastore:       latticestore.NewLatticeDataStore(),
+				latticeService:  &latticemodel.Service{},
+			}
+
+			err := task.buildListener(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var resListener []*latticemodel.Listener
+			stack.ListResources(&resListener)
+
+			var listenerIDs []string
+			for _, listener := range resListener {
+				listenerIDs = append(listenerIDs, listener.ID())
+			}
+			assert.ElementsMatch(t, tt.expectListeners, listenerIDs)
+			assert.Equal(t, tt.expectCertARN, task.latticeService.Spec.CustomerCertARN)
+		})
+	}
+}
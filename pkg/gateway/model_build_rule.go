@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 
@@ -11,6 +14,7 @@ import (
 
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 )
@@ -18,7 +22,7 @@ import (
 const (
 	resourceIDRuleConfig = "RuleConfig"
 	// error code
-	LATTICE_NO_SUPPORT_FOR_MULTIPLE_MATCHES = "LATTICE_NO_SUPPORT_FOR_MULTIPLE_MATCHES"
+	LATTICE_EXCEED_MAX_RULES = "LATTICE_EXCEED_MAX_RULES"
 
 	LATTICE_EXCEED_MAX_HEADER_MATCHES = "LATTICE_EXCEED_MAX_HEADER_MATCHES"
 
@@ -28,174 +32,443 @@ const (
 
 	LATTICE_UNSUPPORTED_PATH_MATCH_TYPE = "LATTICE_UNSUPPORTED_PATH_MATCH_TYPE"
 
+	LATTICE_UNSUPPORTED_FILTER = "LATTICE_UNSUPPORTED_FILTER"
+
+	LATTICE_INVALID_FIXED_RESPONSE = "LATTICE_INVALID_FIXED_RESPONSE"
+
 	LATTICE_MAX_HEADER_MATCHES = 5
+
+	// lattice rule priority is between 1 and 100
+	LATTICE_MAX_RULE_PRIORITY = 100
+
+	// ExtensionRef filter for a fixed response, the name of the reference is the status code, e.g.
+	//   extensionRef:
+	//     group: application-networking.k8s.aws
+	//     kind: FixedResponse
+	//     name: "503"
+	FixedResponseFilterGroup = "application-networking.k8s.aws"
+	FixedResponseFilterKind  = "FixedResponse"
+
+	// a rule without any backendRefs or fixed response filter
+	noBackendFixedResponseStatusCode = 404
+	// a rule whose backendRefs are all refused, e.g. cross-namespace references without a ReferenceGrant
+	invalidBackendFixedResponseStatusCode = 500
 )
 
+// httpRouteRuleMatch is one match of a HTTPRoute rule, each of them becomes a lattice rule
+type httpRouteRuleMatch struct {
+	rule  *gateway_api.HTTPRouteRule
+	match *gateway_api.HTTPRouteMatch
+}
+
 func (t *latticeServiceModelBuildTask) buildRules(ctx context.Context) error {
 
-	var ruleID = 1
-	for _, parentRef := range t.httpRoute.Spec.ParentRefs {
-		if parentRef.Name != t.httpRoute.Spec.ParentRefs[0].Name {
-			// when a service is associate to multiple service network(s), all listener config MUST be same
-			// so here we are only using the 1st gateway
-			glog.V(2).Infof("Ignore parentref of different gateway %v", parentRef.Name)
+	listeners, err := t.buildParentListeners(ctx)
+	if err != nil {
+		glog.V(2).Infof("Error on buildRules %v \n", err)
+		return err
+	}
 
-			continue
-		}
-		port, protocol, _, err := t.extractListnerInfo(ctx, parentRef)
+	for _, listener := range listeners {
+		port, protocol := listener.port, listener.protocol
+		// rule priorities are per lattice listener
+		var ruleID = 1
 
-		if err != nil {
-			glog.V(2).Infof("Error on buildRules %v \n", err)
-			return err
+		var ruleMatches []httpRouteRuleMatch
+		for i := range t.httpRoute.Spec.Rules {
+			httpRule := &t.httpRoute.Spec.Rules[i]
+			glog.V(6).Infof("Parsing http rule spec: %v\n", *httpRule)
+
+			if len(httpRule.Matches) == 0 {
+				glog.V(6).Infof("Continue next rule, no matches specified in current rule")
+				continue
+			}
+
+			// each match of the rule is fanned out into its own lattice rule
+			for j := range httpRule.Matches {
+				ruleMatches = append(ruleMatches, httpRouteRuleMatch{
+					rule:  httpRule,
+					match: &httpRule.Matches[j],
+				})
+			}
 		}
 
-		for _, httpRule := range t.httpRoute.Spec.Rules {
-			glog.V(6).Infof("Parsing http rule spec: %v\n", httpRule)
-			var ruleSpec latticemodel.RuleSpec
+		// lattice evaluates rules by priority, so order them by gateway api match precedence,
+		// ties keep the order they are listed in the route
+		sort.SliceStable(ruleMatches, func(i, j int) bool {
+			return hasHigherMatchPrecedence(ruleMatches[i].match, ruleMatches[j].match)
+		})
 
-			if len(httpRule.Matches) > 1 {
-				// only support 1 match today
-				glog.V(2).Infof("Do not support multiple matches: matches == %v ", len(httpRule.Matches))
-				return errors.New(LATTICE_NO_SUPPORT_FOR_MULTIPLE_MATCHES)
+		for _, ruleMatch := range ruleMatches {
+			ruleSpec, err := t.buildRuleSpec(ruleMatch.match)
+			if err != nil {
+				return err
 			}
 
-			if len(httpRule.Matches) == 0 {
-				glog.V(6).Infof("Continue next rule, no matches specified in current rule")
-				continue
+			ruleAction, err := t.buildRuleAction(ctx, ruleMatch.rule)
+			if err != nil {
+				return err
 			}
+			glog.V(6).Infof("Generated ruleSpec is: %v", ruleSpec)
 
-			// only support 1 match today
-			match := httpRule.Matches[0]
-
-			if match.Path != nil && match.Path.Type != nil {
-				glog.V(6).Infof("Examing pathmatch type %v value %v for for httproute %s namespace %s ",
-					*match.Path.Type, *match.Path.Value, t.httpRoute.Name, t.httpRoute.Namespace)
-
-				switch *match.Path.Type {
-				case gateway_api.PathMatchExact:
-					glog.V(6).Infof("Using PathMatchExact for httproute %s namespace %s ",
-						t.httpRoute.Name, t.httpRoute.Namespace)
-					ruleSpec.PathMatchExact = true
-
-				case gateway_api.PathMatchPathPrefix:
-					glog.V(6).Infof("Using PathMatchPathPrefix for httproute %s namespace %s ",
-						t.httpRoute.Name, t.httpRoute.Namespace)
-					ruleSpec.PathMatchPrefix = true
-				default:
-					glog.V(2).Infof("Unsupported path match type %v for httproute %s namespace %s",
-						*match.Path.Type, t.httpRoute.Name, t.httpRoute.Namespace)
-					return errors.New(LATTICE_UNSUPPORTED_PATH_MATCH_TYPE)
-				}
-				ruleSpec.PathMatchValue = *match.Path.Value
+			if ruleID > LATTICE_MAX_RULE_PRIORITY {
+				glog.V(2).Infof("Exceed max number of rules %v for httproute %s namespace %s",
+					LATTICE_MAX_RULE_PRIORITY, t.httpRoute.Name, t.httpRoute.Namespace)
+				return errors.New(LATTICE_EXCEED_MAX_RULES)
 			}
 
-			// header based match
-			// today, only support EXACT match
-			if match.Headers != nil {
-				if len(match.Headers) > LATTICE_MAX_HEADER_MATCHES {
-					return errors.New(LATTICE_EXCEED_MAX_HEADER_MATCHES)
-				}
+			ruleIDName := fmt.Sprintf("rule-%d-%d-%s", ruleID, port, strings.ToLower(protocol))
+			latticemodel.NewRule(t.stack, ruleIDName, t.httpRoute.Name, t.httpRoute.Namespace, port,
+				protocol, ruleAction, ruleSpec)
+			ruleID++
 
-				ruleSpec.NumOfHeaderMatches = len(match.Headers)
+		}
+	}
 
-				glog.V(6).Infof("Examing match.Headers %v for httproute %s namespace %s",
-					match.Headers, t.httpRoute.Name, t.httpRoute.Namespace)
+	return nil
+}
 
-				for i, header := range match.Headers {
-					glog.V(6).Infof("Examing match.Header: i = %d header.Type %v", i, *header.Type)
-					if header.Type != nil && gateway_api.HeaderMatchType(*header.Type) != gateway_api.HeaderMatchExact {
-						glog.V(2).Infof("Unsupported header matchtype %v for httproute %v namespace %s",
-							*header.Type, t.httpRoute.Name, t.httpRoute.Namespace)
-						return errors.New(LATTICE_UNSUPPORTED_HEADER_MATCH_TYPE)
-					}
+// hasHigherMatchPrecedence follows the gateway api HTTPRouteRule precedence: exact path, longest prefix path,
+// method, largest number of header matches and then largest number of query param matches
+func hasHigherMatchPrecedence(a *gateway_api.HTTPRouteMatch, b *gateway_api.HTTPRouteMatch) bool {
+	aExact, aPrefixLen := pathMatchPrecedence(a)
+	bExact, bPrefixLen := pathMatchPrecedence(b)
 
-					glog.V(6).Infof("Found HeaderExactMatch==%v for HTTPRoute %v, namespace %v",
-						header.Value, t.httpRoute.Name, t.httpRoute.Namespace)
+	if aExact != bExact {
+		return aExact
+	}
+	if aPrefixLen != bPrefixLen {
+		return aPrefixLen > bPrefixLen
+	}
+	if (a.Method != nil) != (b.Method != nil) {
+		return a.Method != nil
+	}
+	if len(a.Headers) != len(b.Headers) {
+		return len(a.Headers) > len(b.Headers)
+	}
+	return len(a.QueryParams) > len(b.QueryParams)
+}
 
-					matchType := vpclattice.HeaderMatchType{
-						Exact: aws.String(header.Value),
-					}
-					ruleSpec.MatchedHeaders[i].Match = &matchType
-					header_name := header.Name
+// pathMatchPrecedence returns whether the path match is exact and the length of its path,
+// a missing path, type or value defaults to PathPrefix "/" in the gateway api
+func pathMatchPrecedence(match *gateway_api.HTTPRouteMatch) (bool, int) {
+	path := "/"
+	if match.Path == nil {
+		return false, len(path)
+	}
+	if match.Path.Value != nil {
+		path = *match.Path.Value
+	}
+	if match.Path.Type != nil && *match.Path.Type == gateway_api.PathMatchExact {
+		return true, len(path)
+	}
+	return false, len(path)
+}
 
-					glog.V(6).Infof("Found matching i = %d header_name %v", i, &header_name)
+func (t *latticeServiceModelBuildTask) buildRuleSpec(match *gateway_api.HTTPRouteMatch) (latticemodel.RuleSpec, error) {
+	var ruleSpec latticemodel.RuleSpec
+
+	if match.Path != nil && match.Path.Type != nil {
+		glog.V(6).Infof("Examing pathmatch type %v value %v for for httproute %s namespace %s ",
+			*match.Path.Type, *match.Path.Value, t.httpRoute.Name, t.httpRoute.Namespace)
+
+		switch *match.Path.Type {
+		case gateway_api.PathMatchExact:
+			glog.V(6).Infof("Using PathMatchExact for httproute %s namespace %s ",
+				t.httpRoute.Name, t.httpRoute.Namespace)
+			ruleSpec.PathMatchExact = true
+
+		case gateway_api.PathMatchPathPrefix:
+			glog.V(6).Infof("Using PathMatchPathPrefix for httproute %s namespace %s ",
+				t.httpRoute.Name, t.httpRoute.Namespace)
+			ruleSpec.PathMatchPrefix = true
+		default:
+			glog.V(2).Infof("Unsupported path match type %v for httproute %s namespace %s",
+				*match.Path.Type, t.httpRoute.Name, t.httpRoute.Namespace)
+			return ruleSpec, errors.New(LATTICE_UNSUPPORTED_PATH_MATCH_TYPE)
+		}
+		ruleSpec.PathMatchValue = *match.Path.Value
+	}
 
-					ruleSpec.MatchedHeaders[i].Name = (*string)(&header_name)
-				}
+	// header based match
+	// Exact match, or RegularExpression match which can be translated into lattice exact/prefix/contains match
+	if match.Headers != nil {
+		if len(match.Headers) > LATTICE_MAX_HEADER_MATCHES {
+			return ruleSpec, errors.New(LATTICE_EXCEED_MAX_HEADER_MATCHES)
+		}
+
+		ruleSpec.NumOfHeaderMatches = len(match.Headers)
+
+		glog.V(6).Infof("Examing match.Headers %v for httproute %s namespace %s",
+			match.Headers, t.httpRoute.Name, t.httpRoute.Namespace)
+
+		for i, header := range match.Headers {
+			glog.V(6).Infof("Examing match.Header: i = %d header.Type %v", i, header.Type)
+
+			headerMatchType := gateway_api.HeaderMatchExact
+			if header.Type != nil {
+				headerMatchType = gateway_api.HeaderMatchType(*header.Type)
 			}
 
-			// controller do not support these match type today
-			if match.Method != nil || match.QueryParams != nil {
-				glog.V(2).Infof("Unsupported Match Method %v for httproute %v, namespace %v",
-					match.Method, t.httpRoute.Name, t.httpRoute.Namespace)
-				return errors.New(LATTICE_UNSUPPORTED_MATCH_TYPE)
+			switch headerMatchType {
+			case gateway_api.HeaderMatchExact:
+				glog.V(6).Infof("Found HeaderExactMatch==%v for HTTPRoute %v, namespace %v",
+					header.Value, t.httpRoute.Name, t.httpRoute.Namespace)
+
+				ruleSpec.MatchedHeaders[i].Match = &vpclattice.HeaderMatchType{
+					Exact: aws.String(header.Value),
+				}
+				ruleSpec.MatchedHeaders[i].CaseSensitive = aws.Bool(true)
+			case gateway_api.HeaderMatchRegularExpression:
+				matchType, caseSensitive, err := translateRegexHeaderMatch(header.Value)
+				if err != nil {
+					glog.V(2).Infof("Unsupported header regular expression %v for httproute %v namespace %s, err %v",
+						header.Value, t.httpRoute.Name, t.httpRoute.Namespace, err)
+					return ruleSpec, errors.New(LATTICE_UNSUPPORTED_HEADER_MATCH_TYPE)
+				}
+				glog.V(6).Infof("Translated header regular expression %v to %v for HTTPRoute %v, namespace %v",
+					header.Value, matchType, t.httpRoute.Name, t.httpRoute.Namespace)
+
+				ruleSpec.MatchedHeaders[i].Match = matchType
+				ruleSpec.MatchedHeaders[i].CaseSensitive = caseSensitive
+			default:
+				glog.V(2).Infof("Unsupported header matchtype %v for httproute %v namespace %s",
+					headerMatchType, t.httpRoute.Name, t.httpRoute.Namespace)
+				return ruleSpec, errors.New(LATTICE_UNSUPPORTED_HEADER_MATCH_TYPE)
 			}
-			glog.V(6).Infof("Generated ruleSpec is: %v", ruleSpec)
 
-			tgList := []*latticemodel.RuleTargetGroup{}
+			header_name := header.Name
 
-			for _, httpBackendRef := range httpRule.BackendRefs {
-				glog.V(6).Infof("buildRoutingPolicy - examing backendRef %v\n", httpBackendRef)
-				glog.V(6).Infof("backendref kind: %v\n", *httpBackendRef.BackendObjectReference.Kind)
+			glog.V(6).Infof("Found matching i = %d header_name %v", i, &header_name)
 
-				ruleTG := latticemodel.RuleTargetGroup{}
+			ruleSpec.MatchedHeaders[i].Name = (*string)(&header_name)
+		}
+	}
 
-				if string(*httpBackendRef.BackendObjectReference.Kind) == "Service" {
-					namespace := t.httpRoute.Namespace
-					if httpBackendRef.BackendObjectReference.Namespace != nil {
-						namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-					}
-					ruleTG.Name = string(httpBackendRef.BackendObjectReference.Name)
-					ruleTG.Namespace = namespace
-					ruleTG.RouteName = t.httpRoute.Name
-					ruleTG.IsServiceImport = false
-					if httpBackendRef.Weight != nil {
-						ruleTG.Weight = int64(*httpBackendRef.Weight)
-					}
+	// method based match
+	if match.Method != nil {
+		glog.V(6).Infof("Using Method %v for httproute %s namespace %s",
+			*match.Method, t.httpRoute.Name, t.httpRoute.Namespace)
+		ruleSpec.Method = string(*match.Method)
+	}
 
-				}
+	// lattice does not support query param match
+	if match.QueryParams != nil {
+		glog.V(2).Infof("Unsupported Match QueryParams %v for httproute %v, namespace %v",
+			match.QueryParams, t.httpRoute.Name, t.httpRoute.Namespace)
+		return ruleSpec, errors.New(LATTICE_UNSUPPORTED_MATCH_TYPE)
+	}
+
+	return ruleSpec, nil
+}
 
-				if string(*httpBackendRef.BackendObjectReference.Kind) == "ServiceImport" {
-					// TODO
-					glog.V(6).Infof("Handle ServiceImport Routing Policy\n")
-					/* I think this need to be done at policy manager API call
-					tg, err := t.Datastore.GetTargetGroup(string(httpBackendRef.BackendObjectReference.Name),
-						"default", true) // isServiceImport==true
-					if err != nil {
-						glog.V(6).Infof("ServiceImport %s Not found, continue \n",
-							string(httpBackendRef.BackendObjectReference.Name))
-						continue
-
-					}
-					*/
-					ruleTG.Name = string(httpBackendRef.BackendObjectReference.Name)
-					ruleTG.Namespace = t.httpRoute.Namespace
-					if httpBackendRef.BackendObjectReference.Namespace != nil {
-						ruleTG.Namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
-					}
-					// the routename for serviceimport is always ""
-					ruleTG.RouteName = ""
-					ruleTG.IsServiceImport = true
-
-					if httpBackendRef.Weight != nil {
-						ruleTG.Weight = int64(*httpBackendRef.Weight)
-					}
+// buildRuleAction forwards to the rule backendRefs, unless the rule has a fixed response filter
+// or no permitted backendRefs at all, in which case lattice responds with a fixed status code
+func (t *latticeServiceModelBuildTask) buildRuleAction(ctx context.Context, httpRule *gateway_api.HTTPRouteRule) (latticemodel.RuleAction, error) {
+	ruleAction := latticemodel.RuleAction{}
 
-				}
+	for _, filter := range httpRule.Filters {
+		statusCode, err := fixedResponseStatusCode(filter)
+		if err != nil {
+			glog.V(2).Infof("Unsupported filter %v for httproute %s namespace %s, err %v",
+				filter.Type, t.httpRoute.Name, t.httpRoute.Namespace, err)
+			return ruleAction, err
+		}
+		ruleAction.FixedResponseStatusCode = statusCode
+	}
 
-				tgList = append(tgList, &ruleTG)
+	for _, httpBackendRef := range httpRule.BackendRefs {
+		if len(httpBackendRef.Filters) != 0 {
+			glog.V(2).Infof("Unsupported backendRef filters %v for httproute %s namespace %s",
+				httpBackendRef.Filters, t.httpRoute.Name, t.httpRoute.Namespace)
+			return ruleAction, errors.New(LATTICE_UNSUPPORTED_FILTER)
+		}
+	}
+
+	if ruleAction.FixedResponseStatusCode != 0 {
+		glog.V(6).Infof("Fixed response %d for httproute %s namespace %s, ignoring backendRefs %v",
+			ruleAction.FixedResponseStatusCode, t.httpRoute.Name, t.httpRoute.Namespace, httpRule.BackendRefs)
+		return ruleAction, nil
+	}
+
+	if len(httpRule.BackendRefs) == 0 {
+		ruleAction.FixedResponseStatusCode = noBackendFixedResponseStatusCode
+		return ruleAction, nil
+	}
+
+	ruleAction.TargetGroups = t.buildRuleTargetGroups(ctx, httpRule)
+	if len(ruleAction.TargetGroups) == 0 {
+		ruleAction.FixedResponseStatusCode = invalidBackendFixedResponseStatusCode
+	}
+	return ruleAction, nil
+}
+
+// fixedResponseStatusCode returns the status code of a fixed response filter,
+// lattice has no equivalent of the other filters (redirect, rewrite, mirror, header modifiers)
+func fixedResponseStatusCode(filter gateway_api.HTTPRouteFilter) (int64, error) {
+	if filter.Type != gateway_api.HTTPRouteFilterExtensionRef || filter.ExtensionRef == nil ||
+		string(filter.ExtensionRef.Group) != FixedResponseFilterGroup ||
+		string(filter.ExtensionRef.Kind) != FixedResponseFilterKind {
+		return 0, errors.New(LATTICE_UNSUPPORTED_FILTER)
+	}
+
+	statusCode, err := strconv.ParseInt(string(filter.ExtensionRef.Name), 10, 64)
+	if err != nil || statusCode < 100 || statusCode > 599 {
+		return 0, errors.New(LATTICE_INVALID_FIXED_RESPONSE)
+	}
+
+	return statusCode, nil
+}
+
+func (t *latticeServiceModelBuildTask) buildRuleTargetGroups(ctx context.Context, httpRule *gateway_api.HTTPRouteRule) []*latticemodel.RuleTargetGroup {
+	tgList := []*latticemodel.RuleTargetGroup{}
+
+	for _, httpBackendRef := range httpRule.BackendRefs {
+		glog.V(6).Infof("buildRoutingPolicy - examing backendRef %v\n", httpBackendRef)
+		glog.V(6).Infof("backendref kind: %v\n", *httpBackendRef.BackendObjectReference.Kind)
+
+		if !t.isBackendRefPermitted(ctx, httpBackendRef.BackendObjectReference) {
+			glog.V(2).Infof("buildRoutingPolicy - ignore backendRef %v which is not permitted\n", httpBackendRef.Name)
+			continue
+		}
+
+		ruleTG := latticemodel.RuleTargetGroup{}
+
+		if k8s.IsLambdaTargetBackendRef(httpBackendRef.BackendObjectReference) {
+			ruleTG.Name = string(httpBackendRef.BackendObjectReference.Name)
+			ruleTG.Namespace = t.httpRoute.Namespace
+			if httpBackendRef.BackendObjectReference.Namespace != nil {
+				ruleTG.Namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
+			}
+			ruleTG.RouteName = t.httpRoute.Name
+			ruleTG.IsLambda = true
+			if httpBackendRef.Weight != nil {
+				ruleTG.Weight = int64(*httpBackendRef.Weight)
 			}
+		}
 
-			ruleIDName := fmt.Sprintf("rule-%d", ruleID)
-			ruleAction := latticemodel.RuleAction{
-				TargetGroups: tgList,
+		if string(*httpBackendRef.BackendObjectReference.Kind) == "Service" {
+			namespace := t.httpRoute.Namespace
+			if httpBackendRef.BackendObjectReference.Namespace != nil {
+				namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
+			}
+			ruleTG.Name = string(httpBackendRef.BackendObjectReference.Name)
+			ruleTG.Namespace = namespace
+			ruleTG.RouteName = t.httpRoute.Name
+			ruleTG.Port = backendRefServicePort(httpBackendRef.BackendRef)
+			ruleTG.IsServiceImport = false
+			if httpBackendRef.Weight != nil {
+				ruleTG.Weight = int64(*httpBackendRef.Weight)
+			}
+
+		}
+
+		if string(*httpBackendRef.BackendObjectReference.Kind) == "ServiceImport" {
+			// TODO
+			glog.V(6).Infof("Handle ServiceImport Routing Policy\n")
+			/* I think this need to be done at policy manager API call
+			tg, err := t.Datastore.GetTargetGroup(string(httpBackendRef.BackendObjectReference.Name),
+				"default", true) // isServiceImport==true
+			if err != nil {
+				glog.V(6).Infof("ServiceImport %s Not found, continue \n",
+					string(httpBackendRef.BackendObjectReference.Name))
+				continue
+
+			}
+			*/
+			ruleTG.Name = string(httpBackendRef.BackendObjectReference.Name)
+			ruleTG.Namespace = t.httpRoute.Namespace
+			if httpBackendRef.BackendObjectReference.Namespace != nil {
+				ruleTG.Namespace = string(*httpBackendRef.BackendObjectReference.Namespace)
+			}
+			// the routename for serviceimport is always ""
+			ruleTG.RouteName = ""
+			ruleTG.IsServiceImport = true
+
+			if httpBackendRef.Weight != nil {
+				ruleTG.Weight = int64(*httpBackendRef.Weight)
 			}
-			latticemodel.NewRule(t.stack, ruleIDName, t.httpRoute.Name, t.httpRoute.Namespace, port,
-				protocol, ruleAction, ruleSpec)
-			ruleID++
 
 		}
+
+		tgList = append(tgList, &ruleTG)
 	}
 
-	return nil
+	return tgList
+}
+
+// translateRegexHeaderMatch translates the constrained regular expressions which lattice can express,
+// an optional (?i) flag for case-insensitive match followed by
+//
+//	^literal$            exact match
+//	^literal, ^literal.* prefix match
+//	literal, .*literal.* contains match
+//
+// where literal has no regular expression meta characters other than escaped ones
+func translateRegexHeaderMatch(regex string) (*vpclattice.HeaderMatchType, *bool, error) {
+	caseSensitive := aws.Bool(true)
+
+	if strings.HasPrefix(regex, "(?i)") {
+		caseSensitive = aws.Bool(false)
+		regex = strings.TrimPrefix(regex, "(?i)")
+	}
+
+	anchoredStart := false
+	if strings.HasPrefix(regex, "^") {
+		anchoredStart = true
+		regex = strings.TrimPrefix(regex, "^")
+	}
+	if strings.HasPrefix(regex, ".*") {
+		anchoredStart = false
+		regex = strings.TrimPrefix(regex, ".*")
+	}
+
+	anchoredEnd := false
+	if strings.HasSuffix(regex, ".*") && !strings.HasSuffix(regex, "\\.*") {
+		regex = strings.TrimSuffix(regex, ".*")
+	} else if strings.HasSuffix(regex, "$") && !strings.HasSuffix(regex, "\\$") {
+		anchoredEnd = true
+		regex = strings.TrimSuffix(regex, "$")
+	}
+
+	literal, err := unescapeRegexLiteral(regex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if literal == "" {
+		return nil, nil, fmt.Errorf("empty header match value")
+	}
+
+	switch {
+	case anchoredStart && anchoredEnd:
+		return &vpclattice.HeaderMatchType{Exact: aws.String(literal)}, caseSensitive, nil
+	case anchoredStart:
+		return &vpclattice.HeaderMatchType{Prefix: aws.String(literal)}, caseSensitive, nil
+	case !anchoredEnd:
+		return &vpclattice.HeaderMatchType{Contains: aws.String(literal)}, caseSensitive, nil
+	default:
+		return nil, nil, fmt.Errorf("suffix match is not supported")
+	}
+}
+
+func unescapeRegexLiteral(regex string) (string, error) {
+	const metaChars = `\.+*?()|[]{}^$`
+
+	var literal strings.Builder
+	for i := 0; i < len(regex); i++ {
+		c := regex[i]
+		if c == '\\' {
+			if i+1 >= len(regex) || !strings.ContainsRune(metaChars, rune(regex[i+1])) {
+				return "", fmt.Errorf("unsupported escape in %s", regex)
+			}
+			i++
+			literal.WriteByte(regex[i])
+			continue
+		}
+		if strings.ContainsRune(metaChars, rune(c)) {
+			return "", fmt.Errorf("unsupported regular expression %s", regex)
+		}
+		literal.WriteByte(c)
+	}
+	return literal.String(), nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -314,6 +315,30 @@ func Test_RuleModelBuild(t *testing.T) {
 
 		k8sClient := mock_client.NewMockClient(c)
 
+		// cross namespace backendRefs are granted by a ReferenceGrant in the backend namespace
+		k8sClient.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, grants *gateway_api.ReferenceGrantList, arg3 ...interface{}) error {
+				grants.Items = append(grants.Items, gateway_api.ReferenceGrant{
+					Spec: gateway_api.ReferenceGrantSpec{
+						From: []gateway_api.ReferenceGrantFrom{
+							{
+								Group:     gateway_api.GroupName,
+								Kind:      "HTTPRoute",
+								Namespace: gateway_api.Namespace(tt.httpRoute.Namespace),
+							},
+						},
+						To: []gateway_api.ReferenceGrantTo{
+							{
+								Group: k8s.ServiceImportGroup,
+								Kind:  "ServiceImport",
+							},
+						},
+					},
+				})
+				return nil
+			},
+		).AnyTimes()
+
 		if tt.k8sGetGatewayCall {
 
 			k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -401,6 +426,7 @@ func Test_HeadersRuleBuild(t *testing.T) {
 	var k8sMethod = gateway_api.HTTPMethodGet
 
 	var k8sHeaderExactType = gateway_api.HeaderMatchExact
+	var k8sHeaderRegexType = gateway_api.HeaderMatchRegularExpression
 	var hdr1 = "env1"
 	var hdr1Value = "test1"
 	var hdr2 = "env2"
@@ -567,7 +593,8 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 
 					{},
@@ -631,12 +658,14 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr2Value},
-						Name: &hdr2,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr2,
 					},
 
 					{},
@@ -706,12 +735,14 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr2Value},
-						Name: &hdr2,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr2,
 					},
 
 					{},
@@ -781,12 +812,14 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr2Value},
-						Name: &hdr2,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr2,
 					},
 
 					{},
@@ -849,12 +882,14 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr2Value},
-						Name: &hdr2,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr2,
 					},
 
 					{},
@@ -944,12 +979,14 @@ func Test_HeadersRuleBuild(t *testing.T) {
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr1Value},
-						Name: &hdr1,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr1,
 					},
 					{
 						Match: &vpclattice.HeaderMatchType{
 							Exact: &hdr2Value},
-						Name: &hdr2,
+						CaseSensitive: aws.Bool(true),
+						Name:          &hdr2,
 					},
 
 					{},
@@ -959,9 +996,9 @@ func Test_HeadersRuleBuild(t *testing.T) {
 			},
 		},
 		{
-			name:           "Negative, multiple methods",
+			name:           "multiple matches, one lattice rule per match",
 			gwListenerPort: *PortNumberPtr(80),
-			wantErrIsNil:   true,
+			wantErrIsNil:   false,
 			samerule:       true,
 
 			httpRoute: &gateway_api.HTTPRoute{
@@ -1011,9 +1048,9 @@ func Test_HeadersRuleBuild(t *testing.T) {
 			},
 		},
 		{
-			name:           "Negative, reject method based",
+			name:           "method based",
 			gwListenerPort: *PortNumberPtr(80),
-			wantErrIsNil:   true,
+			wantErrIsNil:   false,
 			samerule:       true,
 
 			httpRoute: &gateway_api.HTTPRoute{
@@ -1049,8 +1086,151 @@ func Test_HeadersRuleBuild(t *testing.T) {
 				},
 			},
 			expectedRuleSpec: latticemodel.RuleSpec{
-				PathMatchExact: true,
-				PathMatchValue: path1,
+				Method: string(k8sMethod),
+			},
+		},
+		{
+			name:           "regular expression header match, case insensitive prefix",
+			gwListenerPort: *PortNumberPtr(80),
+			wantErrIsNil:   false,
+			samerule:       true,
+
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							Matches: []gateway_api.HTTPRouteMatch{
+								{
+									Headers: []gateway_api.HTTPHeaderMatch{
+										{
+											Type:  &k8sHeaderRegexType,
+											Name:  gateway_api.HTTPHeaderName(hdr1),
+											Value: "(?i)^test1.*",
+										},
+									},
+								},
+							},
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef1,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRuleSpec: latticemodel.RuleSpec{
+				NumOfHeaderMatches: 1,
+				MatchedHeaders: [5]vpclattice.HeaderMatch{
+					{
+						Match: &vpclattice.HeaderMatchType{
+							Prefix: &hdr1Value},
+						Name:          &hdr1,
+						CaseSensitive: aws.Bool(false),
+					},
+					{},
+					{},
+					{},
+					{},
+				},
+			},
+		},
+		{
+			name:           "Negative, reject untranslatable regular expression header match",
+			gwListenerPort: *PortNumberPtr(80),
+			wantErrIsNil:   true,
+			samerule:       true,
+
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							Matches: []gateway_api.HTTPRouteMatch{
+								{
+									Headers: []gateway_api.HTTPHeaderMatch{
+										{
+											Type:  &k8sHeaderRegexType,
+											Name:  gateway_api.HTTPHeaderName(hdr1),
+											Value: "^test[0-9]+$",
+										},
+									},
+								},
+							},
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef1,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "Negative, reject query param based",
+			gwListenerPort: *PortNumberPtr(80),
+			wantErrIsNil:   true,
+			samerule:       true,
+
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name:        "mesh1",
+								SectionName: &httpSectionName,
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							Matches: []gateway_api.HTTPRouteMatch{
+								{
+									QueryParams: []gateway_api.HTTPQueryParamMatch{
+										{
+											Name:  "version",
+											Value: "v1",
+										},
+									},
+								},
+							},
+
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: backendRef1,
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -1148,6 +1328,11 @@ func isRuleSpecSame(rule1 *latticemodel.RuleSpec, rule2 *latticemodel.RuleSpec)
 		}
 	}
 
+	// Method Match
+	if rule1.Method != rule2.Method {
+		return false
+	}
+
 	// Header Match
 	if rule1.NumOfHeaderMatches != rule2.NumOfHeaderMatches {
 		return false
@@ -1165,8 +1350,11 @@ func isRuleSpecSame(rule1 *latticemodel.RuleSpec, rule2 *latticemodel.RuleSpec)
 			rule2Hdr := rule2.MatchedHeaders[j]
 			// fmt.Printf("rule2 match: %v\n", rule2Hdr)
 
-			if *rule1Hdr.Match.Exact == *rule2Hdr.Match.Exact &&
-				*rule1Hdr.Name == *rule2Hdr.Name {
+			if aws.StringValue(rule1Hdr.Match.Exact) == aws.StringValue(rule2Hdr.Match.Exact) &&
+				aws.StringValue(rule1Hdr.Match.Prefix) == aws.StringValue(rule2Hdr.Match.Prefix) &&
+				aws.StringValue(rule1Hdr.Match.Contains) == aws.StringValue(rule2Hdr.Match.Contains) &&
+				aws.BoolValue(rule1Hdr.CaseSensitive) == aws.BoolValue(rule2Hdr.CaseSensitive) &&
+				aws.StringValue(rule1Hdr.Name) == aws.StringValue(rule2Hdr.Name) {
 				found = true
 				break
 			}
@@ -1178,3 +1366,634 @@ func isRuleSpecSame(rule1 *latticemodel.RuleSpec, rule2 *latticemodel.RuleSpec)
 	}
 	return true
 }
+
+func Test_RuleMatchPrecedence(t *testing.T) {
+	var httpSectionName gateway_api.SectionName = "http"
+	var serviceKind gateway_api.Kind = "Service"
+	var k8sPathMatchExactType = gateway_api.PathMatchExact
+	var k8sPathMatchPrefixType = gateway_api.PathMatchPathPrefix
+	var k8sHeaderExactType = gateway_api.HeaderMatchExact
+	var shortPrefix = "/"
+	var longPrefix = "/ver1"
+	var exactPath = "/ver1/api"
+
+	var backendRef1 = gateway_api.BackendRef{
+		BackendObjectReference: gateway_api.BackendObjectReference{
+			Name: "targetgroup1",
+			Kind: &serviceKind,
+		},
+	}
+	var backendRef2 = gateway_api.BackendRef{
+		BackendObjectReference: gateway_api.BackendObjectReference{
+			Name: "targetgroup2",
+			Kind: &serviceKind,
+		},
+	}
+
+	httpRoute := &gateway_api.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "service1",
+			Namespace: "default",
+		},
+		Spec: gateway_api.HTTPRouteSpec{
+			CommonRouteSpec: gateway_api.CommonRouteSpec{
+				ParentRefs: []gateway_api.ParentReference{
+					{
+						Name:        "mesh1",
+						SectionName: &httpSectionName,
+					},
+				},
+			},
+			Rules: []gateway_api.HTTPRouteRule{
+				{
+					Matches: []gateway_api.HTTPRouteMatch{
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &k8sPathMatchPrefixType,
+								Value: &shortPrefix,
+							},
+						},
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &k8sPathMatchPrefixType,
+								Value: &shortPrefix,
+							},
+							Headers: []gateway_api.HTTPHeaderMatch{
+								{
+									Type:  &k8sHeaderExactType,
+									Name:  "env",
+									Value: "test",
+								},
+							},
+						},
+					},
+					BackendRefs: []gateway_api.HTTPBackendRef{
+						{
+							BackendRef: backendRef1,
+						},
+					},
+				},
+				{
+					Matches: []gateway_api.HTTPRouteMatch{
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &k8sPathMatchPrefixType,
+								Value: &longPrefix,
+							},
+						},
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &k8sPathMatchExactType,
+								Value: &exactPath,
+							},
+						},
+					},
+					BackendRefs: []gateway_api.HTTPBackendRef{
+						{
+							BackendRef: backendRef2,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedRules := []struct {
+		pathExact    bool
+		pathValue    string
+		numOfHeaders int
+		tgName       string
+	}{
+		{pathExact: true, pathValue: exactPath, tgName: "targetgroup2"},
+		{pathExact: false, pathValue: longPrefix, tgName: "targetgroup2"},
+		{pathExact: false, pathValue: shortPrefix, numOfHeaders: 1, tgName: "targetgroup1"},
+		{pathExact: false, pathValue: shortPrefix, tgName: "targetgroup1"},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	k8sClient := mock_client.NewMockClient(c)
+	k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, gwName types.NamespacedName, gw *gateway_api.Gateway, arg3 ...interface{}) error {
+			gw.Spec.Listeners = append(gw.Spec.Listeners, gateway_api.Listener{
+				Port: *PortNumberPtr(80),
+				Name: httpSectionName,
+			})
+			return nil
+		},
+	)
+
+	stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(httpRoute)))
+
+	task := &latticeServiceModelBuildTask{
+		httpRoute:       httpRoute,
+		stack:           stack,
+		Client:          k8sClient,
+		listenerByResID: make(map[string]*latticemodel.Listener),
+		Datastore:       latticestore.NewLatticeDataStore(),
+	}
+
+	err := task.buildRules(ctx)
+	assert.NoError(t, err)
+
+	var resRules []*latticemodel.Rule
+	stack.ListResources(&resRules)
+	assert.Equal(t, len(expectedRules), len(resRules))
+
+	for _, resRule := range resRules {
+		var i int
+		fmt.Sscanf(resRule.Spec.RuleID, "rule-%d", &i)
+		expected := expectedRules[i-1]
+
+		assert.Equal(t, expected.pathExact, resRule.Spec.PathMatchExact)
+		assert.Equal(t, !expected.pathExact, resRule.Spec.PathMatchPrefix)
+		assert.Equal(t, expected.pathValue, resRule.Spec.PathMatchValue)
+		assert.Equal(t, expected.numOfHeaders, resRule.Spec.NumOfHeaderMatches)
+		assert.Equal(t, expected.tgName, resRule.Spec.Action.TargetGroups[0].Name)
+	}
+}
+
+func Test_hasHigherMatchPrecedence(t *testing.T) {
+	var k8sPathMatchPrefixType = gateway_api.PathMatchPathPrefix
+	var k8sPathMatchExactType = gateway_api.PathMatchExact
+	var rootPath = "/"
+	var longPath = "/ver1"
+	var getMethod = gateway_api.HTTPMethodGet
+
+	tests := []struct {
+		name string
+		a    gateway_api.HTTPRouteMatch
+		b    gateway_api.HTTPRouteMatch
+		want bool
+	}{
+		{
+			name: "longer prefix before a missing path",
+			a:    gateway_api.HTTPRouteMatch{Path: &gateway_api.HTTPPathMatch{Type: &k8sPathMatchPrefixType, Value: &longPath}},
+			b:    gateway_api.HTTPRouteMatch{},
+			want: true,
+		},
+		{
+			name: "missing path after a longer prefix",
+			a:    gateway_api.HTTPRouteMatch{},
+			b:    gateway_api.HTTPRouteMatch{Path: &gateway_api.HTTPPathMatch{Type: &k8sPathMatchPrefixType, Value: &longPath}},
+			want: false,
+		},
+		{
+			name: "exact root path before a missing path",
+			a:    gateway_api.HTTPRouteMatch{Path: &gateway_api.HTTPPathMatch{Type: &k8sPathMatchExactType, Value: &rootPath}},
+			b:    gateway_api.HTTPRouteMatch{},
+			want: true,
+		},
+		{
+			name: "missing path ties with root prefix, method decides",
+			a:    gateway_api.HTTPRouteMatch{Method: &getMethod},
+			b:    gateway_api.HTTPRouteMatch{Path: &gateway_api.HTTPPathMatch{Type: &k8sPathMatchPrefixType, Value: &rootPath}},
+			want: true,
+		},
+		{
+			name: "root prefix ties with missing path, method decides",
+			a:    gateway_api.HTTPRouteMatch{Path: &gateway_api.HTTPPathMatch{Type: &k8sPathMatchPrefixType, Value: &rootPath}},
+			b:    gateway_api.HTTPRouteMatch{Method: &getMethod},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasHigherMatchPrecedence(&tt.a, &tt.b))
+		})
+	}
+}
+
+func Test_TranslateRegexHeaderMatch(t *testing.T) {
+	tests := []struct {
+		name              string
+		regex             string
+		wantMatch         *vpclattice.HeaderMatchType
+		wantCaseSensitive *bool
+		wantErr           bool
+	}{
+		{
+			name:              "anchored both ends is exact",
+			regex:             "^test$",
+			wantMatch:         &vpclattice.HeaderMatchType{Exact: aws.String("test")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:              "anchored start is prefix",
+			regex:             "^test",
+			wantMatch:         &vpclattice.HeaderMatchType{Prefix: aws.String("test")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:              "anchored start with trailing wildcard is prefix",
+			regex:             "^test.*",
+			wantMatch:         &vpclattice.HeaderMatchType{Prefix: aws.String("test")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:              "unanchored is contains",
+			regex:             "test",
+			wantMatch:         &vpclattice.HeaderMatchType{Contains: aws.String("test")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:              "wildcards on both ends is contains",
+			regex:             ".*test.*",
+			wantMatch:         &vpclattice.HeaderMatchType{Contains: aws.String("test")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:              "case insensitive exact",
+			regex:             "(?i)^test$",
+			wantMatch:         &vpclattice.HeaderMatchType{Exact: aws.String("test")},
+			wantCaseSensitive: aws.Bool(false),
+		},
+		{
+			name:              "escaped meta characters are literal",
+			regex:             `^v1\.2\.3$`,
+			wantMatch:         &vpclattice.HeaderMatchType{Exact: aws.String("v1.2.3")},
+			wantCaseSensitive: aws.Bool(true),
+		},
+		{
+			name:    "suffix match is not supported",
+			regex:   "test$",
+			wantErr: true,
+		},
+		{
+			name:    "character class is not supported",
+			regex:   "^test[0-9]",
+			wantErr: true,
+		},
+		{
+			name:    "alternation is not supported",
+			regex:   "a|b",
+			wantErr: true,
+		},
+		{
+			name:    "empty literal is not supported",
+			regex:   "^.*",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, caseSensitive, err := translateRegexHeaderMatch(tt.regex)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMatch, match)
+			assert.Equal(t, tt.wantCaseSensitive, caseSensitive)
+		})
+	}
+}
+
+func Test_RuleActionBuild(t *testing.T) {
+	var serviceKind gateway_api.Kind = "Service"
+	var namespace = gateway_api.Namespace("default")
+	var weight = int32(10)
+
+	var backendRef1 = gateway_api.HTTPBackendRef{
+		BackendRef: gateway_api.BackendRef{
+			BackendObjectReference: gateway_api.BackendObjectReference{
+				Name:      "targetgroup1",
+				Namespace: &namespace,
+				Kind:      &serviceKind,
+			},
+			Weight: &weight,
+		},
+	}
+
+	var otherNamespace = gateway_api.Namespace("other")
+	var backendRefOtherNamespace = gateway_api.HTTPBackendRef{
+		BackendRef: gateway_api.BackendRef{
+			BackendObjectReference: gateway_api.BackendObjectReference{
+				Name:      "targetgroup2",
+				Namespace: &otherNamespace,
+				Kind:      &serviceKind,
+			},
+			Weight: &weight,
+		},
+	}
+
+	var referenceGrant = gateway_api.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grant",
+			Namespace: "other",
+		},
+		Spec: gateway_api.ReferenceGrantSpec{
+			From: []gateway_api.ReferenceGrantFrom{
+				{
+					Group:     gateway_api.GroupName,
+					Kind:      "HTTPRoute",
+					Namespace: "default",
+				},
+			},
+			To: []gateway_api.ReferenceGrantTo{
+				{
+					Kind: "Service",
+				},
+			},
+		},
+	}
+
+	fixedResponseFilter := func(name string) gateway_api.HTTPRouteFilter {
+		return gateway_api.HTTPRouteFilter{
+			Type: gateway_api.HTTPRouteFilterExtensionRef,
+			ExtensionRef: &gateway_api.LocalObjectReference{
+				Group: FixedResponseFilterGroup,
+				Kind:  FixedResponseFilterKind,
+				Name:  gateway_api.ObjectName(name),
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		rule            gateway_api.HTTPRouteRule
+		referenceGrants []gateway_api.ReferenceGrant
+		expectAction    latticemodel.RuleAction
+		wantErr         error
+	}{
+		{
+			name: "forward to backendRefs",
+			rule: gateway_api.HTTPRouteRule{
+				BackendRefs: []gateway_api.HTTPBackendRef{backendRef1},
+			},
+			expectAction: latticemodel.RuleAction{
+				TargetGroups: []*latticemodel.RuleTargetGroup{
+					{
+						Name:      "targetgroup1",
+						Namespace: "default",
+						RouteName: "service1",
+						Weight:    10,
+					},
+				},
+			},
+		},
+		{
+			name: "forward to cross namespace backendRef permitted by ReferenceGrant",
+			rule: gateway_api.HTTPRouteRule{
+				BackendRefs: []gateway_api.HTTPBackendRef{backendRef1, backendRefOtherNamespace},
+			},
+			referenceGrants: []gateway_api.ReferenceGrant{referenceGrant},
+			expectAction: latticemodel.RuleAction{
+				TargetGroups: []*latticemodel.RuleTargetGroup{
+					{
+						Name:      "targetgroup1",
+						Namespace: "default",
+						RouteName: "service1",
+						Weight:    10,
+					},
+					{
+						Name:      "targetgroup2",
+						Namespace: "other",
+						RouteName: "service1",
+						Weight:    10,
+					},
+				},
+			},
+		},
+		{
+			name: "cross namespace backendRef without ReferenceGrant is ignored",
+			rule: gateway_api.HTTPRouteRule{
+				BackendRefs: []gateway_api.HTTPBackendRef{backendRef1, backendRefOtherNamespace},
+			},
+			expectAction: latticemodel.RuleAction{
+				TargetGroups: []*latticemodel.RuleTargetGroup{
+					{
+						Name:      "targetgroup1",
+						Namespace: "default",
+						RouteName: "service1",
+						Weight:    10,
+					},
+				},
+			},
+		},
+		{
+			name: "no permitted backendRefs, fixed 500 response",
+			rule: gateway_api.HTTPRouteRule{
+				BackendRefs: []gateway_api.HTTPBackendRef{backendRefOtherNamespace},
+			},
+			expectAction: latticemodel.RuleAction{
+				TargetGroups:            []*latticemodel.RuleTargetGroup{},
+				FixedResponseStatusCode: 500,
+			},
+		},
+		{
+			name: "no backendRefs, fixed 404 response",
+			rule: gateway_api.HTTPRouteRule{},
+			expectAction: latticemodel.RuleAction{
+				FixedResponseStatusCode: 404,
+			},
+		},
+		{
+			name: "fixed response filter overrides backendRefs",
+			rule: gateway_api.HTTPRouteRule{
+				Filters:     []gateway_api.HTTPRouteFilter{fixedResponseFilter("503")},
+				BackendRefs: []gateway_api.HTTPBackendRef{backendRef1},
+			},
+			expectAction: latticemodel.RuleAction{
+				FixedResponseStatusCode: 503,
+			},
+		},
+		{
+			name: "Negative, invalid fixed response status code",
+			rule: gateway_api.HTTPRouteRule{
+				Filters: []gateway_api.HTTPRouteFilter{fixedResponseFilter("maintenance")},
+			},
+			wantErr: errors.New(LATTICE_INVALID_FIXED_RESPONSE),
+		},
+		{
+			name: "Negative, unsupported request redirect filter",
+			rule: gateway_api.HTTPRouteRule{
+				Filters: []gateway_api.HTTPRouteFilter{
+					{
+						Type:            gateway_api.HTTPRouteFilterRequestRedirect,
+						RequestRedirect: &gateway_api.HTTPRequestRedirectFilter{},
+					},
+				},
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_FILTER),
+		},
+		{
+			name: "Negative, unsupported extensionRef filter",
+			rule: gateway_api.HTTPRouteRule{
+				Filters: []gateway_api.HTTPRouteFilter{
+					{
+						Type: gateway_api.HTTPRouteFilterExtensionRef,
+						ExtensionRef: &gateway_api.LocalObjectReference{
+							Group: "example.com",
+							Kind:  "Rewrite",
+							Name:  "rewrite",
+						},
+					},
+				},
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_FILTER),
+		},
+		{
+			name: "Negative, unsupported backendRef filter",
+			rule: gateway_api.HTTPRouteRule{
+				BackendRefs: []gateway_api.HTTPBackendRef{
+					{
+						BackendRef: backendRef1.BackendRef,
+						Filters: []gateway_api.HTTPRouteFilter{
+							{
+								Type: gateway_api.HTTPRouteFilterRequestHeaderModifier,
+							},
+						},
+					},
+				},
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_FILTER),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			ctx := context.TODO()
+
+			k8sClient := mock_client.NewMockClient(c)
+			k8sClient.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, grants *gateway_api.ReferenceGrantList, arg3 ...interface{}) error {
+					grants.Items = tt.referenceGrants
+					return nil
+				},
+			).AnyTimes()
+
+			task := &latticeServiceModelBuildTask{
+				httpRoute: &gateway_api.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "service1",
+						Namespace: "default",
+					},
+				},
+				Client: k8sClient,
+			}
+
+			action, err := task.buildRuleAction(ctx, &tt.rule)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectAction, action)
+		})
+	}
+}
+
+func Test_RulePriorityPerListener(t *testing.T) {
+	var httpSectionName gateway_api.SectionName = "http"
+	var httpsSectionName gateway_api.SectionName = "https"
+	var serviceKind gateway_api.Kind = "Service"
+	var path1 = "/ver1"
+	var path2 = "/ver2"
+	var pathMatchPrefixType = gateway_api.PathMatchPathPrefix
+
+	httpRoute := &gateway_api.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "service1",
+			Namespace: "default",
+		},
+		Spec: gateway_api.HTTPRouteSpec{
+			CommonRouteSpec: gateway_api.CommonRouteSpec{
+				ParentRefs: []gateway_api.ParentReference{
+					{
+						Name:        "gateway1",
+						SectionName: &httpSectionName,
+					},
+					{
+						Name:        "gateway1",
+						SectionName: &httpsSectionName,
+					},
+				},
+			},
+			Rules: []gateway_api.HTTPRouteRule{
+				{
+					Matches: []gateway_api.HTTPRouteMatch{
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &pathMatchPrefixType,
+								Value: &path1,
+							},
+						},
+						{
+							Path: &gateway_api.HTTPPathMatch{
+								Type:  &pathMatchPrefixType,
+								Value: &path2,
+							},
+						},
+					},
+					BackendRefs: []gateway_api.HTTPBackendRef{
+						{
+							BackendRef: gateway_api.BackendRef{
+								BackendObjectReference: gateway_api.BackendObjectReference{
+									Name: "targetgroup1",
+									Kind: &serviceKind,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	ctx := context.TODO()
+
+	k8sClient := mock_client.NewMockClient(c)
+	k8sClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, gwName types.NamespacedName, gw *gateway_api.Gateway, arg3 ...interface{}) error {
+			gw.Spec.Listeners = []gateway_api.Listener{
+				{
+					Name:     httpSectionName,
+					Port:     *PortNumberPtr(80),
+					Protocol: gateway_api.HTTPProtocolType,
+				},
+				{
+					Name:     httpsSectionName,
+					Port:     *PortNumberPtr(443),
+					Protocol: gateway_api.HTTPSProtocolType,
+				},
+			}
+			return nil
+		},
+	).AnyTimes()
+
+	stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(httpRoute)))
+
+	task := &latticeServiceModelBuildTask{
+		httpRoute:       httpRoute,
+		stack:           stack,
+		Client:          k8sClient,
+		listenerByResID: make(map[string]*latticemodel.Listener),
+		Datastore:       latticestore.NewLatticeDataStore(),
+	}
+
+	err := task.buildRules(ctx)
+	assert.NoError(t, err)
+
+	var resRules []*latticemodel.Rule
+	stack.ListResources(&resRules)
+
+	ruleIDs := make(map[string]bool)
+	for _, resRule := range resRules {
+		ruleIDs[resRule.Spec.RuleID] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"rule-1-80-http":   true,
+		"rule-2-80-http":   true,
+		"rule-1-443-https": true,
+		"rule-2-443-https": true,
+	}, ruleIDs)
+}
@@ -2,6 +2,8 @@ package gateway
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/golang/glog"
 
@@ -12,17 +14,22 @@ import (
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
 	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
 	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/vpclattice"
 )
 
 const (
 	resourceIDTargetGroup = "TargetGroup"
+
+	// lambda target groups only take HTTP requests
+	LATTICE_UNSUPPORTED_LAMBDA_BACKEND = "LATTICE_UNSUPPORTED_LAMBDA_BACKEND"
 )
 
 type TargetGroupModelBuilder interface {
@@ -141,16 +148,34 @@ func (t *latticeServiceModelBuildTask) buildTargets(ctx context.Context) error {
 				continue
 			}
 
+			if !t.isBackendRefPermitted(ctx, httpBackendRef.BackendObjectReference) {
+				glog.V(6).Infof("latticeServiceModelBuildTask: ignore not permitted service: %v \n", httpBackendRef)
+				continue
+			}
+
 			backendNamespace := t.httpRoute.Namespace
 			if httpBackendRef.Namespace != nil {
 				backendNamespace = string(*httpBackendRef.Namespace)
 			}
 
+			if k8s.IsLambdaTargetBackendRef(httpBackendRef.BackendObjectReference) {
+				lambdaTargetName := types.NamespacedName{
+					Namespace: backendNamespace,
+					Name:      string(httpBackendRef.Name),
+				}
+				if err := t.buildLambdaTargets(ctx, lambdaTargetName); err != nil {
+					glog.V(6).Infof("Error buildTargets for backend ref lambda target %v \n", httpBackendRef)
+					return err
+				}
+				continue
+			}
+
 			targetTask := &latticeTargetsModelBuildTask{
 				Client:      t.Client,
 				tgName:      string(httpBackendRef.Name),
 				tgNamespace: backendNamespace,
 				routename:   t.httpRoute.Name,
+				port:        backendRefServicePort(httpBackendRef.BackendRef),
 				stack:       t.stack,
 				datastore:   t.Datastore,
 			}
@@ -166,6 +191,33 @@ func (t *latticeServiceModelBuildTask) buildTargets(ctx context.Context) error {
 	return nil
 }
 
+// buildLambdaTargets registers the function of the LambdaTarget to its target group. When the route is
+// deleted the target group is deleted along with its target
+func (t *latticeServiceModelBuildTask) buildLambdaTargets(ctx context.Context, lambdaTargetName types.NamespacedName) error {
+	if !t.httpRoute.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	lambdaTarget := &anv1alpha1.LambdaTarget{}
+	if err := t.Client.Get(ctx, lambdaTargetName, lambdaTarget); err != nil {
+		glog.V(6).Infof("Error finding LambdaTarget %v error :%v \n", lambdaTargetName, err)
+		return err
+	}
+
+	tgName := latticestore.LambdaTargetGroupName(lambdaTargetName.Name, lambdaTargetName.Namespace)
+	targetsSpec := latticemodel.TargetsSpec{
+		Name:      lambdaTargetName.Name,
+		Namespace: lambdaTargetName.Namespace,
+		RouteName: t.httpRoute.Name,
+		IsLambda:  true,
+		TargetIPList: []latticemodel.Target{
+			{TargetIP: lambdaTarget.Spec.FunctionARN},
+		},
+	}
+	latticemodel.NewTargets(t.stack, tgName, targetsSpec)
+	return nil
+}
+
 // TODO have a same BuildTargetGroup for both targetGroupModelBuildTask, latticeServiceModelBuildTask
 // Build target group for K8S serviceexport object
 func (t *targetGroupModelBuildTask) BuildTargetGroup(ctx context.Context) error {
@@ -193,9 +245,19 @@ func (t *targetGroupModelBuildTask) BuildTargetGroup(ctx context.Context) error
 			K8SServiceName:      t.serviceExport.Name,
 			K8SServiceNamespace: t.serviceExport.Namespace,
 			Protocol:            "HTTP",
-			ProtocolVersion:     vpclattice.TargetGroupProtocolVersionHttp1,
+			ProtocolVersion:     buildTargetGroupProtocolVersion(svc, nil),
 		},
 	}
+	for _, port := range svc.Spec.Ports {
+		tgSpec.Config.K8SServiceExportPorts = append(tgSpec.Config.K8SServiceExportPorts, port.Port)
+	}
+	weight, err := k8s.ServiceExportWeight(t.serviceExport)
+	if err != nil {
+		// an invalid weight is ignored rather than failing the export, the cluster gets the default weight
+		glog.V(2).Infof("Ignore weight of serviceexport %v, %v\n", k8s.NamespacedName(t.serviceExport), err)
+	}
+	tgSpec.Config.K8SServiceExportWeight = weight
+	applyTargetGroupPolicy(ctx, t.Client, k8s.NamespacedName(svc), "", &tgSpec.Config)
 
 	tg := latticemodel.NewTargetGroup(t.stack, tgName, tgSpec)
 	glog.V(6).Infof("buildTargetGroup, tg[%s], tgSpec%v \n", tgName, tg)
@@ -236,6 +298,11 @@ func (t *latticeServiceModelBuildTask) buildTargetGroup(ctx context.Context, cli
 		for _, httpBackendRef := range httpRule.BackendRefs {
 			glog.V(6).Infof("buildTargetGroup -- backendRef %v \n", httpBackendRef)
 
+			if !t.isBackendRefPermitted(ctx, httpBackendRef.BackendObjectReference) {
+				glog.V(2).Infof("buildTargetGroup, ignore backendRef %v which is not permitted\n", httpBackendRef.Name)
+				continue
+			}
+
 			tgName := t.buildHTTPTargetGroupName(ctx, &httpBackendRef)
 
 			tgSpec, err := t.buildHTTPTargetGroupSpec(ctx, client, &httpBackendRef)
@@ -245,7 +312,7 @@ func (t *latticeServiceModelBuildTask) buildTargetGroup(ctx context.Context, cli
 			}
 
 			// add targetgroup to localcache for service reconcile to reference
-			if *httpBackendRef.Kind == "Service" {
+			if *httpBackendRef.Kind == "Service" || tgSpec.Type == latticemodel.TargetGroupTypeLambda {
 				t.Datastore.AddTargetGroup(tgName, "", "", "", tgSpec.Config.IsServiceImport, t.httpRoute.Name)
 			} else {
 				t.Datastore.AddTargetGroup(tgName, "", "", "", tgSpec.Config.IsServiceImport, "")
@@ -283,9 +350,14 @@ func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupSpec(ctx context.Cont
 	backendKind := string(*httpBackendRef.BackendRef.BackendObjectReference.Kind)
 	glog.V(6).Infof("buildHTTPTargetGroupSpec,  kind %s\n", backendKind)
 
+	if k8s.IsLambdaTargetBackendRef(httpBackendRef.BackendObjectReference) {
+		return t.buildLambdaTargetGroupSpec(httpBackendRef, namespace)
+	}
+
 	var vpc = config.VpcID
 	var ekscluster = ""
 	var isServiceImport bool
+	var svc *corev1.Service
 
 	if backendKind == "ServiceImport" {
 		namespaceName := types.NamespacedName{
@@ -296,7 +368,7 @@ func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupSpec(ctx context.Cont
 
 		if err := client.Get(context.TODO(), namespaceName, serviceImport); err == nil {
 			glog.V(6).Infof("buildHTTPTargetGroupSpec, using service Import %v\n", namespaceName)
-			vpc = serviceImport.Annotations["multicluster.x-k8s.io/aws-vpc"]
+			vpc = serviceImport.Annotations[k8s.ServiceImportVpcAnnotation]
 			ekscluster = serviceImport.Annotations["multicluster.x-k8s.io/aws-eks-cluster-name"]
 			isServiceImport = true
 
@@ -317,14 +389,14 @@ func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupSpec(ctx context.Cont
 			Name:      string(httpBackendRef.Name),
 		}
 
-		svc := &corev1.Service{}
+		svc = &corev1.Service{}
 		if err := t.Client.Get(ctx, serviceNamespaceName, svc); err != nil {
 			glog.V(6).Infof("Error finding backend service %v error :%v \n", serviceNamespaceName, err)
 			return latticemodel.TargetGroupSpec{}, err
 		}
 	}
 
-	tgName := latticestore.TargetGroupName(string(httpBackendRef.Name), namespace)
+	tgName := t.buildHTTPTargetGroupName(ctx, httpBackendRef)
 
 	var isDeleted bool
 
@@ -334,7 +406,22 @@ func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupSpec(ctx context.Cont
 		isDeleted = true
 	}
 
-	return latticemodel.TargetGroupSpec{
+	protocol := "HTTP"
+	protocolVersion := vpclattice.TargetGroupProtocolVersionHttp1
+	switch t.routeType {
+	case latticemodel.K8SGRPCRouteType:
+		protocolVersion = vpclattice.TargetGroupProtocolVersionGrpc
+	case latticemodel.K8STLSRouteType:
+		// passthrough connections are forwarded as is, TCP target groups have no protocol version
+		protocol = "TCP"
+		protocolVersion = ""
+	default:
+		if svc != nil {
+			protocolVersion = buildTargetGroupProtocolVersion(svc, httpBackendRef.Port)
+		}
+	}
+
+	tgSpec := latticemodel.TargetGroupSpec{
 		Name: tgName,
 		Type: latticemodel.TargetGroupTypeIP,
 		Config: latticemodel.TargetGroupConfig{
@@ -344,23 +431,141 @@ func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupSpec(ctx context.Cont
 			IsServiceExport:       false,
 			K8SServiceName:        string(httpBackendRef.Name),
 			K8SServiceNamespace:   namespace,
+			K8SServicePort:        backendRefServicePort(httpBackendRef.BackendRef),
 			K8SHTTPRouteName:      t.httpRoute.Name,
 			K8SHTTPRouteNamespace: t.httpRoute.Namespace,
-			Protocol:              "HTTP",
-			ProtocolVersion:       vpclattice.TargetGroupProtocolVersionHttp1,
+			K8SRouteType:          t.routeType,
+			Protocol:              protocol,
+			ProtocolVersion:       protocolVersion,
 			// Fill in default HTTP port as we are using target port anyway.
 			Port: 80,
 		},
 		IsDeleted: isDeleted,
+	}
+
+	if !isServiceImport {
+		applyTargetGroupPolicy(ctx, client, types.NamespacedName{Namespace: namespace, Name: string(httpBackendRef.Name)},
+			t.routeType, &tgSpec.Config)
+	}
+
+	return tgSpec, nil
+}
+
+// buildLambdaTargetGroupSpec builds the target group of a LambdaTarget backendRef. It has no vpc, port
+// or protocol, its only target is the function, see buildLambdaTargets
+func (t *latticeServiceModelBuildTask) buildLambdaTargetGroupSpec(httpBackendRef *gateway_api.HTTPBackendRef, namespace string) (latticemodel.TargetGroupSpec, error) {
+	if t.routeType == latticemodel.K8SGRPCRouteType || t.routeType == latticemodel.K8STLSRouteType {
+		glog.V(2).Infof("LambdaTarget backendRef %s is not supported by %s\n", httpBackendRef.Name, t.routeType)
+		return latticemodel.TargetGroupSpec{}, errors.New(LATTICE_UNSUPPORTED_LAMBDA_BACKEND)
+	}
+
+	return latticemodel.TargetGroupSpec{
+		Name: latticestore.LambdaTargetGroupName(string(httpBackendRef.Name), namespace),
+		Type: latticemodel.TargetGroupTypeLambda,
+		Config: latticemodel.TargetGroupConfig{
+			K8SServiceName:        string(httpBackendRef.Name),
+			K8SServiceNamespace:   namespace,
+			K8SHTTPRouteName:      t.httpRoute.Name,
+			K8SHTTPRouteNamespace: t.httpRoute.Namespace,
+			K8SRouteType:          t.routeType,
+		},
+		IsDeleted: !t.httpRoute.DeletionTimestamp.IsZero(),
 	}, nil
 }
 
+// buildTargetGroupProtocolVersion returns the protocol version matching the appProtocol of the service port,
+// HTTP1 by default. Without a port, the first port with a known appProtocol is used
+func buildTargetGroupProtocolVersion(svc *corev1.Service, port *gateway_api.PortNumber) string {
+	for _, svcPort := range svc.Spec.Ports {
+		if port != nil && svcPort.Port != int32(*port) {
+			continue
+		}
+		if svcPort.AppProtocol == nil {
+			continue
+		}
+
+		switch strings.ToLower(*svcPort.AppProtocol) {
+		case "http2", "kubernetes.io/h2c":
+			return vpclattice.TargetGroupProtocolVersionHttp2
+		case "grpc":
+			return vpclattice.TargetGroupProtocolVersionGrpc
+		}
+	}
+	return vpclattice.TargetGroupProtocolVersionHttp1
+}
+
+// applyTargetGroupPolicy applies the TargetGroupPolicy of the k8s service to the config of its target group.
+// The protocol version of GRPCRoute backends is always GRPC and TLSRoute backends are always TCP
+func applyTargetGroupPolicy(ctx context.Context, k8sClient client.Client, svcName types.NamespacedName,
+	routeType string, tgConfig *latticemodel.TargetGroupConfig) {
+	policy := k8s.GetTargetGroupPolicy(ctx, k8sClient, svcName)
+	if policy == nil {
+		return
+	}
+	glog.V(6).Infof("Applying TargetGroupPolicy %s-%s to target group of service %v\n",
+		policy.Name, policy.Namespace, svcName)
+
+	if routeType != latticemodel.K8STLSRouteType {
+		if policy.Spec.Protocol != nil {
+			tgConfig.Protocol = *policy.Spec.Protocol
+		}
+		if policy.Spec.ProtocolVersion != nil && routeType != latticemodel.K8SGRPCRouteType {
+			tgConfig.ProtocolVersion = *policy.Spec.ProtocolVersion
+		}
+	}
+
+	tgConfig.HealthCheckConfig = buildHealthCheckConfig(policy.Spec.HealthCheck)
+}
+
+func buildHealthCheckConfig(healthCheck *anv1alpha1.HealthCheckConfig) *vpclattice.HealthCheckConfig {
+	if healthCheck == nil {
+		return nil
+	}
+
+	sdkHealthCheck := &vpclattice.HealthCheckConfig{
+		Enabled:                    healthCheck.Enabled,
+		HealthCheckIntervalSeconds: healthCheck.IntervalSeconds,
+		HealthCheckTimeoutSeconds:  healthCheck.TimeoutSeconds,
+		HealthyThresholdCount:      healthCheck.HealthyThresholdCount,
+		UnhealthyThresholdCount:    healthCheck.UnhealthyThresholdCount,
+		Path:                       healthCheck.Path,
+		Port:                       healthCheck.Port,
+	}
+	if healthCheck.StatusMatch != nil {
+		sdkHealthCheck.Matcher = &vpclattice.Matcher{
+			HttpCode: healthCheck.StatusMatch,
+		}
+	}
+	if healthCheck.Protocol != nil {
+		sdkHealthCheck.Protocol = aws.String(string(*healthCheck.Protocol))
+	}
+	if healthCheck.ProtocolVersion != nil {
+		sdkHealthCheck.ProtocolVersion = aws.String(string(*healthCheck.ProtocolVersion))
+	}
+	return sdkHealthCheck
+}
+
 func (t *latticeServiceModelBuildTask) buildHTTPTargetGroupName(_ context.Context, httpBackendRef *gateway_api.HTTPBackendRef) string {
+	namespace := t.httpRoute.Namespace
 	if httpBackendRef.BackendRef.BackendObjectReference.Namespace != nil {
-		return latticestore.TargetGroupName(string(httpBackendRef.BackendRef.BackendObjectReference.Name),
-			string(*httpBackendRef.BackendRef.BackendObjectReference.Namespace))
-	} else {
-		return latticestore.TargetGroupName(string(httpBackendRef.BackendRef.BackendObjectReference.Name),
-			t.httpRoute.Namespace)
+		namespace = string(*httpBackendRef.BackendRef.BackendObjectReference.Namespace)
+	}
+	if k8s.IsLambdaTargetBackendRef(httpBackendRef.BackendObjectReference) {
+		return latticestore.LambdaTargetGroupName(string(httpBackendRef.BackendRef.BackendObjectReference.Name), namespace)
+	}
+	port := backendRefServicePort(httpBackendRef.BackendRef)
+	return latticestore.TargetGroupPortName(string(httpBackendRef.BackendRef.BackendObjectReference.Name), namespace, port)
+}
+
+// backendRefServicePort returns the service port of a Service backendRef, so that a service backs routes on
+// different ports with a target group per port. It is 0 for ServiceImport backendRefs, which target the
+// exported target group of the whole service
+func backendRefServicePort(backendRef gateway_api.BackendRef) int32 {
+	if backendRef.Kind != nil && string(*backendRef.Kind) != "Service" {
+		return 0
+	}
+	if backendRef.Port == nil {
+		return 0
 	}
+	return int32(*backendRef.Port)
 }
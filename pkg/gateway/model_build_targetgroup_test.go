@@ -6,34 +6,41 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
 	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	mock_client "github.com/aws/aws-application-networking-k8s/mocks/controller-runtime/client"
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+	"github.com/aws/aws-sdk-go/service/vpclattice"
 )
 
 func Test_TGModelByServicexportBuild(t *testing.T) {
 	now := metav1.Now()
 	tests := []struct {
-		name          string
-		svcExport     *mcs_api.ServiceExport
-		svc           *corev1.Service
-		endPoints     []corev1.Endpoints
-		wantErrIsNil  bool
-		wantIsDeleted bool
+		name           string
+		svcExport      *mcs_api.ServiceExport
+		svc            *corev1.Service
+		endpointSlices []discoveryv1.EndpointSlice
+		tgPolicies     []anv1alpha1.TargetGroupPolicy
+		wantErrIsNil   bool
+		wantIsDeleted  bool
+		wantConfig     *latticemodel.TargetGroupConfig
 	}{
 		{
 			name: "Adding ServieExport where service object exist",
@@ -54,12 +61,14 @@ func Test_TGModelByServicexportBuild(t *testing.T) {
 					},
 				},
 			},
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
 					},
+					AddressType: discoveryv1.AddressTypeIPv4,
 				},
 			},
 			wantErrIsNil:  true,
@@ -112,17 +121,218 @@ func Test_TGModelByServicexportBuild(t *testing.T) {
 					},
 				},
 			},
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export4",
+						Name:      "export4-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export4"},
 					},
+					AddressType: discoveryv1.AddressTypeIPv4,
 				},
 			},
 			wantErrIsNil:  true,
 			wantIsDeleted: true,
 		},
+		{
+			name: "Adding ServieExport where service port has appProtocol",
+			svcExport: &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export6",
+					Namespace: "ns1",
+				},
+			},
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export6",
+					Namespace: "ns1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:        8080,
+							AppProtocol: aws.String("grpc"),
+						},
+					},
+				},
+			},
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export6-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export6"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+				},
+			},
+			wantErrIsNil:  true,
+			wantIsDeleted: false,
+			wantConfig: &latticemodel.TargetGroupConfig{
+				Protocol:              vpclattice.TargetGroupProtocolHttp,
+				ProtocolVersion:       vpclattice.TargetGroupProtocolVersionGrpc,
+				K8SServiceExportPorts: []int32{8080},
+			},
+		},
+		{
+			name: "Adding ServieExport where service has TargetGroupPolicy",
+			svcExport: &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export5",
+					Namespace: "ns1",
+				},
+			},
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export5",
+					Namespace: "ns1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Port: 8080},
+					},
+				},
+			},
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export5-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export5"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+				},
+			},
+			tgPolicies: []anv1alpha1.TargetGroupPolicy{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "policy1",
+						Namespace: "ns1",
+					},
+					Spec: anv1alpha1.TargetGroupPolicySpec{
+						Protocol:        aws.String(vpclattice.TargetGroupProtocolHttps),
+						ProtocolVersion: aws.String(vpclattice.TargetGroupProtocolVersionHttp2),
+						TargetRef: &gateway_api_v1alpha2.PolicyTargetReference{
+							Kind: "Service",
+							Name: "export5",
+						},
+						HealthCheck: &anv1alpha1.HealthCheckConfig{
+							Enabled:         aws.Bool(true),
+							IntervalSeconds: aws.Int64(10),
+							Path:            aws.String("/health"),
+							StatusMatch:     aws.String("200-299"),
+						},
+					},
+				},
+				{
+					// targets another service, ignored
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "policy2",
+						Namespace: "ns1",
+					},
+					Spec: anv1alpha1.TargetGroupPolicySpec{
+						Protocol: aws.String(vpclattice.TargetGroupProtocolHttp),
+						TargetRef: &gateway_api_v1alpha2.PolicyTargetReference{
+							Kind: "Service",
+							Name: "export1",
+						},
+					},
+				},
+			},
+			wantErrIsNil:  true,
+			wantIsDeleted: false,
+			wantConfig: &latticemodel.TargetGroupConfig{
+				Protocol:              vpclattice.TargetGroupProtocolHttps,
+				ProtocolVersion:       vpclattice.TargetGroupProtocolVersionHttp2,
+				K8SServiceExportPorts: []int32{8080},
+				HealthCheckConfig: &vpclattice.HealthCheckConfig{
+					Enabled:                    aws.Bool(true),
+					HealthCheckIntervalSeconds: aws.Int64(10),
+					Path:                       aws.String("/health"),
+					Matcher: &vpclattice.Matcher{
+						HttpCode: aws.String("200-299"),
+					},
+				},
+			},
+		},
+		{
+			name: "Adding ServieExport with weight annotation",
+			svcExport: &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "export7",
+					Namespace:   "ns1",
+					Annotations: map[string]string{k8s.ServiceExportWeightAnnotation: "80"},
+				},
+			},
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export7",
+					Namespace: "ns1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Port: 80},
+					},
+				},
+			},
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export7-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export7"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+				},
+			},
+			wantErrIsNil:  true,
+			wantIsDeleted: false,
+			wantConfig: &latticemodel.TargetGroupConfig{
+				Protocol:               vpclattice.TargetGroupProtocolHttp,
+				ProtocolVersion:        vpclattice.TargetGroupProtocolVersionHttp1,
+				K8SServiceExportPorts:  []int32{80},
+				K8SServiceExportWeight: aws.Int64(80),
+			},
+		},
+		{
+			name: "Adding ServieExport with invalid weight annotation, ignored",
+			svcExport: &mcs_api.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "export8",
+					Namespace:   "ns1",
+					Annotations: map[string]string{k8s.ServiceExportWeightAnnotation: "1000"},
+				},
+			},
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "export8",
+					Namespace: "ns1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Port: 80},
+					},
+				},
+			},
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export8-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export8"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+				},
+			},
+			wantErrIsNil:  true,
+			wantIsDeleted: false,
+			wantConfig: &latticemodel.TargetGroupConfig{
+				Protocol:               vpclattice.TargetGroupProtocolHttp,
+				ProtocolVersion:        vpclattice.TargetGroupProtocolVersionHttp1,
+				K8SServiceExportPorts:  []int32{80},
+				K8SServiceExportWeight: nil,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,6 +343,7 @@ func Test_TGModelByServicexportBuild(t *testing.T) {
 
 			k8sSchema := runtime.NewScheme()
 			clientgoscheme.AddToScheme(k8sSchema)
+			anv1alpha1.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewFakeClientWithScheme(k8sSchema)
 
 			if tt.svc != nil {
@@ -140,8 +351,12 @@ func Test_TGModelByServicexportBuild(t *testing.T) {
 
 			}
 
-			if tt.endPoints != nil {
-				assert.NoError(t, k8sClient.Create(ctx, tt.endPoints[0].DeepCopy()))
+			for _, endpointSlice := range tt.endpointSlices {
+				assert.NoError(t, k8sClient.Create(ctx, endpointSlice.DeepCopy()))
+			}
+
+			for _, policy := range tt.tgPolicies {
+				assert.NoError(t, k8sClient.Create(ctx, policy.DeepCopy()))
 			}
 
 			ds := latticestore.NewLatticeDataStore()
@@ -171,6 +386,13 @@ func Test_TGModelByServicexportBuild(t *testing.T) {
 				assert.Equal(t, true, dsTG.ByServiceExport)
 			}
 
+			if tt.wantConfig != nil {
+				assert.Equal(t, tt.wantConfig.Protocol, tg.Spec.Config.Protocol)
+				assert.Equal(t, tt.wantConfig.ProtocolVersion, tg.Spec.Config.ProtocolVersion)
+				assert.Equal(t, tt.wantConfig.HealthCheckConfig, tg.Spec.Config.HealthCheckConfig)
+				assert.Equal(t, tt.wantConfig.K8SServiceExportPorts, tg.Spec.Config.K8SServiceExportPorts)
+				assert.Equal(t, tt.wantConfig.K8SServiceExportWeight, tg.Spec.Config.K8SServiceExportWeight)
+			}
 		})
 	}
 }
@@ -188,20 +410,55 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 		return &p
 	}
 
+	portPtr := func(port int32) *gateway_api.PortNumber {
+		p := gateway_api.PortNumber(port)
+		return &p
+	}
+
+	referenceGrant := func(ns string, routeKind string) gateway_api.ReferenceGrant {
+		return gateway_api.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "grant",
+				Namespace: ns,
+			},
+			Spec: gateway_api.ReferenceGrantSpec{
+				From: []gateway_api.ReferenceGrantFrom{
+					{
+						Group:     gateway_api.GroupName,
+						Kind:      gateway_api.Kind(routeKind),
+						Namespace: "default",
+					},
+				},
+				To: []gateway_api.ReferenceGrantTo{
+					{
+						Kind: "Service",
+					},
+				},
+			},
+		}
+	}
+
 	tests := []struct {
-		name          string
-		httpRoute     *gateway_api.HTTPRoute
-		svcExist      bool
-		wantError     error
-		wantErrIsNil  bool
-		wantName      string
-		wantIsDeleted bool
+		name                string
+		httpRoute           *gateway_api.HTTPRoute
+		routeType           string
+		svcExist            bool
+		svcPorts            []corev1.ServicePort
+		referenceGrants     []gateway_api.ReferenceGrant
+		wantError           error
+		wantErrIsNil        bool
+		wantName            string
+		wantIsDeleted       bool
+		wantNotPermitted    bool
+		wantProtocolVersion string
+		wantProtocol        string
 	}{
 		{
 			name: "Add LatticeService",
 			httpRoute: &gateway_api.HTTPRoute{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "service1",
+					Name:      "service1",
+					Namespace: "default",
 				},
 				Spec: gateway_api.HTTPRouteSpec{
 					CommonRouteSpec: gateway_api.CommonRouteSpec{
@@ -228,11 +485,223 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 					},
 				},
 			},
-			svcExist:      true,
-			wantError:     nil,
-			wantName:      "service1",
-			wantIsDeleted: false,
-			wantErrIsNil:  true,
+			svcExist:            true,
+			referenceGrants:     []gateway_api.ReferenceGrant{referenceGrant("ns11", "HTTPRoute")},
+			wantError:           nil,
+			wantName:            "service1",
+			wantIsDeleted:       false,
+			wantErrIsNil:        true,
+			wantProtocolVersion: vpclattice.TargetGroupProtocolVersionHttp1,
+		},
+		{
+			name: "Add LatticeService, protocol version from appProtocol",
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name: "gateway1",
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name:      "service1-tg1",
+											Namespace: namespacePtr("default"),
+											Kind:      kindPtr("Service"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			svcExist: true,
+			svcPorts: []corev1.ServicePort{
+				{Port: 80},
+				{Port: 8080, AppProtocol: aws.String("kubernetes.io/h2c")},
+			},
+			wantError:           nil,
+			wantName:            "service1",
+			wantIsDeleted:       false,
+			wantErrIsNil:        true,
+			wantProtocolVersion: vpclattice.TargetGroupProtocolVersionHttp2,
+		},
+		{
+			name: "Add LatticeService, protocol version from appProtocol of backendRef port",
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name: "gateway1",
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name:      "service1-tg1",
+											Namespace: namespacePtr("default"),
+											Kind:      kindPtr("Service"),
+											Port:      portPtr(8080),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			svcExist: true,
+			svcPorts: []corev1.ServicePort{
+				{Port: 80, AppProtocol: aws.String("http2")},
+				{Port: 8080, AppProtocol: aws.String("grpc")},
+			},
+			wantError:           nil,
+			wantName:            "service1",
+			wantIsDeleted:       false,
+			wantErrIsNil:        true,
+			wantProtocolVersion: vpclattice.TargetGroupProtocolVersionGrpc,
+		},
+		{
+			name: "Add LatticeService, cross namespace backendRef without ReferenceGrant",
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service5",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name: "gateway1",
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name:      "service5-tg1",
+											Namespace: namespacePtr("ns51"),
+											Kind:      kindPtr("Service"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			svcExist:         true,
+			referenceGrants:  []gateway_api.ReferenceGrant{referenceGrant("ns51", "GRPCRoute")},
+			wantError:        nil,
+			wantName:         "service5",
+			wantIsDeleted:    false,
+			wantErrIsNil:     true,
+			wantNotPermitted: true,
+		},
+		{
+			name: "Add LatticeService for GRPCRoute",
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "grpcservice1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name: "gateway1",
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name:      "grpcservice1-tg1",
+											Namespace: namespacePtr("ns11"),
+											Kind:      kindPtr("Service"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			routeType:           latticemodel.K8SGRPCRouteType,
+			svcExist:            true,
+			referenceGrants:     []gateway_api.ReferenceGrant{referenceGrant("ns11", "GRPCRoute")},
+			wantError:           nil,
+			wantName:            "grpcservice1",
+			wantIsDeleted:       false,
+			wantErrIsNil:        true,
+			wantProtocolVersion: vpclattice.TargetGroupProtocolVersionGrpc,
+		},
+		{
+			name: "Add LatticeService for TLSRoute",
+			httpRoute: &gateway_api.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tlsservice1",
+					Namespace: "default",
+				},
+				Spec: gateway_api.HTTPRouteSpec{
+					CommonRouteSpec: gateway_api.CommonRouteSpec{
+						ParentRefs: []gateway_api.ParentReference{
+							{
+								Name: "gateway1",
+							},
+						},
+					},
+					Rules: []gateway_api.HTTPRouteRule{
+						{
+							BackendRefs: []gateway_api.HTTPBackendRef{
+								{
+									BackendRef: gateway_api.BackendRef{
+										BackendObjectReference: gateway_api.BackendObjectReference{
+											Name:      "tlsservice1-tg1",
+											Namespace: namespacePtr("ns11"),
+											Kind:      kindPtr("Service"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			routeType:           latticemodel.K8STLSRouteType,
+			svcExist:            true,
+			referenceGrants:     []gateway_api.ReferenceGrant{referenceGrant("ns11", "TLSRoute")},
+			wantError:           nil,
+			wantName:            "tlsservice1",
+			wantIsDeleted:       false,
+			wantErrIsNil:        true,
+			wantProtocolVersion: "",
+			wantProtocol:        "TCP",
 		},
 		{
 			name: "Delete LatticeService",
@@ -361,15 +830,21 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 
 			k8sSchema := runtime.NewScheme()
 			clientgoscheme.AddToScheme(k8sSchema)
+			gateway_api.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewFakeClientWithScheme(k8sSchema)
 			ds := latticestore.NewLatticeDataStore()
 
+			for _, grant := range tt.referenceGrants {
+				assert.NoError(t, k8sClient.Create(ctx, grant.DeepCopy()))
+			}
+
 			//builder := NewLatticeServiceBuilder(k8sClient, ds, nil)
 
 			stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(tt.httpRoute)))
 
 			task := &latticeServiceModelBuildTask{
 				httpRoute: tt.httpRoute,
+				routeType: tt.routeType,
 				stack:     stack,
 				Client:    k8sClient,
 				tgByResID: make(map[string]*latticemodel.TargetGroup),
@@ -386,6 +861,9 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 									Name:      string(httpBackendRef.Name),
 									Namespace: string(*httpBackendRef.Namespace),
 								},
+								Spec: corev1.ServiceSpec{
+									Ports: tt.svcPorts,
+								},
 							}
 							fmt.Printf("create K8S service %v\n", svc)
 							assert.NoError(t, k8sClient.Create(ctx, svc.DeepCopy()))
@@ -403,11 +881,17 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 				assert.Nil(t, err)
 			}
 
+			if tt.wantNotPermitted {
+				assert.Empty(t, task.tgByResID)
+				return
+			}
+
 			if tt.wantErrIsNil {
 				// verify data store
 				for _, httpRules := range tt.httpRoute.Spec.Rules {
 					for _, httpBackendRef := range httpRules.BackendRefs {
-						tgName := latticestore.TargetGroupName(string(httpBackendRef.Name), string(*httpBackendRef.Namespace))
+						tgName := latticestore.TargetGroupPortName(string(httpBackendRef.Name), string(*httpBackendRef.Namespace),
+							backendRefServicePort(httpBackendRef.BackendRef))
 
 						fmt.Printf("httpBacndendRef %s\n", *httpBackendRef.BackendObjectReference.Kind)
 						if "Service" == *httpBackendRef.BackendObjectReference.Kind {
@@ -420,6 +904,14 @@ func Test_TGModelByHTTPRouteBuild(t *testing.T) {
 								assert.Equal(t, true, dsTG.ByBackendRef)
 								fmt.Printf("--dsTG %v\n", dsTG)
 								assert.Nil(t, err)
+								tg := task.tgByResID[tgName]
+								assert.Equal(t, tt.wantProtocolVersion, tg.Spec.Config.ProtocolVersion)
+								wantProtocol := "HTTP"
+								if tt.wantProtocol != "" {
+									wantProtocol = tt.wantProtocol
+								}
+								assert.Equal(t, wantProtocol, tg.Spec.Config.Protocol)
+								assert.Equal(t, tt.routeType, tg.Spec.Config.K8SRouteType)
 							}
 						} else {
 							// the routename for serviceimport is ""
@@ -582,6 +1074,30 @@ func Test_TGModelByHTTPRouteImportBuild(t *testing.T) {
 
 		k8sClient := mock_client.NewMockClient(c)
 
+		// cross namespace serviceimports are granted by a ReferenceGrant in the serviceimport namespace
+		k8sClient.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, grants *gateway_api.ReferenceGrantList, arg3 ...interface{}) error {
+				grants.Items = append(grants.Items, gateway_api.ReferenceGrant{
+					Spec: gateway_api.ReferenceGrantSpec{
+						From: []gateway_api.ReferenceGrantFrom{
+							{
+								Group:     gateway_api.GroupName,
+								Kind:      "HTTPRoute",
+								Namespace: gateway_api.Namespace(tt.httpRoute.Namespace),
+							},
+						},
+						To: []gateway_api.ReferenceGrantTo{
+							{
+								Group: k8s.ServiceImportGroup,
+								Kind:  "ServiceImport",
+							},
+						},
+					},
+				})
+				return nil
+			},
+		).AnyTimes()
+
 		ds := latticestore.NewLatticeDataStore()
 
 		//builder := NewLatticeServiceBuilder(k8sClient, ds, nil)
@@ -662,3 +1178,175 @@ func Test_TGModelByHTTPRouteImportBuild(t *testing.T) {
 		}
 	}
 }
+
+func Test_TGModelByHTTPRouteLambdaBuild(t *testing.T) {
+	now := metav1.Now()
+	functionARN := "arn:aws:lambda:us-west-2:123456789012:function:fn"
+
+	groupPtr := func(g string) *gateway_api.Group {
+		p := gateway_api.Group(g)
+		return &p
+	}
+
+	kindPtr := func(k string) *gateway_api.Kind {
+		p := gateway_api.Kind(k)
+		return &p
+	}
+
+	weight := int32(30)
+	lambdaRule := gateway_api.HTTPRouteRule{
+		BackendRefs: []gateway_api.HTTPBackendRef{
+			{
+				BackendRef: gateway_api.BackendRef{
+					BackendObjectReference: gateway_api.BackendObjectReference{
+						Group: groupPtr(anv1alpha1.GroupVersion.Group),
+						Kind:  kindPtr(anv1alpha1.LambdaTargetKind),
+						Name:  "fn",
+					},
+					Weight: &weight,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		routeType          string
+		deletionTimestamp  *metav1.Time
+		lambdaTargetExist  bool
+		wantTGError        error
+		wantTargetsErr     bool
+		wantIsDeleted      bool
+		wantTargetFunction string
+	}{
+		{
+			name:               "Add lambda target group",
+			lambdaTargetExist:  true,
+			wantTargetFunction: functionARN,
+		},
+		{
+			name:              "LambdaTarget does not exist",
+			lambdaTargetExist: false,
+			wantTargetsErr:    true,
+		},
+		{
+			name:              "Delete lambda target group",
+			deletionTimestamp: &now,
+			lambdaTargetExist: false,
+			wantIsDeleted:     true,
+		},
+		{
+			name:              "LambdaTarget is not supported by GRPCRoute",
+			routeType:         latticemodel.K8SGRPCRouteType,
+			lambdaTargetExist: true,
+			wantTGError:       errors.New(LATTICE_UNSUPPORTED_LAMBDA_BACKEND),
+		},
+	}
+
+	for _, tt := range tests {
+		fmt.Printf("Test >>>> %v\n", tt.name)
+		ctx := context.Background()
+
+		httpRoute := &gateway_api.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "route1",
+				Namespace:         "default",
+				DeletionTimestamp: tt.deletionTimestamp,
+			},
+			Spec: gateway_api.HTTPRouteSpec{
+				CommonRouteSpec: gateway_api.CommonRouteSpec{
+					ParentRefs: []gateway_api.ParentReference{
+						{
+							Name: "gateway1",
+						},
+					},
+				},
+				Rules: []gateway_api.HTTPRouteRule{lambdaRule},
+			},
+		}
+
+		k8sSchema := runtime.NewScheme()
+		clientgoscheme.AddToScheme(k8sSchema)
+		anv1alpha1.AddToScheme(k8sSchema)
+		k8sClient := testclient.NewFakeClientWithScheme(k8sSchema)
+
+		if tt.lambdaTargetExist {
+			assert.Nil(t, k8sClient.Create(ctx, &anv1alpha1.LambdaTarget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "fn",
+					Namespace: "default",
+				},
+				Spec: anv1alpha1.LambdaTargetSpec{
+					FunctionARN: functionARN,
+				},
+			}))
+		}
+
+		ds := latticestore.NewLatticeDataStore()
+		stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(httpRoute)))
+		task := &latticeServiceModelBuildTask{
+			httpRoute: httpRoute,
+			routeType: tt.routeType,
+			stack:     stack,
+			Client:    k8sClient,
+			tgByResID: make(map[string]*latticemodel.TargetGroup),
+			Datastore: ds,
+		}
+
+		_, err := task.buildTargetGroup(ctx, k8sClient)
+		if tt.wantTGError != nil {
+			assert.Equal(t, tt.wantTGError, err)
+			continue
+		}
+		assert.Nil(t, err)
+
+		tgName := latticestore.LambdaTargetGroupName("fn", "default")
+		tg := task.tgByResID[tgName]
+		assert.NotNil(t, tg)
+		assert.Equal(t, latticemodel.TargetGroupTypeLambda, tg.Spec.Type)
+		assert.Equal(t, tt.wantIsDeleted, tg.Spec.IsDeleted)
+		assert.Equal(t, "", tg.Spec.Config.VpcID)
+		assert.Equal(t, int32(0), tg.Spec.Config.Port)
+		assert.Equal(t, "", tg.Spec.Config.Protocol)
+		assert.Equal(t, "fn", tg.Spec.Config.K8SServiceName)
+		assert.Equal(t, "route1", tg.Spec.Config.K8SHTTPRouteName)
+
+		// the lambda target group is route scoped, like service target groups
+		dsTG, err := ds.GetTargetGroup(tgName, "route1", false)
+		assert.Nil(t, err)
+		assert.Equal(t, !tt.wantIsDeleted, dsTG.ByBackendRef)
+
+		err = task.buildTargets(ctx)
+		if tt.wantTargetsErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+
+		var resTargets []*latticemodel.Targets
+		stack.ListResources(&resTargets)
+		if tt.wantTargetFunction == "" {
+			assert.Equal(t, 0, len(resTargets))
+		} else {
+			assert.Equal(t, 1, len(resTargets))
+			assert.Equal(t, latticemodel.TargetsSpec{
+				Name:         "fn",
+				Namespace:    "default",
+				RouteName:    "route1",
+				IsLambda:     true,
+				TargetIPList: []latticemodel.Target{{TargetIP: tt.wantTargetFunction}},
+			}, resTargets[0].Spec)
+		}
+
+		ruleTGs := task.buildRuleTargetGroups(ctx, &lambdaRule)
+		assert.Equal(t, []*latticemodel.RuleTargetGroup{
+			{
+				Name:      "fn",
+				Namespace: "default",
+				RouteName: "route1",
+				IsLambda:  true,
+				Weight:    int64(weight),
+			},
+		}, ruleTGs)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"github.com/golang/glog"
 
+	"github.com/aws/aws-sdk-go/aws"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
 	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
@@ -22,7 +25,7 @@ const (
 )
 
 type LatticeTargetsBuilder interface {
-	Build(ctx context.Context, service *corev1.Service, routename string) (core.Stack, *latticemodel.Targets, error)
+	Build(ctx context.Context, service *corev1.Service, routename string, port int32) (core.Stack, *latticemodel.Targets, error)
 }
 
 type latticeTargetsModelBuilder struct {
@@ -42,7 +45,7 @@ func NewTargetsBuilder(client client.Client, cloud lattice_aws.Cloud, datastore
 	}
 }
 
-func (b *latticeTargetsModelBuilder) Build(ctx context.Context, service *corev1.Service, routename string) (core.Stack, *latticemodel.Targets, error) {
+func (b *latticeTargetsModelBuilder) Build(ctx context.Context, service *corev1.Service, routename string, port int32) (core.Stack, *latticemodel.Targets, error) {
 	stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName((service))))
 
 	task := &latticeTargetsModelBuildTask{
@@ -50,6 +53,7 @@ func (b *latticeTargetsModelBuilder) Build(ctx context.Context, service *corev1.
 		tgName:      service.Name,
 		tgNamespace: service.Namespace,
 		routename:   routename,
+		port:        port,
 		stack:       stack,
 		datastore:   b.datastore,
 	}
@@ -80,7 +84,7 @@ func (t *latticeTargetsModelBuildTask) buildModel(ctx context.Context) error {
 
 func (t *latticeTargetsModelBuildTask) buildLatticeTargets(ctx context.Context) error {
 	ds := t.datastore
-	tgName := latticestore.TargetGroupName(t.tgName, t.tgNamespace)
+	tgName := latticestore.TargetGroupPortName(t.tgName, t.tgNamespace, t.port)
 	tg, err := ds.GetTargetGroup(tgName, t.routename, false) // isServiceImport= false
 
 	if err != nil {
@@ -95,8 +99,6 @@ func (t *latticeTargetsModelBuildTask) buildLatticeTargets(ctx context.Context)
 		return errors.New(errmsg)
 	}
 
-	endPoints := &corev1.Endpoints{}
-
 	svc := &corev1.Service{}
 	namespacedName := types.NamespacedName{
 		Namespace: t.tgNamespace,
@@ -107,39 +109,88 @@ func (t *latticeTargetsModelBuildTask) buildLatticeTargets(ctx context.Context)
 		errmsg := fmt.Sprintf("Build Targets failed because K8S service %v does not exist", namespacedName)
 		return errors.New(errmsg)
 	}
-	var targetList []latticemodel.Target
+	var targetList, terminatingList []latticemodel.Target
 
 	if svc.DeletionTimestamp.IsZero() {
-		if err := t.Client.Get(ctx, namespacedName, endPoints); err != nil {
-			errmsg := fmt.Sprintf("Build Targets failed because K8S service %v does not exist", namespacedName)
+		endpointSlices := &discoveryv1.EndpointSliceList{}
+		if err := t.Client.List(ctx, endpointSlices, client.InNamespace(t.tgNamespace),
+			client.MatchingLabels{discoveryv1.LabelServiceName: t.tgName}); err != nil || len(endpointSlices.Items) == 0 {
+			errmsg := fmt.Sprintf("Build Targets failed because K8S service %v has no EndpointSlices, err %v", namespacedName, err)
 			glog.V(6).Infof("errmsg: %v\n", errmsg)
 			return errors.New(errmsg)
 		}
 
-		glog.V(6).Infof("Build Targets:  endPoints %v \n", endPoints)
+		glog.V(6).Infof("Build Targets:  endpointSlices %v \n", endpointSlices.Items)
 
-		for _, endPoint := range endPoints.Subsets {
+		svcPort, ok := t.findServicePort(svc)
+		if !ok {
+			errmsg := fmt.Sprintf("Build Targets failed because K8S service %v has no port %d", namespacedName, t.port)
+			glog.V(6).Infof("errmsg: %v\n", errmsg)
+			return errors.New(errmsg)
+		}
 
-			for _, address := range endPoint.Addresses {
-				for _, port := range endPoint.Ports {
-					glog.V(6).Infof("serviceReconcile-endpoints: address %v, port %v\n", address, port)
-					target := latticemodel.Target{
-						TargetIP: address.IP,
-						Port:     int64(port.Port),
+		// an endpoint can be in several slices while it moves between them, register it once
+		registered := make(map[latticemodel.Target]bool)
+		terminatingTargets := make(map[latticemodel.Target]bool)
+		for _, endpointSlice := range endpointSlices.Items {
+			if endpointSlice.AddressType != discoveryv1.AddressTypeIPv4 && endpointSlice.AddressType != discoveryv1.AddressTypeIPv6 {
+				continue
+			}
+			for _, endpoint := range endpointSlice.Endpoints {
+				terminating := false
+				if !isEndpointTarget(endpoint) && !t.isPodReadinessGatePending(ctx, endpoint) {
+					if !isEndpointTerminatingServing(endpoint) {
+						glog.V(6).Infof("serviceReconcile-endpoints: skip endpoint %v, conditions %v\n", endpoint.Addresses, endpoint.Conditions)
+						continue
+					}
+					terminating = true
+				}
+				for _, address := range endpoint.Addresses {
+					for _, port := range endpointSlice.Ports {
+						if port.Port == nil || (svcPort != nil && aws.StringValue(port.Name) != svcPort.Name) {
+							continue
+						}
+						glog.V(6).Infof("serviceReconcile-endpoints: address %v, port %v\n", address, *port.Port)
+						target := latticemodel.Target{
+							TargetIP: address,
+							Port:     int64(*port.Port),
+						}
+						if terminating {
+							if !terminatingTargets[target] {
+								terminatingTargets[target] = true
+								terminatingList = append(terminatingList, target)
+							}
+							continue
+						}
+						if registered[target] {
+							continue
+						}
+						registered[target] = true
+						targetList = append(targetList, target)
 					}
-					targetList = append(targetList, target)
 				}
 			}
 		}
+
+		// an endpoint moving between slices can be terminating in one and ready in the other
+		var servingTerminating []latticemodel.Target
+		for _, target := range terminatingList {
+			if !registered[target] {
+				servingTerminating = append(servingTerminating, target)
+			}
+		}
+		terminatingList = servingTerminating
 	}
 
-	glog.V(6).Infof("Build Targets--- targetIPList [%v]\n", targetList)
+	glog.V(6).Infof("Build Targets--- targetIPList [%v], terminating [%v]\n", targetList, terminatingList)
 
 	spec := latticemodel.TargetsSpec{
-		Name:         t.tgName,
-		Namespace:    t.tgNamespace,
-		RouteName:    t.routename,
-		TargetIPList: targetList,
+		Name:                  t.tgName,
+		Namespace:             t.tgNamespace,
+		RouteName:             t.routename,
+		Port:                  t.port,
+		TargetIPList:          targetList,
+		TerminatingTargetList: terminatingList,
 	}
 
 	t.latticeTargets = latticemodel.NewTargets(t.stack, tgName, spec)
@@ -147,11 +198,71 @@ func (t *latticeTargetsModelBuildTask) buildLatticeTargets(ctx context.Context)
 	return nil
 }
 
+// isEndpointTarget returns whether the endpoint is registered as target according to the endpoint readiness policy.
+// Unknown (nil) conditions are interpreted as ready, as recommended by the EndpointSlice API
+func isEndpointTarget(endpoint discoveryv1.Endpoint) bool {
+	conditions := endpoint.Conditions
+	switch config.EndpointReadinessPolicy {
+	case config.EndpointReadinessPolicyAll:
+		return true
+	case config.EndpointReadinessPolicyServing:
+		if conditions.Serving != nil {
+			return *conditions.Serving
+		}
+	}
+	return conditions.Ready == nil || *conditions.Ready
+}
+
+// isEndpointTerminatingServing returns whether the endpoint is terminating but still serving, its target is kept
+// registered until it is drained
+func isEndpointTerminatingServing(endpoint discoveryv1.Endpoint) bool {
+	conditions := endpoint.Conditions
+	return conditions.Terminating != nil && *conditions.Terminating &&
+		conditions.Serving != nil && *conditions.Serving
+}
+
+// isPodReadinessGatePending returns whether the endpoint is a pod which is only waiting for the lattice readiness
+// gate, it has to be registered for its target to become healthy and the gate to pass
+func (t *latticeTargetsModelBuildTask) isPodReadinessGatePending(ctx context.Context, endpoint discoveryv1.Endpoint) bool {
+	if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" ||
+		(endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating) {
+		return false
+	}
+
+	pod := &corev1.Pod{}
+	podName := types.NamespacedName{
+		Namespace: t.tgNamespace,
+		Name:      endpoint.TargetRef.Name,
+	}
+	if err := t.Client.Get(ctx, podName, pod); err != nil || !k8s.HasPodReadinessGate(pod) {
+		return false
+	}
+
+	containersReady := k8s.GetPodCondition(pod, corev1.ContainersReady)
+	return containersReady != nil && containersReady.Status == corev1.ConditionTrue
+}
+
+// findServicePort returns the service port of the backendRef, nil when the targets are built for all ports.
+// EndpointSlice ports carry the name of their service port, which resolves named targetPorts
+func (t *latticeTargetsModelBuildTask) findServicePort(svc *corev1.Service) (*corev1.ServicePort, bool) {
+	if t.port == 0 {
+		return nil, true
+	}
+	for i, svcPort := range svc.Spec.Ports {
+		if svcPort.Port == t.port {
+			return &svc.Spec.Ports[i], true
+		}
+	}
+	return nil, false
+}
+
 type latticeTargetsModelBuildTask struct {
 	client.Client
 	tgName      string
 	tgNamespace string
 	routename   string
+	// port is the service port of the backendRef, 0 for all ports
+	port int32
 
 	latticeTargets *latticemodel.Targets
 	stack          core.Stack
@@ -6,49 +6,140 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
 	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
 )
 
 func Test_Targets(t *testing.T) {
+	int32Ptr := func(i int32) *int32 {
+		return &i
+	}
+
+	// endpoints of a service with all conditions, spread over two slices
+	conditionSlices := []discoveryv1.EndpointSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1",
+				Name:      "export1-1",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses:  []string{"10.10.1.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(true), Serving: aws.Bool(true)},
+				},
+				{
+					Addresses: []string{"10.10.2.2"},
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(false), Serving: aws.Bool(true),
+						Terminating: aws.Bool(true)},
+				},
+				{
+					Addresses:  []string{"10.10.3.3"},
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(false), Serving: aws.Bool(false)},
+				},
+			},
+			Ports: []discoveryv1.EndpointPort{
+				{Name: aws.String("http"), Port: int32Ptr(8080)},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1",
+				Name:      "export1-2",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					// unknown conditions are interpreted as ready
+					Addresses: []string{"10.10.4.4"},
+				},
+				{
+					// moving between slices
+					Addresses:  []string{"10.10.1.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(true), Serving: aws.Bool(true)},
+				},
+			},
+			Ports: []discoveryv1.EndpointPort{
+				{Name: aws.String("http"), Port: int32Ptr(8080)},
+			},
+		},
+		{
+			// slice of another service
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1",
+				Name:      "export2-1",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "export2"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.10.9.9"}},
+			},
+			Ports: []discoveryv1.EndpointPort{
+				{Name: aws.String("http"), Port: int32Ptr(8080)},
+			},
+		},
+	}
+	conditionSvc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "export1",
+		},
+	}
+
 	tests := []struct {
 		name               string
 		srvExportName      string
 		srvExportNamespace string
-		endPoints          []corev1.Endpoints
+		port               int32
+		endpointSlices     []discoveryv1.EndpointSlice
+		readinessPolicy    string
+		pods               []corev1.Pod
 		svc                corev1.Service
 		inDataStore        bool
 		refByServiceExport bool
 		refByService       bool
 		wantErrIsNil       bool
 		expectedTargetList []latticemodel.Target
+		// expectedTerminatingList are the targets of terminating endpoints which are still serving
+		expectedTerminatingList []latticemodel.Target
 	}{
 		{
 			name:               "Add all endpoints to build spec",
 			srvExportName:      "export1",
 			srvExportNamespace: "ns1",
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
 					},
-					Subsets: []corev1.EndpointSubset{
-						{
-							Addresses: []corev1.EndpointAddress{{IP: "10.10.1.1"}, {IP: "10.10.2.2"}},
-							Ports:     []corev1.EndpointPort{{Name: "a", Port: 8675}, {Name: "b", Port: 309}},
-						},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("a"), Port: int32Ptr(8675)},
+						{Name: aws.String("b"), Port: int32Ptr(309)},
 					},
 				},
 			},
@@ -81,21 +172,110 @@ func Test_Targets(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:               "Add endpoints of the backendRef port, with named targetPort",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			port:               80,
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("http"), Port: int32Ptr(8080)},
+						{Name: aws.String("metrics"), Port: int32Ptr(9090)},
+					},
+				},
+			},
+			svc: corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns1",
+					Name:      "export1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+						{Name: "metrics", Port: 9090, TargetPort: intstr.FromInt(9090)},
+					},
+				},
+			},
+			inDataStore:  true,
+			refByService: true,
+			wantErrIsNil: true,
+			expectedTargetList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.1.1",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.2.2",
+					Port:     8080,
+				},
+			},
+		},
+		{
+			name:               "Service has no backendRef port",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			port:               8443,
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("http"), Port: int32Ptr(8080)},
+					},
+				},
+			},
+			svc: corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns1",
+					Name:      "export1",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Name: "http", Port: 80},
+					},
+				},
+			},
+			inDataStore:  true,
+			refByService: true,
+			wantErrIsNil: false,
+		},
 		{
 			name:               "Delete svc and all endpoints to build spec",
 			srvExportName:      "export1",
 			srvExportNamespace: "ns1",
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
 					},
-					Subsets: []corev1.EndpointSubset{
-						{
-							Addresses: []corev1.EndpointAddress{{IP: "10.10.1.1"}, {IP: "10.10.2.2"}},
-							Ports:     []corev1.EndpointPort{{Name: "a", Port: 8675}, {Name: "b", Port: 309}},
-						},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("a"), Port: int32Ptr(8675)},
+						{Name: aws.String("b"), Port: int32Ptr(309)},
 					},
 				},
 			},
@@ -117,7 +297,7 @@ func Test_Targets(t *testing.T) {
 			name:               "Delete svc and no endpoints to build spec",
 			srvExportName:      "export1",
 			srvExportNamespace: "ns1",
-			endPoints:          []corev1.Endpoints{},
+			endpointSlices:     []discoveryv1.EndpointSlice{},
 			svc: corev1.Service{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: "ns1",
@@ -136,17 +316,21 @@ func Test_Targets(t *testing.T) {
 			name:               "Endpoints without TargetGroup",
 			srvExportName:      "export2",
 			srvExportNamespace: "ns1",
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
 					},
-					Subsets: []corev1.EndpointSubset{
-						{
-							Addresses: []corev1.EndpointAddress{{IP: "10.10.1.1"}, {IP: "10.10.2.2"}},
-							Ports:     []corev1.EndpointPort{{Name: "a", Port: 8675}, {Name: "b", Port: 309}},
-						},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("a"), Port: int32Ptr(8675)},
+						{Name: aws.String("b"), Port: int32Ptr(309)},
 					},
 				},
 			},
@@ -165,17 +349,21 @@ func Test_Targets(t *testing.T) {
 			name:               "Endpoints's TargetGroup is NOT referenced by serviceexport",
 			srvExportName:      "export3",
 			srvExportNamespace: "ns1",
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
 					},
-					Subsets: []corev1.EndpointSubset{
-						{
-							Addresses: []corev1.EndpointAddress{{IP: "10.10.1.1"}, {IP: "10.10.2.2"}},
-							Ports:     []corev1.EndpointPort{{Name: "a", Port: 8675}, {Name: "b", Port: 309}},
-						},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("a"), Port: int32Ptr(8675)},
+						{Name: aws.String("b"), Port: int32Ptr(309)},
 					},
 				},
 			},
@@ -210,17 +398,21 @@ func Test_Targets(t *testing.T) {
 			name:               "Add all endpoints to build spec",
 			srvExportName:      "export5",
 			srvExportNamespace: "ns1",
-			endPoints: []corev1.Endpoints{
+			endpointSlices: []discoveryv1.EndpointSlice{
 				{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "ns1",
-						Name:      "export5",
+						Name:      "export5-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export5"},
 					},
-					Subsets: []corev1.EndpointSubset{
-						{
-							Addresses: []corev1.EndpointAddress{{IP: "10.10.1.1"}, {IP: "10.10.2.2"}},
-							Ports:     []corev1.EndpointPort{{Name: "a", Port: 8675}, {Name: "b", Port: 309}},
-						},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.10.1.1"}},
+						{Addresses: []string{"10.10.2.2"}},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("a"), Port: int32Ptr(8675)},
+						{Name: aws.String("b"), Port: int32Ptr(309)},
 					},
 				},
 			},
@@ -253,6 +445,161 @@ func Test_Targets(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:               "Only ready endpoints by default, serving terminating endpoints are kept for draining",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			endpointSlices:     conditionSlices,
+			svc:                conditionSvc,
+			inDataStore:        true,
+			refByServiceExport: true,
+			wantErrIsNil:       true,
+			expectedTargetList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.1.1",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.4.4",
+					Port:     8080,
+				},
+			},
+			expectedTerminatingList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.2.2",
+					Port:     8080,
+				},
+			},
+		},
+		{
+			name:               "Ready and serving terminating endpoints with serving policy",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			readinessPolicy:    config.EndpointReadinessPolicyServing,
+			endpointSlices:     conditionSlices,
+			svc:                conditionSvc,
+			inDataStore:        true,
+			refByServiceExport: true,
+			wantErrIsNil:       true,
+			expectedTargetList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.1.1",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.2.2",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.4.4",
+					Port:     8080,
+				},
+			},
+		},
+		{
+			name:               "All endpoints with all policy",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			readinessPolicy:    config.EndpointReadinessPolicyAll,
+			endpointSlices:     conditionSlices,
+			svc:                conditionSvc,
+			inDataStore:        true,
+			refByServiceExport: true,
+			wantErrIsNil:       true,
+			expectedTargetList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.1.1",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.2.2",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.3.3",
+					Port:     8080,
+				},
+				{
+					TargetIP: "10.10.4.4",
+					Port:     8080,
+				},
+			},
+		},
+		{
+			name:               "Service has no EndpointSlices",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			svc:                conditionSvc,
+			inDataStore:        true,
+			refByServiceExport: true,
+			wantErrIsNil:       false,
+		},
+		{
+			name:               "Not ready pods only waiting for the readiness gate",
+			srvExportName:      "export1",
+			srvExportNamespace: "ns1",
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "export1-1",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "export1"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{
+							Addresses:  []string{"10.10.1.1"},
+							Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(false)},
+							TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "gated-pod"},
+						},
+						{
+							Addresses:  []string{"10.10.2.2"},
+							Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(false)},
+							TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "starting-pod"},
+						},
+					},
+					Ports: []discoveryv1.EndpointPort{
+						{Name: aws.String("http"), Port: int32Ptr(8080)},
+					},
+				},
+			},
+			pods: []corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "gated-pod",
+					},
+					Spec: corev1.PodSpec{
+						ReadinessGates: []corev1.PodReadinessGate{{ConditionType: k8s.PodReadinessGateConditionType}},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns1",
+						Name:      "starting-pod",
+					},
+					Spec: corev1.PodSpec{
+						ReadinessGates: []corev1.PodReadinessGate{{ConditionType: k8s.PodReadinessGateConditionType}},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionFalse}},
+					},
+				},
+			},
+			svc:                conditionSvc,
+			inDataStore:        true,
+			refByServiceExport: true,
+			wantErrIsNil:       true,
+			expectedTargetList: []latticemodel.Target{
+				{
+					TargetIP: "10.10.1.1",
+					Port:     8080,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -264,16 +611,25 @@ func Test_Targets(t *testing.T) {
 		clientgoscheme.AddToScheme(k8sSchema)
 		k8sClient := testclient.NewFakeClientWithScheme(k8sSchema)
 
-		if len(tt.endPoints) > 0 {
-			assert.NoError(t, k8sClient.Create(ctx, tt.endPoints[0].DeepCopy()))
+		for _, endpointSlice := range tt.endpointSlices {
+			assert.NoError(t, k8sClient.Create(ctx, endpointSlice.DeepCopy()))
 		}
 
 		assert.NoError(t, k8sClient.Create(ctx, tt.svc.DeepCopy()))
 
+		for _, pod := range tt.pods {
+			assert.NoError(t, k8sClient.Create(ctx, pod.DeepCopy()))
+		}
+
+		config.EndpointReadinessPolicy = config.EndpointReadinessPolicyReady
+		if tt.readinessPolicy != "" {
+			config.EndpointReadinessPolicy = tt.readinessPolicy
+		}
+
 		ds := latticestore.NewLatticeDataStore()
 
 		if tt.inDataStore {
-			tgName := latticestore.TargetGroupName(tt.srvExportName, tt.srvExportNamespace)
+			tgName := latticestore.TargetGroupPortName(tt.srvExportName, tt.srvExportNamespace, tt.port)
 			err := ds.AddTargetGroup(tgName, "", "", "", false, "")
 			assert.Nil(t, err)
 			if tt.refByServiceExport {
@@ -293,6 +649,7 @@ func Test_Targets(t *testing.T) {
 			Client:      k8sClient,
 			tgName:      tt.srvExportName,
 			tgNamespace: tt.srvExportNamespace,
+			port:        tt.port,
 			datastore:   ds,
 			stack:       core.NewDefaultStack(core.StackID(srvName)),
 		}
@@ -305,6 +662,7 @@ func Test_Targets(t *testing.T) {
 
 			// verify targets, ports are built correctly
 			assert.Equal(t, tt.expectedTargetList, targetTask.latticeTargets.Spec.TargetIPList)
+			assert.Equal(t, tt.expectedTerminatingList, targetTask.latticeTargets.Spec.TerminatingTargetList)
 			assert.Nil(t, err)
 
 		} else {
@@ -312,4 +670,5 @@ func Test_Targets(t *testing.T) {
 		}
 
 	}
+	config.EndpointReadinessPolicy = config.EndpointReadinessPolicyReady
 }
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	lattice_aws "github.com/aws/aws-application-networking-k8s/pkg/aws"
+	"github.com/aws/aws-application-networking-k8s/pkg/k8s"
+	"github.com/aws/aws-application-networking-k8s/pkg/latticestore"
+	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
+	latticemodel "github.com/aws/aws-application-networking-k8s/pkg/model/lattice"
+)
+
+const (
+	LATTICE_UNSUPPORTED_MULTIPLE_TLS_RULES = "LATTICE_UNSUPPORTED_MULTIPLE_TLS_RULES"
+	// a lattice service has a single custom domain name to route connections to by SNI
+	LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES = "LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES"
+)
+
+type TLSLatticeServiceBuilder interface {
+	Build(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) (core.Stack, *latticemodel.Service, error)
+}
+
+type tlsLatticeServiceModelBuilder struct {
+	client.Client
+	Datastore *latticestore.LatticeDataStore
+
+	cloud lattice_aws.Cloud
+}
+
+func NewTLSLatticeServiceBuilder(client client.Client, datastore *latticestore.LatticeDataStore, cloud lattice_aws.Cloud) *tlsLatticeServiceModelBuilder {
+	return &tlsLatticeServiceModelBuilder{
+		Client:    client,
+		Datastore: datastore,
+		cloud:     cloud,
+	}
+}
+
+// Build translates the TLSRoute into a HTTPRoute with a single catch-all rule and builds the lattice service
+// from it with TCP target groups. The catch-all rule is the default action of the TLS passthrough listeners,
+// connections reach the service by SNI through its custom domain name, the hostname of the route
+func (b *tlsLatticeServiceModelBuilder) Build(ctx context.Context, tlsRoute *gateway_api_v1alpha2.TLSRoute) (core.Stack, *latticemodel.Service, error) {
+	stack := core.NewDefaultStack(core.StackID(k8s.NamespacedName(tlsRoute)))
+
+	httpRoute, err := translateTLSRoute(tlsRoute)
+	if err != nil {
+		glog.V(2).Infof("TLSLatticeServiceBuilder: failed to translate tlsroute %v-%v, err %v\n",
+			tlsRoute.Name, tlsRoute.Namespace, err)
+		return stack, nil, err
+	}
+
+	task := &latticeServiceModelBuildTask{
+		httpRoute: httpRoute,
+		routeType: latticemodel.K8STLSRouteType,
+		stack:     stack,
+		Client:    b.Client,
+		tgByResID: make(map[string]*latticemodel.TargetGroup),
+		Datastore: b.Datastore,
+	}
+
+	if err := task.run(ctx); err != nil {
+		return stack, task.latticeService, fmt.Errorf("failed to build tlsroute %s-%s: %w",
+			tlsRoute.Name, tlsRoute.Namespace, err)
+	}
+
+	return task.stack, task.latticeService, nil
+}
+
+func translateTLSRoute(tlsRoute *gateway_api_v1alpha2.TLSRoute) (*gateway_api.HTTPRoute, error) {
+	httpRoute := &gateway_api.HTTPRoute{
+		ObjectMeta: *tlsRoute.ObjectMeta.DeepCopy(),
+		Spec: gateway_api.HTTPRouteSpec{
+			CommonRouteSpec: *tlsRoute.Spec.CommonRouteSpec.DeepCopy(),
+			Hostnames:       tlsRoute.Spec.Hostnames,
+		},
+	}
+
+	if len(tlsRoute.Spec.Hostnames) > 1 {
+		glog.V(2).Infof("Unsupported %d hostnames for tlsroute %s namespace %s",
+			len(tlsRoute.Spec.Hostnames), tlsRoute.Name, tlsRoute.Namespace)
+		return nil, errors.New(LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES)
+	}
+
+	// a TLS passthrough listener has no rules, only a default action
+	if len(tlsRoute.Spec.Rules) > 1 {
+		glog.V(2).Infof("Unsupported %d rules for tlsroute %s namespace %s",
+			len(tlsRoute.Spec.Rules), tlsRoute.Name, tlsRoute.Namespace)
+		return nil, errors.New(LATTICE_UNSUPPORTED_MULTIPLE_TLS_RULES)
+	}
+
+	for _, tlsRule := range tlsRoute.Spec.Rules {
+		httpRule := gateway_api.HTTPRouteRule{}
+
+		for _, tlsBackendRef := range tlsRule.BackendRefs {
+			httpRule.BackendRefs = append(httpRule.BackendRefs, gateway_api.HTTPBackendRef{
+				BackendRef: *tlsBackendRef.DeepCopy(),
+			})
+		}
+
+		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, httpRule)
+	}
+
+	return httpRoute, nil
+}
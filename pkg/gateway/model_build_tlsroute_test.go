@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func Test_TLSRouteTranslate(t *testing.T) {
+	var serviceKind gateway_api.Kind = "Service"
+	var namespace = gateway_api.Namespace("default")
+	var weight1 = int32(10)
+	var weight2 = int32(90)
+
+	var backendRef1 = gateway_api.BackendRef{
+		BackendObjectReference: gateway_api.BackendObjectReference{
+			Name:      "targetgroup1",
+			Namespace: &namespace,
+			Kind:      &serviceKind,
+		},
+		Weight: &weight1,
+	}
+	var backendRef2 = gateway_api.BackendRef{
+		BackendObjectReference: gateway_api.BackendObjectReference{
+			Name: "targetgroup2",
+			Kind: &serviceKind,
+		},
+		Weight: &weight2,
+	}
+
+	tests := []struct {
+		name                string
+		hostnames           []gateway_api.Hostname
+		rules               []gateway_api_v1alpha2.TLSRouteRule
+		expectedBackendRefs []gateway_api.HTTPBackendRef
+		wantErr             error
+	}{
+		{
+			name:      "single backend",
+			hostnames: []gateway_api.Hostname{"tls.example.com"},
+			rules: []gateway_api_v1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gateway_api.BackendRef{backendRef1},
+				},
+			},
+			expectedBackendRefs: []gateway_api.HTTPBackendRef{{BackendRef: backendRef1}},
+		},
+		{
+			name: "weighted backends, no hostname",
+			rules: []gateway_api_v1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gateway_api.BackendRef{backendRef1, backendRef2},
+				},
+			},
+			expectedBackendRefs: []gateway_api.HTTPBackendRef{{BackendRef: backendRef1}, {BackendRef: backendRef2}},
+		},
+		{
+			name:      "Negative, multiple rules",
+			hostnames: []gateway_api.Hostname{"tls.example.com"},
+			rules: []gateway_api_v1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gateway_api.BackendRef{backendRef1},
+				},
+				{
+					BackendRefs: []gateway_api.BackendRef{backendRef2},
+				},
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_MULTIPLE_TLS_RULES),
+		},
+		{
+			name:      "Negative, multiple hostnames",
+			hostnames: []gateway_api.Hostname{"tls.example.com", "tls2.example.com"},
+			rules: []gateway_api_v1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gateway_api.BackendRef{backendRef1},
+				},
+			},
+			wantErr: errors.New(LATTICE_UNSUPPORTED_MULTIPLE_TLS_HOSTNAMES),
+		},
+	}
+
+	for _, tt := range tests {
+		fmt.Printf("Testing >>> %v\n", tt.name)
+
+		tlsRoute := &gateway_api_v1alpha2.TLSRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service1",
+				Namespace: "default",
+			},
+			Spec: gateway_api_v1alpha2.TLSRouteSpec{
+				CommonRouteSpec: gateway_api.CommonRouteSpec{
+					ParentRefs: []gateway_api.ParentReference{
+						{
+							Name: "mesh1",
+						},
+					},
+				},
+				Hostnames: tt.hostnames,
+				Rules:     tt.rules,
+			},
+		}
+
+		httpRoute, err := translateTLSRoute(tlsRoute)
+
+		if tt.wantErr != nil {
+			assert.Equal(t, tt.wantErr, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tlsRoute.Name, httpRoute.Name)
+		assert.Equal(t, tlsRoute.Namespace, httpRoute.Namespace)
+		assert.Equal(t, tlsRoute.Spec.ParentRefs, httpRoute.Spec.ParentRefs)
+		assert.Equal(t, tt.hostnames, httpRoute.Spec.Hostnames)
+		assert.Equal(t, 1, len(httpRoute.Spec.Rules))
+		// the rule is the catch-all rule, i.e. the listener default action
+		assert.Nil(t, httpRoute.Spec.Rules[0].Matches)
+		assert.Equal(t, tt.expectedBackendRefs, httpRoute.Spec.Rules[0].BackendRefs)
+	}
+}
@@ -6,6 +6,9 @@ const (
 	GatewayEventReasonFailedBuildModel   = "FailedBuildModel"
 	GatewayEventReasonFailedDeployModel  = "FailedDeployModel"
 
+	// Route events, shared by all route kinds
+	RouteEventReasonNotAllowedByListeners = "NotAllowedByListeners"
+
 	// HTTPRoute events
 	HTTPRouteeventReasonReconcile         = "Reconcile"
 	HTTPRouteeventReasonDeploySucceed     = "DeploySucceed"
@@ -13,19 +16,44 @@ const (
 	HTTPRouteEventReasonFailedBuildModel  = "FailedBuildModel"
 	HTTPRouteEventReasonFailedDeployModel = "FailedDeployModel"
 	HTTPRouteEventReasonRetryReconcile    = "Retry-Reconcile"
+	HTTPRouteEventReasonConflicted        = "Conflicted"
+
+	// GRPCRoute events
+	GRPCRouteEventReasonReconcile          = "Reconcile"
+	GRPCRouteEventReasonDeploySucceed      = "DeploySucceed"
+	GRPCRouteEventReasonFailedAddFinalizer = "FailedAddFinalizer"
+	GRPCRouteEventReasonFailedBuildModel   = "FailedBuildModel"
+	GRPCRouteEventReasonFailedDeployModel  = "FailedDeployModel"
+	GRPCRouteEventReasonRetryReconcile     = "Retry-Reconcile"
+	GRPCRouteEventReasonConflicted         = "Conflicted"
+
+	// TLSRoute events
+	TLSRouteEventReasonReconcile          = "Reconcile"
+	TLSRouteEventReasonDeploySucceed      = "DeploySucceed"
+	TLSRouteEventReasonFailedAddFinalizer = "FailedAddFinalizer"
+	TLSRouteEventReasonFailedBuildModel   = "FailedBuildModel"
+	TLSRouteEventReasonFailedDeployModel  = "FailedDeployModel"
+	TLSRouteEventReasonRetryReconcile     = "Retry-Reconcile"
+	TLSRouteEventReasonConflicted         = "Conflicted"
 
 	// Service events
-	ServiceEventReasonFailedAddFinalizer = "FailedAddFinalizer"
-	ServiceEventReasonFailedBuildModel   = "FailedBuildModel"
-	ServiceEventReasonFailedDeployModel  = "FailedDeployModel"
+	ServiceEventReasonFailedAddFinalizer     = "FailedAddFinalizer"
+	ServiceEventReasonFailedBuildModel       = "FailedBuildModel"
+	ServiceEventReasonFailedDeployModel      = "FailedDeployModel"
+	ServiceEventReasonFailedRegisterTarget   = "FailedRegisterTarget"
+	ServiceEventReasonFailedDeregisterTarget = "FailedDeregisterTarget"
 
 	// ServiceExport events
 	ServiceExportEventReasonFailedAddFinalizer = "FailedAddFinalizer"
 	ServiceExportEventReasonFailedBuildModel   = "FailedBuildModel"
 	ServiceExportEventReasonFailedDeployModel  = "FailedDeployModel"
+	ServiceExportEventReasonInvalidWeight      = "InvalidWeight"
 
 	// ServiceImport events
 	ServiceImportEventReasonFailedAddFinalizer = "FailedAddFinalizer"
 	ServiceImportEventReasonFailedBuildModel   = "FailedBuildModel"
 	ServiceImportEventReasonFailedDeployModel  = "FailedDeployModel"
+	ServiceImportEventReasonResolved           = "Resolved"
+	ServiceImportEventReasonNotResolved        = "NotResolved"
+	ServiceImportEventReasonFailedResolve      = "FailedResolve"
 )
@@ -0,0 +1,25 @@
+package k8s
+
+import (
+	"regexp"
+
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+)
+
+// lambdaFunctionARNRegexp matches unqualified and qualified, i.e. version or alias, Lambda function ARNs,
+// the same as the validation of the LambdaTarget CRD
+var lambdaFunctionARNRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:lambda:[a-z0-9-]+:[0-9]{12}:function:[a-zA-Z0-9_-]+(:[a-zA-Z0-9_$-]+)?$`)
+
+// IsLambdaTargetBackendRef returns whether the backendRef refers to a LambdaTarget, the group must be set
+// since backendRefs default to the core group
+func IsLambdaTargetBackendRef(backendRef gateway_api.BackendObjectReference) bool {
+	return backendRef.Group != nil && string(*backendRef.Group) == anv1alpha1.GroupVersion.Group &&
+		backendRef.Kind != nil && string(*backendRef.Kind) == anv1alpha1.LambdaTargetKind
+}
+
+// IsValidLambdaFunctionARN returns whether arn is a Lambda function ARN lattice can register as target
+func IsValidLambdaFunctionARN(arn string) bool {
+	return lambdaFunctionARNRegexp.MatchString(arn)
+}
@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PodReadinessGateConditionType is the readiness gate of pods which wait for their lattice targets to be healthy
+	PodReadinessGateConditionType corev1.PodConditionType = "application-networking.k8s.aws/pod-readiness-gate"
+
+	PodReadinessGateReasonTargetHealthy    = "LatticeTargetHealthy"
+	PodReadinessGateReasonTargetNotHealthy = "LatticeTargetNotHealthy"
+	PodReadinessGateReasonTargetNotFound   = "LatticeTargetNotFound"
+)
+
+// HasPodReadinessGate returns whether the pod carries the lattice readiness gate
+func HasPodReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == PodReadinessGateConditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPodCondition returns the condition of the pod with conditionType, nil if the pod has none
+func GetPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetPodCondition adds or updates the condition of the pod, the transition time only changes with the status
+func SetPodCondition(pod *corev1.Pod, condition corev1.PodCondition) {
+	existing := GetPodCondition(pod, condition.Type)
+	if existing == nil {
+		condition.LastTransitionTime = metav1.Now()
+		pod.Status.Conditions = append(pod.Status.Conditions, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Status = condition.Status
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	existing.LastProbeTime = condition.LastProbeTime
+}
@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	ServiceImportGroup = "multicluster.x-k8s.io"
+)
+
+// IsBackendRefPermitted returns whether a route of routeKind in routeNamespace may refer to backendRef.
+// A reference into another namespace needs a ReferenceGrant in that namespace. Cross-namespace parentRefs
+// are governed by the AllowedRoutes of the gateway listeners instead
+func IsBackendRefPermitted(ctx context.Context, k8sClient client.Client, routeKind string, routeNamespace string,
+	backendRef gateway_api.BackendObjectReference) bool {
+	if backendRef.Namespace == nil || string(*backendRef.Namespace) == routeNamespace {
+		return true
+	}
+
+	grants := &gateway_api.ReferenceGrantList{}
+	if err := k8sClient.List(ctx, grants, client.InNamespace(string(*backendRef.Namespace))); err != nil {
+		glog.V(2).Infof("Failed to list ReferenceGrants in namespace %s, err %v\n", *backendRef.Namespace, err)
+		return false
+	}
+
+	for _, grant := range grants.Items {
+		if IsReferenceGrantFrom(grant, routeKind, routeNamespace) && isReferenceGrantTo(grant, backendRef) {
+			return true
+		}
+	}
+
+	glog.V(6).Infof("%s in namespace %s is not permitted to refer to %v\n", routeKind, routeNamespace, backendRef)
+	return false
+}
+
+// IsReferenceGrantFrom returns whether the grant admits references from routes of routeKind in routeNamespace
+func IsReferenceGrantFrom(grant gateway_api.ReferenceGrant, routeKind string, routeNamespace string) bool {
+	for _, from := range grant.Spec.From {
+		if string(from.Group) == gateway_api.GroupName && string(from.Kind) == routeKind &&
+			string(from.Namespace) == routeNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+func isReferenceGrantTo(grant gateway_api.ReferenceGrant, backendRef gateway_api.BackendObjectReference) bool {
+	kind := "Service"
+	if backendRef.Kind != nil {
+		kind = string(*backendRef.Kind)
+	}
+	group := ""
+	if backendRef.Group != nil {
+		group = string(*backendRef.Group)
+	} else if kind == "ServiceImport" {
+		group = ServiceImportGroup
+	}
+
+	for _, to := range grant.Spec.To {
+		if string(to.Group) != group || string(to.Kind) != kind {
+			continue
+		}
+		if to.Name == nil || *to.Name == backendRef.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	HTTPRouteKind = "HTTPRoute"
+	GRPCRouteKind = "GRPCRoute"
+	TLSRouteKind  = "TLSRoute"
+
+	// RouteReasonConflicted is the Accepted reason of a route whose name is taken by a route of another kind
+	RouteReasonConflicted gateway_api.RouteConditionReason = "Conflicted"
+	// RouteReasonUnsupportedHostnames is the Accepted reason of a TLSRoute with more than one hostname
+	RouteReasonUnsupportedHostnames gateway_api.RouteConditionReason = "UnsupportedHostnames"
+	// RouteReasonPending is the Accepted reason of a route whose model build failed and is retried
+	RouteReasonPending gateway_api.RouteConditionReason = "Pending"
+)
+
+// RouteConflictError is the error of a route whose name and namespace are taken by an older route of another kind.
+// The lattice service, and the datastore entries of the service and target groups, are keyed by the route name
+// and namespace only, so only one route of a name can be served
+type RouteConflictError struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+func (e *RouteConflictError) Error() string {
+	return fmt.Sprintf("%s %s-%s of the same name already exists", e.Kind, e.Name, e.Namespace)
+}
+
+// RouteInfo is the kind-agnostic view of a Gateway API route which is used when walking
+// routes for gateway status and event fan-out
+type RouteInfo struct {
+	Kind              string
+	Name              string
+	Namespace         string
+	DeletionTimestamp *metav1.Time
+	Annotations       map[string]string
+	ParentRefs        []gateway_api.ParentReference
+	BackendRefs       []gateway_api.BackendRef
+}
+
+// NewRouteInfo returns the RouteInfo of a HTTPRoute, GRPCRoute or TLSRoute object
+func NewRouteInfo(obj client.Object) (RouteInfo, bool) {
+	switch route := obj.(type) {
+	case *gateway_api.HTTPRoute:
+		info := RouteInfo{
+			Kind:              HTTPRouteKind,
+			Name:              route.Name,
+			Namespace:         route.Namespace,
+			DeletionTimestamp: route.DeletionTimestamp,
+			Annotations:       route.Annotations,
+			ParentRefs:        route.Spec.ParentRefs,
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				info.BackendRefs = append(info.BackendRefs, backendRef.BackendRef)
+			}
+		}
+		return info, true
+	case *gateway_api_v1alpha2.GRPCRoute:
+		info := RouteInfo{
+			Kind:              GRPCRouteKind,
+			Name:              route.Name,
+			Namespace:         route.Namespace,
+			DeletionTimestamp: route.DeletionTimestamp,
+			Annotations:       route.Annotations,
+			ParentRefs:        route.Spec.ParentRefs,
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				info.BackendRefs = append(info.BackendRefs, backendRef.BackendRef)
+			}
+		}
+		return info, true
+	case *gateway_api_v1alpha2.TLSRoute:
+		info := RouteInfo{
+			Kind:              TLSRouteKind,
+			Name:              route.Name,
+			Namespace:         route.Namespace,
+			DeletionTimestamp: route.DeletionTimestamp,
+			Annotations:       route.Annotations,
+			ParentRefs:        route.Spec.ParentRefs,
+		}
+		for _, rule := range route.Spec.Rules {
+			info.BackendRefs = append(info.BackendRefs, rule.BackendRefs...)
+		}
+		return info, true
+	}
+	return RouteInfo{}, false
+}
+
+// GetRouteStatus returns the status of a HTTPRoute, GRPCRoute or TLSRoute object, which is updated in place
+func GetRouteStatus(obj client.Object) *gateway_api.RouteStatus {
+	switch route := obj.(type) {
+	case *gateway_api.HTTPRoute:
+		return &route.Status.RouteStatus
+	case *gateway_api_v1alpha2.GRPCRoute:
+		return &route.Status.RouteStatus
+	case *gateway_api_v1alpha2.TLSRoute:
+		return &route.Status.RouteStatus
+	}
+	return nil
+}
+
+// ListRoutes lists the routes of the given kinds, all supported kinds are listed when none is given
+func ListRoutes(ctx context.Context, k8sClient client.Client, kinds ...string) []RouteInfo {
+	var routes []RouteInfo
+
+	if len(kinds) == 0 {
+		kinds = []string{HTTPRouteKind, GRPCRouteKind, TLSRouteKind}
+	}
+
+	for _, kind := range kinds {
+		switch kind {
+		case HTTPRouteKind:
+			httpRouteList := &gateway_api.HTTPRouteList{}
+			if err := k8sClient.List(ctx, httpRouteList); err != nil {
+				glog.V(6).Infof("ListRoutes: failed to list HTTPRoutes, err %v\n", err)
+				continue
+			}
+			for i := range httpRouteList.Items {
+				info, _ := NewRouteInfo(&httpRouteList.Items[i])
+				routes = append(routes, info)
+			}
+		case GRPCRouteKind:
+			grpcRouteList := &gateway_api_v1alpha2.GRPCRouteList{}
+			if err := k8sClient.List(ctx, grpcRouteList); err != nil {
+				glog.V(6).Infof("ListRoutes: failed to list GRPCRoutes, err %v\n", err)
+				continue
+			}
+			for i := range grpcRouteList.Items {
+				info, _ := NewRouteInfo(&grpcRouteList.Items[i])
+				routes = append(routes, info)
+			}
+		case TLSRouteKind:
+			tlsRouteList := &gateway_api_v1alpha2.TLSRouteList{}
+			if err := k8sClient.List(ctx, tlsRouteList); err != nil {
+				glog.V(6).Infof("ListRoutes: failed to list TLSRoutes, err %v\n", err)
+				continue
+			}
+			for i := range tlsRouteList.Items {
+				info, _ := NewRouteInfo(&tlsRouteList.Items[i])
+				routes = append(routes, info)
+			}
+		}
+	}
+
+	return routes
+}
+
+// ParentGatewayName returns the namespaced name of the gateway a parentRef points to
+func ParentGatewayName(routeNamespace string, parentRef gateway_api.ParentReference) types.NamespacedName {
+	gwNamespace := routeNamespace
+	if parentRef.Namespace != nil {
+		gwNamespace = string(*parentRef.Namespace)
+	}
+	return types.NamespacedName{
+		Namespace: gwNamespace,
+		Name:      string(parentRef.Name),
+	}
+}
+
+// FindConflictingRoute returns the conflict with a route of another kind of the same name and namespace which was
+// created before the route, nil when there is none. Routes created at the same time are ordered by kind. A route
+// being deleted still conflicts, since its cleanup deletes the lattice service of the name
+func FindConflictingRoute(ctx context.Context, k8sClient client.Client, obj client.Object) *RouteConflictError {
+	route, ok := NewRouteInfo(obj)
+	if !ok {
+		return nil
+	}
+	created := obj.GetCreationTimestamp()
+
+	for _, kind := range []string{HTTPRouteKind, GRPCRouteKind, TLSRouteKind} {
+		if kind == route.Kind {
+			continue
+		}
+
+		var other client.Object
+		switch kind {
+		case HTTPRouteKind:
+			other = &gateway_api.HTTPRoute{}
+		case GRPCRouteKind:
+			other = &gateway_api_v1alpha2.GRPCRoute{}
+		case TLSRouteKind:
+			other = &gateway_api_v1alpha2.TLSRoute{}
+		}
+		if err := k8sClient.Get(ctx, NamespacedName(obj), other); err != nil {
+			continue
+		}
+
+		otherCreated := other.GetCreationTimestamp()
+		if otherCreated.Before(&created) || (otherCreated.Equal(&created) && kind < route.Kind) {
+			return &RouteConflictError{
+				Kind:      kind,
+				Name:      route.Name,
+				Namespace: route.Namespace,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gateway_api "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/aws/aws-application-networking-k8s/pkg/config"
+)
+
+const (
+	// RouteReasonNoMatchingParent is used when the sectionName of a parentRef matches no gateway listener
+	RouteReasonNoMatchingParent gateway_api.RouteConditionReason = "NoMatchingParent"
+)
+
+// RouteParent is the outcome of validating one parentRef of a route against its gateway
+type RouteParent struct {
+	ParentRef gateway_api.ParentReference
+	Gateway   *gateway_api.Gateway
+	// Listeners are the gateway listeners the route attaches to, empty when not accepted
+	Listeners []gateway_api.Listener
+	Accepted  bool
+	Reason    gateway_api.RouteConditionReason
+	Message   string
+}
+
+// ValidateRouteParents validates each parentRef of the route independently. ParentRefs which do not
+// point to a gateway of the lattice gateway class are left out, their status belongs to other controllers
+func ValidateRouteParents(ctx context.Context, k8sClient client.Client, route RouteInfo) []RouteParent {
+	var parents []RouteParent
+
+	for _, parentRef := range route.ParentRefs {
+		if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+			glog.V(6).Infof("Ignore parentRef %v of %s %s-%s, not a gateway\n",
+				parentRef, route.Kind, route.Name, route.Namespace)
+			continue
+		}
+
+		gw := &gateway_api.Gateway{}
+		gwName := ParentGatewayName(route.Namespace, parentRef)
+		if err := k8sClient.Get(ctx, gwName, gw); err != nil {
+			glog.V(6).Infof("Ignore parentRef %v of %s %s-%s, gateway not found, err %v\n",
+				parentRef, route.Kind, route.Name, route.Namespace, err)
+			continue
+		}
+
+		gwClass := &gateway_api.GatewayClass{}
+		gwClassName := types.NamespacedName{
+			Name: string(gw.Spec.GatewayClassName),
+		}
+		if err := k8sClient.Get(ctx, gwClassName, gwClass); err != nil {
+			glog.V(6).Infof("Ignore parentRef %v of %s %s-%s, gatewayclass not found, err %v\n",
+				parentRef, route.Kind, route.Name, route.Namespace, err)
+			continue
+		}
+
+		if gwClass.Spec.ControllerName != config.LatticeGatewayControllerName {
+			glog.V(6).Infof("Ignore parentRef %v of %s %s-%s, not a aws-vpc-lattice gateway\n",
+				parentRef, route.Kind, route.Name, route.Namespace)
+			continue
+		}
+
+		parents = append(parents, NewRouteParent(ctx, k8sClient, gw, parentRef, route.Kind, route.Namespace))
+	}
+
+	return parents
+}
+
+// NewRouteParent resolves the listeners of gw which a route of routeKind in routeNamespace attaches to
+// through parentRef. A parentRef without sectionName attaches to every listener of the gateway which
+// allows the route
+func NewRouteParent(ctx context.Context, k8sClient client.Client, gw *gateway_api.Gateway,
+	parentRef gateway_api.ParentReference, routeKind string, routeNamespace string) RouteParent {
+	parent := RouteParent{
+		ParentRef: parentRef,
+		Gateway:   gw,
+	}
+
+	if parentRef.SectionName == nil {
+		return newRouteParentOfAllListeners(ctx, k8sClient, parent, routeKind, routeNamespace)
+	}
+
+	var listeners []gateway_api.Listener
+	for _, listener := range gw.Spec.Listeners {
+		if listener.Name == *parentRef.SectionName {
+			listeners = append(listeners, listener)
+		}
+	}
+
+	if len(listeners) == 0 {
+		parent.Reason = RouteReasonNoMatchingParent
+		parent.Message = fmt.Sprintf("No listener of gateway %s-%s matches parentRef %v",
+			gw.Name, gw.Namespace, parentRef.Name)
+		return parent
+	}
+
+	for _, listener := range listeners {
+		if !ListenerAllowsRouteKind(listener, routeKind) {
+			parent.Reason = gateway_api.RouteReasonNotAllowedByListeners
+			parent.Message = fmt.Sprintf("Listener %s of gateway %s-%s does not allow %s",
+				listener.Name, gw.Name, gw.Namespace, routeKind)
+			return parent
+		}
+
+		// gw is the one the parentRef resolves to, whose namespace may be unset when built in memory
+		gwNamespace := ParentGatewayName(routeNamespace, parentRef).Namespace
+		if !ListenerAllowsRouteNamespace(ctx, k8sClient, listener, gwNamespace, routeNamespace) {
+			parent.Reason = gateway_api.RouteReasonNotAllowedByListeners
+			parent.Message = fmt.Sprintf("Listener %s of gateway %s-%s does not allow routes from namespace %s",
+				listener.Name, gw.Name, gw.Namespace, routeNamespace)
+			return parent
+		}
+	}
+
+	parent.Listeners = listeners
+	parent.Accepted = true
+	parent.Reason = gateway_api.RouteReasonAccepted
+	return parent
+}
+
+// newRouteParentOfAllListeners attaches the route to the listeners of the gateway which allow its kind
+// and namespace, the parent is accepted when there is at least one
+func newRouteParentOfAllListeners(ctx context.Context, k8sClient client.Client, parent RouteParent,
+	routeKind string, routeNamespace string) RouteParent {
+	gw := parent.Gateway
+	if len(gw.Spec.Listeners) == 0 {
+		parent.Reason = RouteReasonNoMatchingParent
+		parent.Message = fmt.Sprintf("Gateway %s-%s has no listener", gw.Name, gw.Namespace)
+		return parent
+	}
+
+	gwNamespace := ParentGatewayName(routeNamespace, parent.ParentRef).Namespace
+	var listeners []gateway_api.Listener
+	for _, listener := range gw.Spec.Listeners {
+		if !ListenerAllowsRouteKind(listener, routeKind) {
+			glog.V(6).Infof("Listener %s of gateway %s-%s does not allow %s\n",
+				listener.Name, gw.Name, gw.Namespace, routeKind)
+			continue
+		}
+		if !ListenerAllowsRouteNamespace(ctx, k8sClient, listener, gwNamespace, routeNamespace) {
+			glog.V(6).Infof("Listener %s of gateway %s-%s does not allow routes from namespace %s\n",
+				listener.Name, gw.Name, gw.Namespace, routeNamespace)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	if len(listeners) == 0 {
+		parent.Reason = gateway_api.RouteReasonNotAllowedByListeners
+		parent.Message = fmt.Sprintf("No listener of gateway %s-%s allows %s from namespace %s",
+			gw.Name, gw.Namespace, routeKind, routeNamespace)
+		return parent
+	}
+
+	parent.Listeners = listeners
+	parent.Accepted = true
+	parent.Reason = gateway_api.RouteReasonAccepted
+	return parent
+}
+
+// ListenerAllowsRouteKind returns whether the listener allows routes of the kind to attach. When the listener
+// does not restrict kinds, a TLS listener allows TLSRoute and any other listener HTTPRoute and GRPCRoute
+func ListenerAllowsRouteKind(listener gateway_api.Listener, routeKind string) bool {
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		for _, kind := range ListenerDefaultRouteKinds(listener) {
+			if kind == routeKind {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, routeGroupKind := range listener.AllowedRoutes.Kinds {
+		if string(routeGroupKind.Kind) == routeKind {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenerDefaultRouteKinds returns the route kinds the listener allows when it does not restrict kinds
+func ListenerDefaultRouteKinds(listener gateway_api.Listener) []string {
+	if listener.Protocol == gateway_api.TLSProtocolType {
+		return []string{TLSRouteKind}
+	}
+	return []string{HTTPRouteKind, GRPCRouteKind}
+}
+
+// ListenerAllowsRouteNamespace returns whether the listener of a gateway in gwNamespace allows routes in
+// routeNamespace to attach. Only routes in the namespace of the gateway are allowed when the listener
+// does not say otherwise
+func ListenerAllowsRouteNamespace(ctx context.Context, k8sClient client.Client, listener gateway_api.Listener,
+	gwNamespace string, routeNamespace string) bool {
+	from := gateway_api.NamespacesFromSame
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil &&
+		listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+
+	switch from {
+	case gateway_api.NamespacesFromAll:
+		return true
+	case gateway_api.NamespacesFromSame:
+		return routeNamespace == gwNamespace
+	case gateway_api.NamespacesFromSelector:
+		if listener.AllowedRoutes.Namespaces.Selector == nil {
+			return false
+		}
+		selector, err := metav1.LabelSelectorAsSelector(listener.AllowedRoutes.Namespaces.Selector)
+		if err != nil {
+			glog.V(2).Infof("Invalid namespace selector of listener %s, err %v\n", listener.Name, err)
+			return false
+		}
+
+		ns := &corev1.Namespace{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: routeNamespace}, ns); err != nil {
+			glog.V(2).Infof("Failed to get namespace %s, err %v\n", routeNamespace, err)
+			return false
+		}
+		return selector.Matches(labels.Set(ns.Labels))
+	}
+
+	return false
+}
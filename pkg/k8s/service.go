@@ -0,0 +1,8 @@
+package k8s
+
+const (
+	// annotations summarizing the health of the lattice targets of a service
+	ServiceTargetsHealthAnnotation  = "application-networking.k8s.aws/lattice-targets-health"
+	ServiceTargetsTotalAnnotation   = "application-networking.k8s.aws/lattice-targets-total"
+	ServiceTargetsHealthyAnnotation = "application-networking.k8s.aws/lattice-targets-healthy"
+)
@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+)
+
+const (
+	// ServiceExportWeightAnnotation sets the weight of this cluster, relative to the other clusters exporting the
+	// same service, for the routes of the importing clusters. Integer from 0 to 999, see DefaultServiceExportWeight
+	ServiceExportWeightAnnotation = "application-networking.k8s.aws/export-weight"
+	// DefaultServiceExportWeight is the weight of a cluster whose ServiceExport has no weight annotation
+	DefaultServiceExportWeight int64 = 1
+	// MaxServiceExportWeight is the maximum weight of a target group in a lattice rule
+	MaxServiceExportWeight int64 = 999
+
+	// ServiceExportConditionTargetsHealthy is True when all the lattice targets of the exported service are healthy
+	ServiceExportConditionTargetsHealthy mcs_api.ServiceExportConditionType = "LatticeTargetsHealthy"
+
+	ServiceExportReasonTargetsHealthy   = "TargetsHealthy"
+	ServiceExportReasonTargetsUnhealthy = "TargetsUnhealthy"
+	ServiceExportReasonNoTargets        = "NoTargets"
+
+	// ServiceExportConditionReady is True once the target group of the exported service is active and has
+	// registered targets
+	ServiceExportConditionReady mcs_api.ServiceExportConditionType = "Ready"
+
+	ServiceExportReasonValid                = "Valid"
+	ServiceExportReasonServiceNotFound      = "ServiceNotFound"
+	ServiceExportReasonServiceHeadless      = "ServiceHeadless"
+	ServiceExportReasonNoConflict           = "NoConflict"
+	ServiceExportReasonPortsConflict        = "PortsConflict"
+	ServiceExportReasonProtocolConflict     = "ProtocolConflict"
+	ServiceExportReasonExported             = "Exported"
+	ServiceExportReasonInvalid              = "Invalid"
+	ServiceExportReasonTargetGroupNotActive = "TargetGroupNotActive"
+)
+
+// SetServiceExportCondition adds or updates the condition of the serviceexport, the transition time only
+// changes with the status. Returns whether the condition changed
+func SetServiceExportCondition(srvExport *mcs_api.ServiceExport, conditionType mcs_api.ServiceExportConditionType,
+	status corev1.ConditionStatus, reason string, message string) bool {
+	for i := range srvExport.Status.Conditions {
+		condition := &srvExport.Status.Conditions[i]
+		if condition.Type != conditionType {
+			continue
+		}
+
+		if condition.Status == status && stringValue(condition.Reason) == reason && stringValue(condition.Message) == message {
+			return false
+		}
+		if condition.Status != status {
+			now := metav1.Now()
+			condition.LastTransitionTime = &now
+		}
+		condition.Status = status
+		condition.Reason = &reason
+		condition.Message = &message
+		return true
+	}
+
+	now := metav1.Now()
+	srvExport.Status.Conditions = append(srvExport.Status.Conditions, mcs_api.ServiceExportCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: &now,
+		Reason:             &reason,
+		Message:            &message,
+	})
+	return true
+}
+
+// RemoveServiceExportCondition removes the condition of the serviceexport. Returns whether the condition was present
+func RemoveServiceExportCondition(srvExport *mcs_api.ServiceExport, conditionType mcs_api.ServiceExportConditionType) bool {
+	for i := range srvExport.Status.Conditions {
+		if srvExport.Status.Conditions[i].Type == conditionType {
+			srvExport.Status.Conditions = append(srvExport.Status.Conditions[:i], srvExport.Status.Conditions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceExportWeight returns the weight of the ServiceExportWeightAnnotation, nil when the annotation is not set
+func ServiceExportWeight(srvExport *mcs_api.ServiceExport) (*int64, error) {
+	value, ok := srvExport.Annotations[ServiceExportWeightAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	weight, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || weight < 0 || weight > MaxServiceExportWeight {
+		return nil, fmt.Errorf("invalid %s %q, expecting an integer from 0 to %d",
+			ServiceExportWeightAnnotation, value, MaxServiceExportWeight)
+	}
+	return &weight, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
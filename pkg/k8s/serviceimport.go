@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	mcs_api "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+)
+
+const (
+	// ServiceImportReadyAnnotation is "True" when lattice target groups exported for the ServiceImport are found and
+	// "False" otherwise. It stands in for a Ready condition, which mcs-api v1alpha1 ServiceImportStatus does not have
+	ServiceImportReadyAnnotation = "application-networking.k8s.aws/service-import-ready"
+
+	// ServiceImportVpcAnnotation restricts the ServiceImport to the target group exported by the cluster of the vpc
+	ServiceImportVpcAnnotation = "multicluster.x-k8s.io/aws-vpc"
+
+	// ServiceImportClusterWeightsAnnotation shows the weight of each cluster exporting the service, as comma separated
+	// vpc=weight. Traffic of the routes using the ServiceImport is split across the clusters by these weights
+	ServiceImportClusterWeightsAnnotation = "application-networking.k8s.aws/cluster-weights"
+
+	// ServiceImportDiscoveredLabel marks the ServiceImports created by the discovery of exported target groups,
+	// only those are updated and deleted by it
+	ServiceImportDiscoveredLabel = "application-networking.k8s.aws/service-import-discovered"
+)
+
+// IsServiceImportNotReady returns whether the ServiceImport was resolved without any exported target group,
+// a ServiceImport which was not reconciled yet is not considered not ready
+func IsServiceImportNotReady(serviceImport *mcs_api.ServiceImport) bool {
+	return serviceImport.Annotations[ServiceImportReadyAnnotation] == "False"
+}
+
+// IsServiceImportDiscovered returns whether the ServiceImport was created by the discovery of exported target groups
+func IsServiceImportDiscovered(serviceImport *mcs_api.ServiceImport) bool {
+	return serviceImport.Labels[ServiceImportDiscoveredLabel] == "true"
+}
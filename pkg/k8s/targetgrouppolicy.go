@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	anv1alpha1 "github.com/aws/aws-application-networking-k8s/pkg/apis/applicationnetworking/v1alpha1"
+)
+
+// ListTargetGroupPolicies returns the TargetGroupPolicies targeting the service, oldest first. Only the oldest
+// one is applied, the others conflict with it
+func ListTargetGroupPolicies(ctx context.Context, k8sClient client.Client, svcName types.NamespacedName) []anv1alpha1.TargetGroupPolicy {
+	policies := &anv1alpha1.TargetGroupPolicyList{}
+	if err := k8sClient.List(ctx, policies, client.InNamespace(svcName.Namespace)); err != nil {
+		glog.V(6).Infof("Failed to list TargetGroupPolicies in namespace %s, err %v\n", svcName.Namespace, err)
+		return nil
+	}
+
+	var matched []anv1alpha1.TargetGroupPolicy
+	for _, policy := range policies.Items {
+		if IsTargetGroupPolicyTargetService(&policy) && string(policy.Spec.TargetRef.Name) == svcName.Name {
+			matched = append(matched, policy)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		ti, tj := matched[i].CreationTimestamp, matched[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched
+}
+
+// GetTargetGroupPolicy returns the TargetGroupPolicy applied to the target groups of the service, nil if none
+func GetTargetGroupPolicy(ctx context.Context, k8sClient client.Client, svcName types.NamespacedName) *anv1alpha1.TargetGroupPolicy {
+	policies := ListTargetGroupPolicies(ctx, k8sClient, svcName)
+	if len(policies) == 0 {
+		return nil
+	}
+	return &policies[0]
+}
+
+// IsTargetGroupPolicyTargetService returns whether the targetRef of the policy is a Service in its namespace
+func IsTargetGroupPolicyTargetService(policy *anv1alpha1.TargetGroupPolicy) bool {
+	targetRef := policy.Spec.TargetRef
+	if targetRef == nil || targetRef.Kind != "Service" || (targetRef.Group != "" && targetRef.Group != "core") {
+		return false
+	}
+	return targetRef.Namespace == nil || string(*targetRef.Namespace) == policy.Namespace
+}
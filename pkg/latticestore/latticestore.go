@@ -82,6 +82,17 @@ type TargetGroup struct {
 	VpcID           string
 	ByServiceExport bool // triggered by K8S serviceexport object
 	ByBackendRef    bool // triggered by backend ref which points to service
+	// Clusters are the target groups exported by each cluster for a ServiceImport
+	Clusters []ClusterTargetGroup
+}
+
+// ClusterTargetGroup is the target group exported by the cluster of a vpc for a ServiceImport
+type ClusterTargetGroup struct {
+	VpcID string
+	ARN   string
+	ID    string
+	// Weight is relative to the other clusters exporting the service
+	Weight int64
 }
 
 type Target struct {
@@ -296,6 +307,21 @@ func TargetGroupName(name string, namespace string) string {
 	return fmt.Sprintf("k8s-%0.20s-%0.20s", name, namespace)
 }
 
+// TargetGroupPortName is the name of the target group of a service port, for backendRefs with a port.
+// Without a port, i.e. 0, it is the name of the target group of the whole service
+func TargetGroupPortName(name string, namespace string, port int32) string {
+	if port == 0 {
+		return TargetGroupName(name, namespace)
+	}
+	return fmt.Sprintf("k8s-%0.20s-%0.20s-%d", name, namespace, port)
+}
+
+// LambdaTargetGroupName is the name of the target group of a LambdaTarget, the suffix tells it apart from
+// the target group of a service of the same name
+func LambdaTargetGroupName(name string, namespace string) string {
+	return fmt.Sprintf("k8s-%0.20s-%0.20s-lambda", name, namespace)
+}
+
 func TargetGroupLongName(k8sName string, routeName string, vpcid string) string {
 	return fmt.Sprintf("k8s-%0.40s-%0.20s-%0.20s", k8sName, routeName, vpcid)
 }
@@ -392,6 +418,25 @@ func (ds *LatticeDataStore) SetTargetGroupByBackendRef(name string, routeName st
 
 }
 
+// SetTargetGroupClusters replaces the target groups exported by each cluster for a ServiceImport target group
+func (ds *LatticeDataStore) SetTargetGroupClusters(name string, routeName string, isServiceImport bool, clusters []ClusterTargetGroup) error {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	targetGroupKey := TargetGroupKey{
+		Name:            name,
+		RouteName:       routeName,
+		IsServiceImport: isServiceImport,
+	}
+
+	tg, ok := ds.targetGroups[targetGroupKey]
+	if !ok {
+		return errors.New(DATASTORE_TG_NOT_EXIST)
+	}
+	tg.Clusters = clusters
+	return nil
+}
+
 func (ds *LatticeDataStore) DelTargetGroup(name string, routeName string, isServiceImport bool) error {
 	ds.lock.Lock()
 	defer ds.lock.Unlock()
@@ -443,6 +488,9 @@ func (ds *LatticeDataStore) GetTargetGroup(name string, routeName string, isServ
 }
 
 func (ds *LatticeDataStore) GetTargetGroupsByTG(name string) []TargetGroup {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
 	tgs := make([]TargetGroup, 0)
 
 	for _, tg := range ds.targetGroups {
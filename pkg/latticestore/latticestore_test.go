@@ -215,6 +215,19 @@ func Test_TargetGroup(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, true, tg.ByServiceExport)
 
+	clusters := []ClusterTargetGroup{
+		{VpcID: "vpc-1", ARN: "arn-1", ID: "tg-1", Weight: 80},
+		{VpcID: "vpc-2", ARN: "arn-2", ID: "tg-2", Weight: 20},
+	}
+	err = inputDataStore.SetTargetGroupClusters(tgName, "", serviceImport, clusters)
+	assert.Nil(t, err)
+	tg, err = inputDataStore.GetTargetGroup(tgName, "", serviceImport)
+	assert.Nil(t, err)
+	assert.Equal(t, clusters, tg.Clusters)
+
+	err = inputDataStore.SetTargetGroupClusters(tgName, "", K8SService, clusters)
+	assert.Equal(t, errors.New(DATASTORE_TG_NOT_EXIST), err)
+
 	// Verify GetTargetGroup will fail if it is K8SService
 	_, err = inputDataStore.GetTargetGroup(tgName, "", K8SService)
 	assert.Equal(t, errors.New(DATASTORE_TG_NOT_EXIST), err)
@@ -273,6 +286,17 @@ func Test_TargetGroup(t *testing.T) {
 
 }
 
+func Test_TargetGroupPortName(t *testing.T) {
+	assert.Equal(t, TargetGroupName("svc", "ns"), TargetGroupPortName("svc", "ns", 0))
+	assert.Equal(t, "k8s-svc-ns-8080", TargetGroupPortName("svc", "ns", 8080))
+	assert.NotEqual(t, TargetGroupPortName("svc", "ns", 80), TargetGroupPortName("svc", "ns", 8080))
+}
+
+func Test_LambdaTargetGroupName(t *testing.T) {
+	assert.Equal(t, "k8s-fn-ns-lambda", LambdaTargetGroupName("fn", "ns"))
+	assert.NotEqual(t, TargetGroupName("fn", "ns"), LambdaTargetGroupName("fn", "ns"))
+}
+
 func Test_Listener(t *testing.T) {
 
 	ds := NewLatticeDataStore()
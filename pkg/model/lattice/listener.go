@@ -4,6 +4,16 @@ import (
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 )
 
+const (
+	// ListenerProtocolTLSPassthrough listeners forward TLS connections by SNI to TCP target groups without
+	// terminating them, they only have a default action
+	ListenerProtocolTLSPassthrough = "TLS_PASSTHROUGH"
+
+	// TLS modes of the gateway listener, an HTTPS listener terminates TLS and a TLS_PASSTHROUGH one does not
+	ListenerTLSModeTerminate   = "Terminate"
+	ListenerTLSModePassthrough = "Passthrough"
+)
+
 type Listener struct {
 	core.ResourceMeta `json:"-"`
 	Spec              ListenerSpec    `json:"spec"`
@@ -11,18 +21,18 @@ type Listener struct {
 }
 
 type ListenerSpec struct {
-	Name          string        `json:"name"`
-	Namespace     string        `json:"namespace"`
-	Port          int64         `json:"port"`
-	Protocol      string        `json:"protocol"`
-	DefaultAction DefaultAction `json:"defaultaction"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Port      int64  `json:"port"`
+	Protocol  string `json:"protocol"`
+	// DefaultAction is the action of the catch-all rule of the route
+	DefaultAction RuleAction `json:"defaultaction"`
+	// TLSMode is empty for listeners without TLS
+	TLSMode string `json:"tlsmode,omitempty"`
+	// CertificateARN is the custom certificate of an HTTPS listener, lattice keeps it on the service
+	CertificateARN string `json:"certificatearn,omitempty"`
 }
 
-type DefaultAction struct {
-	Is_Import               bool   `json:"isimport"`
-	BackendServiceName      string `json:"backendservicename"`
-	BackendServiceNamespace string `json:"backendservicenamespace"`
-}
 type ListenerStatus struct {
 	Name        string `json:"name"`
 	Namespace   string `json:"namespace"`
@@ -33,7 +43,7 @@ type ListenerStatus struct {
 	Protocol    string `json:"protocol"`
 }
 
-func NewListener(stack core.Stack, id string, port int64, protocol string, name string, namespace string, action DefaultAction) *Listener {
+func NewListener(stack core.Stack, id string, port int64, protocol string, name string, namespace string, action RuleAction) *Listener {
 
 	listener := &Listener{
 		ResourceMeta: core.NewResourceMeta(stack, "AWS::VPCServiceNetwork::Listener", id),
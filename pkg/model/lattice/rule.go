@@ -32,6 +32,8 @@ type RuleSpec struct {
 	PathMatchValue  string `json:"pathmatchvalue"`
 	PathMatchExact  bool   `json:"pathmatchexact"`
 	PathMatchPrefix bool   `json:"pathmatchprefix"`
+	// Method, empty matches any method
+	Method string `json:"method"`
 	// Header
 	NumOfHeaderMatches int `json:"numofheadermatches"`
 	MatchedHeaders     [MAX_NUM_OF_MATCHED_HEADERS]vpclattice.HeaderMatch
@@ -43,13 +45,17 @@ type RuleSpec struct {
 
 type RuleAction struct {
 	TargetGroups []*RuleTargetGroup `json:"ruletarget"`
+	// when set, lattice responds with this status code instead of forwarding to TargetGroups
+	FixedResponseStatusCode int64 `json:"fixedresponsestatuscode,omitempty"`
 }
 
 type RuleTargetGroup struct {
 	Name            string `json:"name"`
 	Namespace       string `json:"namespace"`
 	RouteName       string `json:"routename"`
+	Port            int32  `json:"port"`
 	IsServiceImport bool   `json:"isServiceImport"`
+	IsLambda        bool   `json:"isLambda"`
 	Weight          int64  `json:"weight"`
 }
 
@@ -1,17 +1,30 @@
 package lattice
 
 import (
+	"github.com/aws/aws-sdk-go/service/vpclattice"
+
 	"github.com/aws/aws-application-networking-k8s/pkg/model/core"
 )
 
 const (
 	K8SServiceNameKey        = "K8SServiceName"
 	K8SServiceNamespaceKey   = "K8SServiceNamespace"
+	K8SServicePortKey        = "K8SServicePort"
 	K8SParentRefTypeKey      = "K8SParentRefTypeKey"
 	K8SHTTPRouteNameKey      = "K8SHTTPRouteName"
 	K8SHTTPRouteNamespaceKey = "K8SHTTPRouteNamespace"
 	K8SServiceExportType     = "K8SServiceExportType"
 	K8SHTTPRouteType         = "K8SHTTPRouteType"
+	K8SGRPCRouteType         = "K8SGRPCRouteType"
+	K8STLSRouteType          = "K8STLSRouteType"
+	// K8SClusterVpcIDKey tags lambda target groups, which are not in a vpc, with the vpc of the cluster
+	K8SClusterVpcIDKey = "K8SClusterVpcID"
+	// K8SServiceExportPortsKey tags exported target groups with the comma separated ports of the k8s service,
+	// so that importing clusters know the ports without access to the service
+	K8SServiceExportPortsKey = "K8SServiceExportPorts"
+	// K8SServiceExportWeightKey tags exported target groups with the weight of the exporting cluster, relative to
+	// the other clusters exporting the same service
+	K8SServiceExportWeightKey = "K8SServiceExportWeight"
 )
 
 type TargetGroup struct {
@@ -35,12 +48,22 @@ type TargetGroupConfig struct {
 	VpcID           string `json:"vpcid"`
 	EKSClusterName  string `json:"eksclustername"`
 	IsServiceImport bool   `json:"serviceimport"`
+	// HealthCheckConfig is set by the TargetGroupPolicy of the k8s service, nil keeps the lattice defaults
+	HealthCheckConfig *vpclattice.HealthCheckConfig `json:"healthcheckconfig,omitempty"`
 	// the following fields are used for AWS resource tagging
 	IsServiceExport       bool   `json:"serviceexport"`
 	K8SServiceName        string `json:"k8sservice"`
 	K8SServiceNamespace   string `json:"k8sservicenamespace"`
+	K8SServicePort        int32  `json:"k8sserviceport"`
 	K8SHTTPRouteName      string `json:"k8shttproutename"`
 	K8SHTTPRouteNamespace string `json:"k8shttproutenamespace"`
+	// K8SRouteType is K8SHTTPRouteType, K8SGRPCRouteType or K8STLSRouteType, empty is treated as K8SHTTPRouteType
+	K8SRouteType string `json:"k8sroutetype"`
+	// K8SServiceExportPorts are the ports of the exported k8s service, only set with IsServiceExport
+	K8SServiceExportPorts []int32 `json:"k8sserviceexportports,omitempty"`
+	// K8SServiceExportWeight is the weight of the cluster for the exported k8s service, nil when not set on the
+	// ServiceExport. Only set with IsServiceExport
+	K8SServiceExportWeight *int64 `json:"k8sserviceexportweight,omitempty"`
 }
 
 type TargetGroupStatus struct {
@@ -52,6 +75,8 @@ type TargetGroupType string
 
 const (
 	TargetGroupTypeIP TargetGroupType = "IP"
+	// TargetGroupTypeLambda target groups have a Lambda function as only target and no config
+	TargetGroupTypeLambda TargetGroupType = "LAMBDA"
 )
 
 func NewTargetGroup(stack core.Stack, id string, spec TargetGroupSpec) *TargetGroup {
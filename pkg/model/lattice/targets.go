@@ -6,15 +6,27 @@ import (
 
 type Targets struct {
 	core.ResourceMeta `json:"-"`
-	Spec              TargetsSpec `json:"spec"`
+	Spec              TargetsSpec    `json:"spec"`
+	Status            *TargetsStatus `json:"status,omitempty"`
 }
 
 type TargetsSpec struct {
 	Name          string   `json:"name"`
 	Namespace     string   `json:"namespace"`
 	RouteName     string   `json:"routename"`
+	Port          int32    `json:"port"`
 	TargetGroupID string   `json:"targetgroupID"`
 	TargetIPList  []Target `json:"targetIPlist"`
+	// TerminatingTargetList are the targets of terminating endpoints which are still serving, they stay
+	// registered until their deregistration is driven by the targets manager
+	TerminatingTargetList []Target `json:"terminatingTargetList"`
+	IsLambda              bool     `json:"islambda"`
+}
+
+type TargetsStatus struct {
+	// PendingTargets are the stale targets lattice still lists until they finished draining, the targets
+	// are checked again later while there are any
+	PendingTargets int `json:"pendingTargets"`
 }
 
 type Target struct {
@@ -6,6 +6,11 @@ import (
 )
 
 func (env *Framework) NewGateway(name string, namespace string) *v1beta1.Gateway {
+	// the test routes are not necessarily in the namespace of the gateway
+	fromAll := v1beta1.NamespacesFromAll
+	allowedRoutes := &v1beta1.AllowedRoutes{
+		Namespaces: &v1beta1.RouteNamespaces{From: &fromAll},
+	}
 	gateway := New(
 		&v1beta1.Gateway{
 			ObjectMeta: metav1.ObjectMeta{
@@ -22,11 +27,13 @@ func (env *Framework) NewGateway(name string, namespace string) *v1beta1.Gateway
 						Name:     "http",
 						Protocol: v1beta1.HTTPProtocolType,
 						Port:     80,
+						AllowedRoutes: allowedRoutes,
 					},
 					{
 						Name:     "https",
 						Protocol: v1beta1.HTTPSProtocolType,
 						Port:     443,
+						AllowedRoutes: allowedRoutes,
 					},
 				},
 			},